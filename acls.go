@@ -0,0 +1,155 @@
+package gokong
+
+import (
+	"context"
+)
+
+// AclClient manages acl credentials of consumers
+type AclClient struct {
+	credentialClient
+}
+
+type AclRequest struct {
+	Group  string    `json:"group,omitempty" yaml:"group,omitempty"`
+	Tags   []*string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Fields `json:"-" yaml:"-"`
+}
+
+type Acl struct {
+	Id        string    `json:"id,omitempty" yaml:"id,omitempty"`
+	Group     string    `json:"group,omitempty" yaml:"group,omitempty"`
+	Consumer  *Id       `json:"consumer,omitempty" yaml:"consumer,omitempty"`
+	CreatedAt int       `json:"created_at,omitempty" yaml:"created_at,omitempty"`
+	Tags      []*string `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+type Acls struct {
+	Results []*Acl  `json:"data" yaml:"data,omitempty"`
+	Next    *string `json:"next" yaml:"next,omitempty"`
+	Offset  string  `json:"offset,omitempty" yaml:"offset,omitempty"`
+}
+
+const AclsPath = "/acls/"
+
+func (aclClient *AclClient) Create(consumerUsernameOrId string, aclRequest *AclRequest) (*Acl, error) {
+	return aclClient.CreateWithContext(context.Background(), consumerUsernameOrId, aclRequest)
+}
+
+func (aclClient *AclClient) CreateWithContext(ctx context.Context, consumerUsernameOrId string, aclRequest *AclRequest) (*Acl, error) {
+	acl := &Acl{}
+	if err := aclClient.create(ctx, consumerUsernameOrId, aclRequest, acl); err != nil {
+		return nil, err
+	}
+
+	return acl, nil
+}
+
+func (aclClient *AclClient) GetById(consumerUsernameOrId string, id string) (*Acl, error) {
+	return aclClient.GetByIdWithContext(context.Background(), consumerUsernameOrId, id)
+}
+
+// GetByIdWithContext returns the acl credential of the consumer with the id, or nil when the consumer has no such
+// credential
+func (aclClient *AclClient) GetByIdWithContext(ctx context.Context, consumerUsernameOrId string, id string) (*Acl, error) {
+	acl := &Acl{}
+	found, err := aclClient.getById(ctx, consumerUsernameOrId, id, acl)
+	if err != nil || !found || acl.Id == "" {
+		return nil, err
+	}
+
+	return acl, nil
+}
+
+func (aclClient *AclClient) GetConsumer(id string) (*Consumer, error) {
+	return aclClient.GetConsumerWithContext(context.Background(), id)
+}
+
+// GetConsumerWithContext returns the consumer owning the acl credential with the id, or nil when there is no such
+// credential
+func (aclClient *AclClient) GetConsumerWithContext(ctx context.Context, id string) (*Consumer, error) {
+	return aclClient.consumer(ctx, id)
+}
+
+func (aclClient *AclClient) List(query *CredentialQueryString) ([]*Acl, error) {
+	return aclClient.ListWithContext(context.Background(), query)
+}
+
+// ListWithContext returns the acl credentials of every consumer matching the query, following pagination
+func (aclClient *AclClient) ListWithContext(ctx context.Context, query *CredentialQueryString) ([]*Acl, error) {
+	acls := make([]*Acl, 0)
+	if err := aclClient.list(ctx, "", query, &acls); err != nil {
+		return nil, err
+	}
+
+	return acls, nil
+}
+
+func (aclClient *AclClient) ListFromConsumer(consumerUsernameOrId string, query *CredentialQueryString) ([]*Acl, error) {
+	return aclClient.ListFromConsumerWithContext(context.Background(), consumerUsernameOrId, query)
+}
+
+// ListFromConsumerWithContext returns the acl credentials of the consumer matching the query, following pagination
+func (aclClient *AclClient) ListFromConsumerWithContext(ctx context.Context, consumerUsernameOrId string, query *CredentialQueryString) ([]*Acl, error) {
+	acls := make([]*Acl, 0)
+	if err := aclClient.list(ctx, consumerUsernameOrId, query, &acls); err != nil {
+		return nil, err
+	}
+
+	return acls, nil
+}
+
+// Iter returns an iterator over the acl credentials of every consumer matching the query
+func (aclClient *AclClient) Iter(ctx context.Context, query *CredentialQueryString) *AclIterator {
+	return &AclIterator{iterator: aclClient.iter(ctx, "", query)}
+}
+
+// IterFromConsumer returns an iterator over the acl credentials of the consumer matching the query
+func (aclClient *AclClient) IterFromConsumer(ctx context.Context, consumerUsernameOrId string, query *CredentialQueryString) *AclIterator {
+	return &AclIterator{iterator: aclClient.iter(ctx, consumerUsernameOrId, query)}
+}
+
+type AclIterator struct {
+	iterator
+}
+
+// Value returns the acl credential the iterator is positioned on
+func (aclIterator *AclIterator) Value() *Acl {
+	acl, _ := aclIterator.value.(*Acl)
+	return acl
+}
+
+func (aclClient *AclClient) UpdateById(consumerUsernameOrId string, id string, aclRequest *AclRequest) (*Acl, error) {
+	return aclClient.UpdateByIdWithContext(context.Background(), consumerUsernameOrId, id, aclRequest)
+}
+
+func (aclClient *AclClient) UpdateByIdWithContext(ctx context.Context, consumerUsernameOrId string, id string, aclRequest *AclRequest) (*Acl, error) {
+	acl := &Acl{}
+	if err := aclClient.update(ctx, consumerUsernameOrId, id, aclRequest, acl); err != nil {
+		return nil, err
+	}
+
+	return acl, nil
+}
+
+func (aclClient *AclClient) Upsert(consumerUsernameOrId string, id string, aclRequest *AclRequest) (*Acl, error) {
+	return aclClient.UpsertWithContext(context.Background(), consumerUsernameOrId, id, aclRequest)
+}
+
+// UpsertWithContext creates the acl credential of the consumer with the id or, when it exists, replaces it in full
+// with the request
+func (aclClient *AclClient) UpsertWithContext(ctx context.Context, consumerUsernameOrId string, id string, aclRequest *AclRequest) (*Acl, error) {
+	acl := &Acl{}
+	if err := aclClient.upsert(ctx, consumerUsernameOrId, id, aclRequest, acl); err != nil {
+		return nil, err
+	}
+
+	return acl, nil
+}
+
+func (aclClient *AclClient) DeleteById(consumerUsernameOrId string, id string) error {
+	return aclClient.DeleteByIdWithContext(context.Background(), consumerUsernameOrId, id)
+}
+
+func (aclClient *AclClient) DeleteByIdWithContext(ctx context.Context, consumerUsernameOrId string, id string) error {
+	return aclClient.delete(ctx, consumerUsernameOrId, id)
+}
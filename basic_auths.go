@@ -0,0 +1,158 @@
+package gokong
+
+import (
+	"context"
+)
+
+// BasicAuthClient manages basic-auth credentials of consumers
+type BasicAuthClient struct {
+	credentialClient
+}
+
+type BasicAuthRequest struct {
+	Username string    `json:"username,omitempty" yaml:"username,omitempty"`
+	Password string    `json:"password,omitempty" yaml:"password,omitempty"`
+	Tags     []*string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Fields   `json:"-" yaml:"-"`
+}
+
+type BasicAuth struct {
+	Id        string    `json:"id,omitempty" yaml:"id,omitempty"`
+	Username  string    `json:"username,omitempty" yaml:"username,omitempty"`
+	Password  string    `json:"password,omitempty" yaml:"password,omitempty"`
+	Consumer  *Id       `json:"consumer,omitempty" yaml:"consumer,omitempty"`
+	CreatedAt int       `json:"created_at,omitempty" yaml:"created_at,omitempty"`
+	Tags      []*string `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+type BasicAuths struct {
+	Results []*BasicAuth `json:"data" yaml:"data,omitempty"`
+	Next    *string      `json:"next" yaml:"next,omitempty"`
+	Offset  string       `json:"offset,omitempty" yaml:"offset,omitempty"`
+}
+
+const BasicAuthsPath = "/basic-auths/"
+
+func (basicAuthClient *BasicAuthClient) Create(consumerUsernameOrId string, basicAuthRequest *BasicAuthRequest) (*BasicAuth, error) {
+	return basicAuthClient.CreateWithContext(context.Background(), consumerUsernameOrId, basicAuthRequest)
+}
+
+func (basicAuthClient *BasicAuthClient) CreateWithContext(ctx context.Context, consumerUsernameOrId string, basicAuthRequest *BasicAuthRequest) (*BasicAuth, error) {
+	basicAuth := &BasicAuth{}
+	if err := basicAuthClient.create(ctx, consumerUsernameOrId, basicAuthRequest, basicAuth); err != nil {
+		return nil, err
+	}
+
+	return basicAuth, nil
+}
+
+func (basicAuthClient *BasicAuthClient) GetById(consumerUsernameOrId string, id string) (*BasicAuth, error) {
+	return basicAuthClient.GetByIdWithContext(context.Background(), consumerUsernameOrId, id)
+}
+
+// GetByIdWithContext returns the basic-auth credential of the consumer with the id or username, or nil when the
+// consumer has no such credential
+func (basicAuthClient *BasicAuthClient) GetByIdWithContext(ctx context.Context, consumerUsernameOrId string, id string) (*BasicAuth, error) {
+	basicAuth := &BasicAuth{}
+	found, err := basicAuthClient.getById(ctx, consumerUsernameOrId, id, basicAuth)
+	if err != nil || !found || basicAuth.Id == "" {
+		return nil, err
+	}
+
+	return basicAuth, nil
+}
+
+func (basicAuthClient *BasicAuthClient) GetConsumer(id string) (*Consumer, error) {
+	return basicAuthClient.GetConsumerWithContext(context.Background(), id)
+}
+
+// GetConsumerWithContext returns the consumer owning the basic-auth credential with the id or username, or nil when
+// there is no such credential
+func (basicAuthClient *BasicAuthClient) GetConsumerWithContext(ctx context.Context, id string) (*Consumer, error) {
+	return basicAuthClient.consumer(ctx, id)
+}
+
+func (basicAuthClient *BasicAuthClient) List(query *CredentialQueryString) ([]*BasicAuth, error) {
+	return basicAuthClient.ListWithContext(context.Background(), query)
+}
+
+// ListWithContext returns the basic-auth credentials of every consumer matching the query, following pagination
+func (basicAuthClient *BasicAuthClient) ListWithContext(ctx context.Context, query *CredentialQueryString) ([]*BasicAuth, error) {
+	basicAuths := make([]*BasicAuth, 0)
+	if err := basicAuthClient.list(ctx, "", query, &basicAuths); err != nil {
+		return nil, err
+	}
+
+	return basicAuths, nil
+}
+
+func (basicAuthClient *BasicAuthClient) ListFromConsumer(consumerUsernameOrId string, query *CredentialQueryString) ([]*BasicAuth, error) {
+	return basicAuthClient.ListFromConsumerWithContext(context.Background(), consumerUsernameOrId, query)
+}
+
+// ListFromConsumerWithContext returns the basic-auth credentials of the consumer matching the query, following
+// pagination
+func (basicAuthClient *BasicAuthClient) ListFromConsumerWithContext(ctx context.Context, consumerUsernameOrId string, query *CredentialQueryString) ([]*BasicAuth, error) {
+	basicAuths := make([]*BasicAuth, 0)
+	if err := basicAuthClient.list(ctx, consumerUsernameOrId, query, &basicAuths); err != nil {
+		return nil, err
+	}
+
+	return basicAuths, nil
+}
+
+// Iter returns an iterator over the basic-auth credentials of every consumer matching the query
+func (basicAuthClient *BasicAuthClient) Iter(ctx context.Context, query *CredentialQueryString) *BasicAuthIterator {
+	return &BasicAuthIterator{iterator: basicAuthClient.iter(ctx, "", query)}
+}
+
+// IterFromConsumer returns an iterator over the basic-auth credentials of the consumer matching the query
+func (basicAuthClient *BasicAuthClient) IterFromConsumer(ctx context.Context, consumerUsernameOrId string, query *CredentialQueryString) *BasicAuthIterator {
+	return &BasicAuthIterator{iterator: basicAuthClient.iter(ctx, consumerUsernameOrId, query)}
+}
+
+type BasicAuthIterator struct {
+	iterator
+}
+
+// Value returns the basic-auth credential the iterator is positioned on
+func (basicAuthIterator *BasicAuthIterator) Value() *BasicAuth {
+	basicAuth, _ := basicAuthIterator.value.(*BasicAuth)
+	return basicAuth
+}
+
+func (basicAuthClient *BasicAuthClient) UpdateById(consumerUsernameOrId string, id string, basicAuthRequest *BasicAuthRequest) (*BasicAuth, error) {
+	return basicAuthClient.UpdateByIdWithContext(context.Background(), consumerUsernameOrId, id, basicAuthRequest)
+}
+
+func (basicAuthClient *BasicAuthClient) UpdateByIdWithContext(ctx context.Context, consumerUsernameOrId string, id string, basicAuthRequest *BasicAuthRequest) (*BasicAuth, error) {
+	basicAuth := &BasicAuth{}
+	if err := basicAuthClient.update(ctx, consumerUsernameOrId, id, basicAuthRequest, basicAuth); err != nil {
+		return nil, err
+	}
+
+	return basicAuth, nil
+}
+
+func (basicAuthClient *BasicAuthClient) Upsert(consumerUsernameOrId string, id string, basicAuthRequest *BasicAuthRequest) (*BasicAuth, error) {
+	return basicAuthClient.UpsertWithContext(context.Background(), consumerUsernameOrId, id, basicAuthRequest)
+}
+
+// UpsertWithContext creates the basic-auth credential of the consumer with the id or username or, when it exists,
+// replaces it in full with the request
+func (basicAuthClient *BasicAuthClient) UpsertWithContext(ctx context.Context, consumerUsernameOrId string, id string, basicAuthRequest *BasicAuthRequest) (*BasicAuth, error) {
+	basicAuth := &BasicAuth{}
+	if err := basicAuthClient.upsert(ctx, consumerUsernameOrId, id, basicAuthRequest, basicAuth); err != nil {
+		return nil, err
+	}
+
+	return basicAuth, nil
+}
+
+func (basicAuthClient *BasicAuthClient) DeleteById(consumerUsernameOrId string, id string) error {
+	return basicAuthClient.DeleteByIdWithContext(context.Background(), consumerUsernameOrId, id)
+}
+
+func (basicAuthClient *BasicAuthClient) DeleteByIdWithContext(ctx context.Context, consumerUsernameOrId string, id string) error {
+	return basicAuthClient.delete(ctx, consumerUsernameOrId, id)
+}
@@ -0,0 +1,318 @@
+package gokong
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+type CACertificateClient struct {
+	config *Config
+}
+
+type CACertificateRequest struct {
+	Cert   *string   `json:"cert,omitempty" yaml:"cert,omitempty"`
+	Tags   []*string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Fields `json:"-" yaml:"-"`
+}
+
+type CACertificate struct {
+	Id         *string   `json:"id,omitempty" yaml:"id,omitempty"`
+	CreatedAt  *int      `json:"created_at,omitempty" yaml:"created_at,omitempty"`
+	Cert       *string   `json:"cert,omitempty" yaml:"cert,omitempty"`
+	CertDigest *string   `json:"cert_digest,omitempty" yaml:"cert_digest,omitempty"`
+	Tags       []*string `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+type CACertificates struct {
+	Results []*CACertificate `json:"data,omitempty" yaml:"data,omitempty"`
+	Next    string           `json:"next,omitempty" yaml:"next,omitempty"`
+	Offset  string           `json:"offset,omitempty" yaml:"offset,omitempty"`
+}
+
+type CACertificateQueryString struct {
+	Offset string `json:"offset,omitempty"`
+	Size   int    `json:"size"`
+	Tags   string `json:"tags,omitempty"`
+}
+
+const CACertificatesPath = "/ca_certificates/"
+
+// CACertificateDigest returns the digest kong keeps of a pem encoded certificate as its cert_digest, the hex encoded
+// sha256 of the certificate
+func CACertificateDigest(cert string) (string, error) {
+	block, _ := pem.Decode([]byte(cert))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return "", errors.New("invalid certificate: unable to load certificate")
+	}
+
+	digest := sha256.Sum256(block.Bytes)
+	return hex.EncodeToString(digest[:]), nil
+}
+
+// Validate checks the certificate is a single pem encoded ca certificate that has not expired, the checks kong makes
+// when the certificate is uploaded, it returns a *ValidationError listing every invalid field
+func (caCertificateRequest *CACertificateRequest) Validate() error {
+	v := newValidator("ca certificate")
+
+	if caCertificateRequest.Cert == nil || *caCertificateRequest.Cert == "" {
+		v.add("cert", "required field missing")
+		return v.err()
+	}
+
+	block, rest := pem.Decode([]byte(*caCertificateRequest.Cert))
+	if block == nil || block.Type != "CERTIFICATE" {
+		v.add("cert", "invalid certificate: unable to load certificate")
+		return v.err()
+	}
+	if next, _ := pem.Decode(bytes.TrimSpace(rest)); next != nil {
+		v.add("cert", "please submit only one certificate at a time")
+	}
+
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		v.add("cert", "invalid certificate: %v", err)
+		return v.err()
+	}
+	if !certificate.BasicConstraintsValid || !certificate.IsCA {
+		v.add("cert", `certificate does not appear to be a CA because it is missing the "CA" basic constraint`)
+	}
+	if certificate.NotAfter.Before(clock()) {
+		v.add("cert", `certificate expired, "Not After" time is in the past`)
+	}
+
+	return v.err()
+}
+
+func (caCertificateClient *CACertificateClient) GetById(id string) (*CACertificate, error) {
+	return caCertificateClient.GetByIdWithContext(context.Background(), id)
+}
+
+func (caCertificateClient *CACertificateClient) GetByIdWithContext(ctx context.Context, id string) (*CACertificate, error) {
+
+	r, body, errs := newGet(ctx, caCertificateClient.config, caCertificateClient.config.HostAddress+CACertificatesPath+id).End()
+	if errs != nil {
+		return nil, fmt.Errorf("could not get ca certificate, error: %v", errs)
+	}
+
+	if r.StatusCode == 404 {
+		return nil, nil
+	}
+
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
+	}
+
+	caCertificate := &CACertificate{}
+	err := json.Unmarshal([]byte(body), caCertificate)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse ca certificate get response, error: %v", err)
+	}
+
+	if caCertificate.Id == nil {
+		return nil, nil
+	}
+
+	return caCertificate, nil
+}
+
+func (caCertificateClient *CACertificateClient) GetByCert(cert string) (*CACertificate, error) {
+	return caCertificateClient.GetByCertWithContext(context.Background(), cert)
+}
+
+// GetByCertWithContext returns the ca certificate kong holds for the pem encoded certificate, matched by its digest,
+// or nil when kong does not hold it
+func (caCertificateClient *CACertificateClient) GetByCertWithContext(ctx context.Context, cert string) (*CACertificate, error) {
+	digest, err := CACertificateDigest(cert)
+	if err != nil {
+		return nil, err
+	}
+
+	caCertificates := caCertificateClient.Iter(ctx, &CACertificateQueryString{})
+	for caCertificates.Next() {
+		if caCertificate := caCertificates.Value(); caCertificate.CertDigest != nil && *caCertificate.CertDigest == digest {
+			return caCertificate, nil
+		}
+	}
+
+	return nil, caCertificates.Err()
+}
+
+func (caCertificateClient *CACertificateClient) Create(caCertificateRequest *CACertificateRequest) (*CACertificate, error) {
+	return caCertificateClient.CreateWithContext(context.Background(), caCertificateRequest)
+}
+
+// CreateWithContext uploads the ca certificate, the certificate is validated first and a *ValidationError returned
+// without calling kong when it is invalid
+func (caCertificateClient *CACertificateClient) CreateWithContext(ctx context.Context, caCertificateRequest *CACertificateRequest) (*CACertificate, error) {
+
+	if err := caCertificateRequest.Validate(); err != nil {
+		return nil, err
+	}
+
+	r, body, errs := newPost(ctx, caCertificateClient.config, caCertificateClient.config.HostAddress+CACertificatesPath).Send(caCertificateRequest).End()
+	if errs != nil {
+		return nil, fmt.Errorf("could not create new ca certificate, error: %v", errs)
+	}
+
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
+	}
+
+	createdCACertificate := &CACertificate{}
+	err := json.Unmarshal([]byte(body), createdCACertificate)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse ca certificate creation response, error: %v", err)
+	}
+
+	if createdCACertificate.Id == nil {
+		return nil, fmt.Errorf("could not create ca certificate, error: %v", body)
+	}
+
+	return createdCACertificate, nil
+}
+
+func (caCertificateClient *CACertificateClient) DeleteById(id string) error {
+	return caCertificateClient.DeleteByIdWithContext(context.Background(), id)
+}
+
+func (caCertificateClient *CACertificateClient) DeleteByIdWithContext(ctx context.Context, id string) error {
+
+	r, body, errs := newDelete(ctx, caCertificateClient.config, caCertificateClient.config.HostAddress+CACertificatesPath+id).End()
+	if errs != nil {
+		return fmt.Errorf("could not delete ca certificate, result: %v error: %v", r, errs)
+	}
+
+	if err := checkResponse(r, body); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (caCertificateClient *CACertificateClient) List() (*CACertificates, error) {
+	return caCertificateClient.ListWithContext(context.Background())
+}
+
+// ListWithContext returns every ca certificate, following pagination
+func (caCertificateClient *CACertificateClient) ListWithContext(ctx context.Context) (*CACertificates, error) {
+	results, err := caCertificateClient.GetCACertificatesWithContext(ctx, &CACertificateQueryString{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &CACertificates{Results: results}, nil
+}
+
+func (caCertificateClient *CACertificateClient) GetCACertificates(query *CACertificateQueryString) ([]*CACertificate, error) {
+	return caCertificateClient.GetCACertificatesWithContext(context.Background(), query)
+}
+
+// GetCACertificatesWithContext returns every ca certificate matching the query, following pagination
+func (caCertificateClient *CACertificateClient) GetCACertificatesWithContext(ctx context.Context, query *CACertificateQueryString) ([]*CACertificate, error) {
+	caCertificates := make([]*CACertificate, 0)
+
+	pages := newPager(ctx, caCertificateClient.config, caCertificateClient.config.HostAddress+CACertificatesPath, "ca certificates", *query)
+	for pages.more() {
+		data := &CACertificates{}
+		if err := pages.next(data); err != nil {
+			return nil, err
+		}
+
+		caCertificates = append(caCertificates, data.Results...)
+	}
+
+	return caCertificates, nil
+}
+
+// Iter returns an iterator over the ca certificates matching the query
+func (caCertificateClient *CACertificateClient) Iter(ctx context.Context, query *CACertificateQueryString) *CACertificateIterator {
+	pages := newPager(ctx, caCertificateClient.config, caCertificateClient.config.HostAddress+CACertificatesPath, "ca certificates", *query)
+	return &CACertificateIterator{iterator: newIterator(pages, func() listPage { return &CACertificates{} })}
+}
+
+type CACertificateIterator struct {
+	iterator
+}
+
+// Value returns the ca certificate the iterator is positioned on
+func (caCertificateIterator *CACertificateIterator) Value() *CACertificate {
+	caCertificate, _ := caCertificateIterator.value.(*CACertificate)
+	return caCertificate
+}
+
+func (caCertificateClient *CACertificateClient) UpdateById(id string, caCertificateRequest *CACertificateRequest) (*CACertificate, error) {
+	return caCertificateClient.UpdateByIdWithContext(context.Background(), id, caCertificateRequest)
+}
+
+// UpdateByIdWithContext updates the ca certificate, a new certificate is validated first and a *ValidationError
+// returned without calling kong when it is invalid
+func (caCertificateClient *CACertificateClient) UpdateByIdWithContext(ctx context.Context, id string, caCertificateRequest *CACertificateRequest) (*CACertificate, error) {
+
+	if caCertificateRequest.Cert != nil {
+		if err := caCertificateRequest.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	r, body, errs := newPatch(ctx, caCertificateClient.config, caCertificateClient.config.HostAddress+CACertificatesPath+id).Send(caCertificateRequest).End()
+	if errs != nil {
+		return nil, fmt.Errorf("could not update ca certificate, error: %v", errs)
+	}
+
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
+	}
+
+	updatedCACertificate := &CACertificate{}
+	err := json.Unmarshal([]byte(body), updatedCACertificate)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse ca certificate update response, error: %v", err)
+	}
+
+	if updatedCACertificate.Id == nil {
+		return nil, fmt.Errorf("could not update ca certificate, error: %v", body)
+	}
+
+	return updatedCACertificate, nil
+}
+
+func (caCertificateClient *CACertificateClient) Upsert(id string, caCertificateRequest *CACertificateRequest) (*CACertificate, error) {
+	return caCertificateClient.UpsertWithContext(context.Background(), id, caCertificateRequest)
+}
+
+// UpsertWithContext creates the ca certificate with the id or, when it exists, replaces it in full with the request,
+// the certificate is validated first and a *ValidationError returned without calling kong when it is invalid
+func (caCertificateClient *CACertificateClient) UpsertWithContext(ctx context.Context, id string, caCertificateRequest *CACertificateRequest) (*CACertificate, error) {
+
+	if err := caCertificateRequest.Validate(); err != nil {
+		return nil, err
+	}
+
+	r, body, errs := newPut(ctx, caCertificateClient.config, caCertificateClient.config.HostAddress+CACertificatesPath+id).Send(caCertificateRequest).End()
+	if errs != nil {
+		return nil, fmt.Errorf("could not upsert ca certificate, error: %v", errs)
+	}
+
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
+	}
+
+	upsertedCACertificate := &CACertificate{}
+	err := json.Unmarshal([]byte(body), upsertedCACertificate)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse ca certificate upsert response, error: %v", err)
+	}
+
+	if upsertedCACertificate.Id == nil {
+		return nil, fmt.Errorf("could not upsert ca certificate, error: %v", body)
+	}
+
+	return upsertedCACertificate, nil
+}
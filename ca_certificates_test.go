@@ -0,0 +1,182 @@
+package gokong
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestCACertificate returns a self signed pem encoded certificate, a ca certificate when isCA is set
+func newTestCACertificate(t *testing.T, isCA bool, notAfter time.Time) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: "ca-" + uuid.NewV4().String()},
+		NotBefore:             notAfter.Add(-48 * time.Hour),
+		NotAfter:              notAfter,
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func Test_CACertificatesCreateGetUpdateAndDelete(t *testing.T) {
+	client := NewClient(NewDefaultConfig())
+	cert := newTestCACertificate(t, true, time.Now().Add(24*time.Hour))
+
+	caCertificate, err := client.CACertificates().Create(&CACertificateRequest{Cert: String(cert), Tags: StringSlice([]string{"team-a"})})
+	assert.Nil(t, err)
+	assert.Equal(t, cert, *caCertificate.Cert)
+	assert.Equal(t, StringSlice([]string{"team-a"}), caCertificate.Tags)
+
+	digest, err := CACertificateDigest(cert)
+	assert.Nil(t, err)
+	assert.Equal(t, digest, *caCertificate.CertDigest)
+
+	result, err := client.CACertificates().GetById(*caCertificate.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, caCertificate, result)
+
+	result, err = client.CACertificates().GetByCert(cert)
+	assert.Nil(t, err)
+	assert.Equal(t, caCertificate.Id, result.Id)
+
+	_, err = client.CACertificates().Create(&CACertificateRequest{Cert: String(cert)})
+	assert.True(t, IsConflict(err))
+
+	updatedCert := newTestCACertificate(t, true, time.Now().Add(48*time.Hour))
+	updated, err := client.CACertificates().UpdateById(*caCertificate.Id, &CACertificateRequest{Cert: String(updatedCert)})
+	assert.Nil(t, err)
+	assert.Equal(t, updatedCert, *updated.Cert)
+	assert.NotEqual(t, *caCertificate.CertDigest, *updated.CertDigest)
+	assert.Equal(t, caCertificate.Tags, updated.Tags)
+
+	err = client.CACertificates().DeleteById(*caCertificate.Id)
+	assert.Nil(t, err)
+
+	result, err = client.CACertificates().GetById(*caCertificate.Id)
+	assert.Nil(t, err)
+	assert.Nil(t, result)
+
+	result, err = client.CACertificates().GetByCert(updatedCert)
+	assert.Nil(t, err)
+	assert.Nil(t, result)
+}
+
+func Test_CACertificatesListAndIterate(t *testing.T) {
+	client := NewClient(NewDefaultConfig())
+	tag := "tag-" + uuid.NewV4().String()
+
+	created := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		caCertificate, err := client.CACertificates().Create(&CACertificateRequest{
+			Cert: String(newTestCACertificate(t, true, time.Now().Add(24*time.Hour))),
+			Tags: StringSlice([]string{tag}),
+		})
+		assert.Nil(t, err)
+		created[*caCertificate.Id] = true
+	}
+
+	caCertificates, err := client.CACertificates().GetCACertificates(&CACertificateQueryString{Tags: tag})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(caCertificates))
+
+	iterated := map[string]bool{}
+	iterator := client.CACertificates().Iter(context.Background(), &CACertificateQueryString{Tags: tag, Size: 1})
+	for iterator.Next() {
+		iterated[*iterator.Value().Id] = true
+	}
+	assert.Nil(t, iterator.Err())
+	assert.Equal(t, created, iterated)
+
+	all, err := client.CACertificates().List()
+	assert.Nil(t, err)
+	assert.True(t, len(all.Results) >= 3)
+
+	for id := range created {
+		assert.Nil(t, client.CACertificates().DeleteById(id))
+	}
+}
+
+func Test_CACertificatesUpsert(t *testing.T) {
+	client := NewClient(NewDefaultConfig())
+	id := uuid.NewV4().String()
+
+	created, err := client.CACertificates().Upsert(id, &CACertificateRequest{
+		Cert: String(newTestCACertificate(t, true, time.Now().Add(24*time.Hour))),
+		Tags: StringSlice([]string{"team-a"}),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, id, *created.Id)
+
+	cert := newTestCACertificate(t, true, time.Now().Add(24*time.Hour))
+	replaced, err := client.CACertificates().Upsert(id, &CACertificateRequest{Cert: String(cert)})
+	assert.Nil(t, err)
+	assert.Equal(t, id, *replaced.Id)
+	assert.Equal(t, cert, *replaced.Cert)
+	assert.Nil(t, replaced.Tags)
+
+	err = client.CACertificates().DeleteById(id)
+	assert.Nil(t, err)
+}
+
+func Test_CACertificatesValidateThePemBeforeUpload(t *testing.T) {
+	client := NewClient(&Config{HostAddress: "http://localhost:0"})
+
+	_, err := client.CACertificates().Create(&CACertificateRequest{Cert: String("not a certificate")})
+	assert.Equal(t, "invalid certificate: unable to load certificate", err.(*ValidationError).Field("cert").Message)
+
+	_, err = client.CACertificates().Create(&CACertificateRequest{Cert: String(newTestCACertificate(t, false, time.Now().Add(time.Hour)))})
+	assert.Equal(t, `certificate does not appear to be a CA because it is missing the "CA" basic constraint`, err.(*ValidationError).Field("cert").Message)
+
+	_, err = client.CACertificates().Upsert(uuid.NewV4().String(), &CACertificateRequest{Cert: String(newTestCACertificate(t, true, time.Now().Add(-time.Hour)))})
+	assert.Equal(t, `certificate expired, "Not After" time is in the past`, err.(*ValidationError).Field("cert").Message)
+
+	twoCerts := newTestCACertificate(t, true, time.Now().Add(time.Hour)) + newTestCACertificate(t, true, time.Now().Add(time.Hour))
+	_, err = client.CACertificates().UpdateById(uuid.NewV4().String(), &CACertificateRequest{Cert: String(twoCerts)})
+	assert.Equal(t, "please submit only one certificate at a time", err.(*ValidationError).Field("cert").Message)
+
+	err = (&CACertificateRequest{}).Validate()
+	assert.Equal(t, "required field missing", err.(*ValidationError).Field("cert").Message)
+}
+
+func Test_ServicesResolveTheirCACertificates(t *testing.T) {
+	client := NewClient(NewDefaultConfig())
+
+	first, err := client.CACertificates().Create(&CACertificateRequest{Cert: String(newTestCACertificate(t, true, time.Now().Add(24*time.Hour)))})
+	assert.Nil(t, err)
+	second, err := client.CACertificates().Create(&CACertificateRequest{Cert: String(newTestCACertificate(t, true, time.Now().Add(24*time.Hour)))})
+	assert.Nil(t, err)
+
+	service, err := client.Services().Create(&ServiceRequest{
+		Name:           String("service-name-" + uuid.NewV4().String()),
+		Url:            String("https://foo.com"),
+		TlsVerify:      Bool(true),
+		CaCertificates: []*string{second.Id, first.Id},
+	})
+	assert.Nil(t, err)
+
+	caCertificates, err := client.Services().GetCACertificates(service)
+	assert.Nil(t, err)
+	assert.Equal(t, []*CACertificate{second, first}, caCertificates)
+
+	assert.Nil(t, client.Services().DeleteServiceById(*service.Id))
+	assert.Nil(t, client.CACertificates().DeleteById(*first.Id))
+	assert.Nil(t, client.CACertificates().DeleteById(*second.Id))
+}
@@ -0,0 +1,168 @@
+package gokong
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// CertificateInfo is the metadata of the leaf certificate in the pem of a Certificate
+type CertificateInfo struct {
+	Subject      string    `json:"subject" yaml:"subject"`
+	Issuer       string    `json:"issuer" yaml:"issuer"`
+	SerialNumber string    `json:"serial_number" yaml:"serial_number"`
+	DNSNames     []string  `json:"dns_names,omitempty" yaml:"dns_names,omitempty"`
+	IPAddresses  []string  `json:"ip_addresses,omitempty" yaml:"ip_addresses,omitempty"`
+	NotBefore    time.Time `json:"not_before" yaml:"not_before"`
+	NotAfter     time.Time `json:"not_after" yaml:"not_after"`
+	// KeyType is the public key algorithm of the certificate e.g. RSA or ECDSA, KeySize is its size in bits when it
+	// is known
+	KeyType string `json:"key_type" yaml:"key_type"`
+	KeySize int    `json:"key_size,omitempty" yaml:"key_size,omitempty"`
+	// KeyMatches is true when the certificate has a private key and it is the key of the certificate
+	KeyMatches bool `json:"key_matches" yaml:"key_matches"`
+}
+
+// Expired returns whether the certificate is no longer valid at the time given
+func (certificateInfo *CertificateInfo) Expired(at time.Time) bool {
+	return certificateInfo.NotAfter.Before(at)
+}
+
+// Info parses the leaf certificate of the pem in Cert, the first certificate when Cert holds a chain, and checks Key
+// against it
+func (certificate *Certificate) Info() (*CertificateInfo, error) {
+	if certificate.Cert == nil {
+		return nil, errors.New("could not parse certificate, error: certificate has no cert")
+	}
+
+	block, _ := pem.Decode([]byte(*certificate.Cert))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("could not parse certificate, error: unable to load certificate")
+	}
+
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse certificate, error: %v", err)
+	}
+
+	certificateInfo := &CertificateInfo{
+		Subject:      leaf.Subject.String(),
+		Issuer:       leaf.Issuer.String(),
+		SerialNumber: leaf.SerialNumber.String(),
+		DNSNames:     leaf.DNSNames,
+		NotBefore:    leaf.NotBefore,
+		NotAfter:     leaf.NotAfter,
+		KeyType:      leaf.PublicKeyAlgorithm.String(),
+	}
+
+	for _, ip := range leaf.IPAddresses {
+		certificateInfo.IPAddresses = append(certificateInfo.IPAddresses, ip.String())
+	}
+
+	switch publicKey := leaf.PublicKey.(type) {
+	case *rsa.PublicKey:
+		certificateInfo.KeySize = publicKey.N.BitLen()
+	case *ecdsa.PublicKey:
+		certificateInfo.KeySize = publicKey.Curve.Params().BitSize
+	}
+
+	if certificate.Key != nil {
+		_, err := tls.X509KeyPair([]byte(*certificate.Cert), []byte(*certificate.Key))
+		certificateInfo.KeyMatches = err == nil
+	}
+
+	return certificateInfo, nil
+}
+
+// CertificateExpiryReport lists the certificates that expire before Before, soonest first, followed by the
+// certificates that could not be parsed
+type CertificateExpiryReport struct {
+	GeneratedAt  time.Time              `json:"generated_at" yaml:"generated_at"`
+	Before       time.Time              `json:"before" yaml:"before"`
+	Certificates []*ExpiringCertificate `json:"certificates" yaml:"certificates"`
+}
+
+// ExpiringCertificate is a certificate in an expiry report with the names of the snis that use it, Error is set and
+// Info is nil when the certificate could not be parsed
+type ExpiringCertificate struct {
+	Id      string           `json:"id" yaml:"id"`
+	Info    *CertificateInfo `json:"info,omitempty" yaml:"info,omitempty"`
+	Expired bool             `json:"expired" yaml:"expired"`
+	Snis    []string         `json:"snis,omitempty" yaml:"snis,omitempty"`
+	Tags    []*string        `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Error   string           `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Failed returns the certificates that could not be parsed
+func (certificateExpiryReport *CertificateExpiryReport) Failed() []*ExpiringCertificate {
+	failed := make([]*ExpiringCertificate, 0)
+	for _, expiringCertificate := range certificateExpiryReport.Certificates {
+		if expiringCertificate.Error != "" {
+			failed = append(failed, expiringCertificate)
+		}
+	}
+	return failed
+}
+
+func (certificateClient *CertificateClient) ExpiryReport(within time.Duration, query *CertificateQueryString) (*CertificateExpiryReport, error) {
+	return certificateClient.ExpiryReportWithContext(context.Background(), within, query)
+}
+
+// ExpiryReportWithContext reports the certificates matching the query that have expired or expire within the
+// duration, along with the snis that serve them
+func (certificateClient *CertificateClient) ExpiryReportWithContext(ctx context.Context, within time.Duration, query *CertificateQueryString) (*CertificateExpiryReport, error) {
+	certificates, err := certificateClient.GetCertificatesWithContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	snis, err := (&SnisClient{config: certificateClient.config}).GetSnisWithContext(ctx, &SniQueryString{})
+	if err != nil {
+		return nil, err
+	}
+
+	snisByCertificate := map[string][]string{}
+	for _, sni := range snis {
+		if sni.CertificateId != nil {
+			snisByCertificate[string(*sni.CertificateId)] = append(snisByCertificate[string(*sni.CertificateId)], sni.Name)
+		}
+	}
+
+	now := clock()
+	report := &CertificateExpiryReport{GeneratedAt: now, Before: now.Add(within), Certificates: make([]*ExpiringCertificate, 0)}
+	for _, certificate := range certificates {
+		expiringCertificate := &ExpiringCertificate{Id: *certificate.Id, Snis: snisByCertificate[*certificate.Id], Tags: certificate.Tags}
+
+		info, err := certificate.Info()
+		if err != nil {
+			expiringCertificate.Error = err.Error()
+			report.Certificates = append(report.Certificates, expiringCertificate)
+			continue
+		}
+
+		if !info.Expired(report.Before) {
+			continue
+		}
+
+		expiringCertificate.Info = info
+		expiringCertificate.Expired = info.Expired(now)
+		report.Certificates = append(report.Certificates, expiringCertificate)
+	}
+
+	sort.SliceStable(report.Certificates, func(i, j int) bool {
+		first, second := report.Certificates[i].Info, report.Certificates[j].Info
+		if first == nil || second == nil {
+			return second == nil && first != nil
+		}
+		return first.NotAfter.Before(second.NotAfter)
+	})
+
+	return report, nil
+}
@@ -0,0 +1,118 @@
+package gokong
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestServerCertificate returns a self signed pem encoded certificate for the dns names and its private key
+func newTestServerCertificate(t *testing.T, notAfter time.Time, dnsNames ...string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "server-" + uuid.NewV4().String()},
+		DNSNames:     dnsNames,
+		IPAddresses:  []net.IP{net.ParseIP("10.0.0.1")},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+
+	keyDer, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.Nil(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer}))
+}
+
+func Test_CertificateInfo(t *testing.T) {
+	notAfter := time.Now().Add(24 * time.Hour).Truncate(time.Second).UTC()
+	cert, key := newTestServerCertificate(t, notAfter, "foo.com", "*.bar.com")
+
+	info, err := (&Certificate{Cert: String(cert), Key: String(key)}).Info()
+	assert.Nil(t, err)
+	assert.Equal(t, info.Subject, info.Issuer)
+	assert.Equal(t, []string{"foo.com", "*.bar.com"}, info.DNSNames)
+	assert.Equal(t, []string{"10.0.0.1"}, info.IPAddresses)
+	assert.Equal(t, notAfter, info.NotAfter)
+	assert.Equal(t, notAfter.Add(-365*24*time.Hour), info.NotBefore)
+	assert.Equal(t, "ECDSA", info.KeyType)
+	assert.Equal(t, 256, info.KeySize)
+	assert.True(t, info.KeyMatches)
+	assert.False(t, info.Expired(time.Now()))
+	assert.True(t, info.Expired(notAfter.Add(time.Second)))
+
+	_, otherKey := newTestServerCertificate(t, notAfter, "foo.com")
+	info, err = (&Certificate{Cert: String(cert), Key: String(otherKey)}).Info()
+	assert.Nil(t, err)
+	assert.False(t, info.KeyMatches)
+
+	info, err = (&Certificate{Cert: String(cert)}).Info()
+	assert.Nil(t, err)
+	assert.False(t, info.KeyMatches)
+
+	info, err = (&Certificate{Cert: String(testCert1), Key: String(testKey1)}).Info()
+	assert.Nil(t, err)
+	assert.Equal(t, "CN=gokong,O=kevholditch,L=Cambridge,ST=CAMB,C=GB", info.Subject)
+	assert.Equal(t, "RSA", info.KeyType)
+	assert.Equal(t, 2048, info.KeySize)
+	assert.True(t, info.KeyMatches)
+
+	_, err = (&Certificate{Cert: String("public key --- 123")}).Info()
+	assert.Equal(t, "could not parse certificate, error: unable to load certificate", err.Error())
+}
+
+func Test_CertificatesExpiryReport(t *testing.T) {
+	client := NewClient(NewDefaultConfig())
+	tag := "tag-" + uuid.NewV4().String()
+
+	create := func(notAfter time.Time) *Certificate {
+		cert, key := newTestServerCertificate(t, notAfter, "foo.com")
+		certificate, err := client.Certificates().Create(&CertificateRequest{Cert: String(cert), Key: String(key), Tags: StringSlice([]string{tag})})
+		assert.Nil(t, err)
+		return certificate
+	}
+
+	expired := create(time.Now().Add(-24 * time.Hour))
+	expiringSoon := create(time.Now().Add(10 * 24 * time.Hour))
+	expiringLater := create(time.Now().Add(100 * 24 * time.Hour))
+
+	sniName := "sni-" + uuid.NewV4().String() + ".com"
+	_, err := client.Snis().Create(&SnisRequest{Name: sniName, CertificateId: ToId(*expiringSoon.Id)})
+	assert.Nil(t, err)
+
+	report, err := client.Certificates().ExpiryReport(30*24*time.Hour, &CertificateQueryString{Tags: tag})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(report.Certificates))
+	assert.Empty(t, report.Failed())
+
+	assert.Equal(t, *expired.Id, report.Certificates[0].Id)
+	assert.True(t, report.Certificates[0].Expired)
+	assert.Nil(t, report.Certificates[0].Snis)
+
+	assert.Equal(t, *expiringSoon.Id, report.Certificates[1].Id)
+	assert.False(t, report.Certificates[1].Expired)
+	assert.Equal(t, []string{sniName}, report.Certificates[1].Snis)
+	assert.True(t, report.Certificates[1].Info.KeyMatches)
+	assert.Equal(t, StringSlice([]string{tag}), report.Certificates[1].Tags)
+
+	assert.Nil(t, client.Snis().DeleteByName(sniName))
+	for _, certificate := range []*Certificate{expired, expiringSoon, expiringLater} {
+		assert.Nil(t, client.Certificates().DeleteById(*certificate.Id))
+	}
+}
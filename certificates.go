@@ -1,6 +1,7 @@
 package gokong
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 )
@@ -10,33 +11,52 @@ type CertificateClient struct {
 }
 
 type CertificateRequest struct {
-	Cert *string `json:"cert,omitempty" yaml:"cert,omitempty"`
-	Key  *string `json:"key,omitempty" yaml:"key,omitempty"`
-	SNIs *[]string `json:"snis" yaml:"snis"`
+	Cert   *string   `json:"cert,omitempty" yaml:"cert,omitempty"`
+	Key    *string   `json:"key,omitempty" yaml:"key,omitempty"`
+	SNIs   *[]string `json:"snis,omitempty" yaml:"snis,omitempty"`
+	Tags   []*string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Fields `json:"-" yaml:"-"`
 }
 
 type Certificate struct {
-	Id   *string `json:"id,omitempty" yaml:"id,omitempty"`
-	Cert *string `json:"cert,omitempty" yaml:"cert,omitempty"`
-	Key  *string `json:"key,omitempty" yaml:"key,omitempty"`
+	Id   *string   `json:"id,omitempty" yaml:"id,omitempty"`
+	Cert *string   `json:"cert,omitempty" yaml:"cert,omitempty"`
+	Key  *string   `json:"key,omitempty" yaml:"key,omitempty"`
+	Tags []*string `json:"tags,omitempty" yaml:"tags,omitempty"`
 }
 
 type Certificates struct {
 	Results []*Certificate `json:"data,omitempty" yaml:"data,omitempty"`
 	Total   int            `json:"total,omitempty" yaml:"total,omitempty"`
+	Next    string         `json:"next,omitempty" yaml:"next,omitempty"`
+	Offset  string         `json:"offset,omitempty" yaml:"offset,omitempty"`
+}
+
+type CertificateQueryString struct {
+	Offset string `json:"offset,omitempty"`
+	Size   int    `json:"size"`
+	Tags   string `json:"tags,omitempty"`
 }
 
 const CertificatesPath = "/certificates/"
 
 func (certificateClient *CertificateClient) GetById(id string) (*Certificate, error) {
+	return certificateClient.GetByIdWithContext(context.Background(), id)
+}
 
-	r, body, errs := newGet(certificateClient.config, certificateClient.config.HostAddress+CertificatesPath+id).End()
+func (certificateClient *CertificateClient) GetByIdWithContext(ctx context.Context, id string) (*Certificate, error) {
+
+	r, body, errs := newGet(ctx, certificateClient.config, certificateClient.config.HostAddress+CertificatesPath+id).End()
 	if errs != nil {
 		return nil, fmt.Errorf("could not get certificate, error: %v", errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return nil, fmt.Errorf("not authorised, message from kong: %s", body)
+	if r.StatusCode == 404 {
+		return nil, nil
+	}
+
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
 	}
 
 	certificate := &Certificate{}
@@ -53,14 +73,18 @@ func (certificateClient *CertificateClient) GetById(id string) (*Certificate, er
 }
 
 func (certificateClient *CertificateClient) Create(certificateRequest *CertificateRequest) (*Certificate, error) {
+	return certificateClient.CreateWithContext(context.Background(), certificateRequest)
+}
+
+func (certificateClient *CertificateClient) CreateWithContext(ctx context.Context, certificateRequest *CertificateRequest) (*Certificate, error) {
 
-	r, body, errs := newPost(certificateClient.config, certificateClient.config.HostAddress+CertificatesPath).Send(certificateRequest).End()
+	r, body, errs := newPost(ctx, certificateClient.config, certificateClient.config.HostAddress+CertificatesPath).Send(certificateRequest).End()
 	if errs != nil {
 		return nil, fmt.Errorf("could not create new certificate, error: %v", errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return nil, fmt.Errorf("not authorised, message from kong: %s", body)
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
 	}
 
 	createdCertificate := &Certificate{}
@@ -77,48 +101,87 @@ func (certificateClient *CertificateClient) Create(certificateRequest *Certifica
 }
 
 func (certificateClient *CertificateClient) DeleteById(id string) error {
+	return certificateClient.DeleteByIdWithContext(context.Background(), id)
+}
+
+func (certificateClient *CertificateClient) DeleteByIdWithContext(ctx context.Context, id string) error {
 
-	r, body, errs := newDelete(certificateClient.config, certificateClient.config.HostAddress+CertificatesPath+id).End()
+	r, body, errs := newDelete(ctx, certificateClient.config, certificateClient.config.HostAddress+CertificatesPath+id).End()
 	if errs != nil {
 		return fmt.Errorf("could not delete certificate, result: %v error: %v", r, errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return fmt.Errorf("not authorised, message from kong: %s", body)
+	if err := checkResponse(r, body); err != nil {
+		return err
 	}
 
 	return nil
 }
 
 func (certificateClient *CertificateClient) List() (*Certificates, error) {
+	return certificateClient.ListWithContext(context.Background())
+}
 
-	r, body, errs := newGet(certificateClient.config, certificateClient.config.HostAddress+CertificatesPath).End()
-	if errs != nil {
-		return nil, fmt.Errorf("could not get certificates, error: %v", errs)
+// ListWithContext returns every certificate, following pagination
+func (certificateClient *CertificateClient) ListWithContext(ctx context.Context) (*Certificates, error) {
+	results, err := certificateClient.GetCertificatesWithContext(ctx, &CertificateQueryString{})
+	if err != nil {
+		return nil, err
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return nil, fmt.Errorf("not authorised, message from kong: %s", body)
-	}
+	return &Certificates{Results: results}, nil
+}
 
-	certificates := &Certificates{}
-	err := json.Unmarshal([]byte(body), certificates)
-	if err != nil {
-		return nil, fmt.Errorf("could not parse certificates list response, error: %v", err)
+func (certificateClient *CertificateClient) GetCertificates(query *CertificateQueryString) ([]*Certificate, error) {
+	return certificateClient.GetCertificatesWithContext(context.Background(), query)
+}
+
+// GetCertificatesWithContext returns every certificate matching the query, following pagination
+func (certificateClient *CertificateClient) GetCertificatesWithContext(ctx context.Context, query *CertificateQueryString) ([]*Certificate, error) {
+	certificates := make([]*Certificate, 0)
+
+	pages := newPager(ctx, certificateClient.config, certificateClient.config.HostAddress+CertificatesPath, "certificates", *query)
+	for pages.more() {
+		data := &Certificates{}
+		if err := pages.next(data); err != nil {
+			return nil, err
+		}
+
+		certificates = append(certificates, data.Results...)
 	}
 
 	return certificates, nil
 }
 
+// Iter returns an iterator over the certificates matching the query
+func (certificateClient *CertificateClient) Iter(ctx context.Context, query *CertificateQueryString) *CertificateIterator {
+	pages := newPager(ctx, certificateClient.config, certificateClient.config.HostAddress+CertificatesPath, "certificates", *query)
+	return &CertificateIterator{iterator: newIterator(pages, func() listPage { return &Certificates{} })}
+}
+
+type CertificateIterator struct {
+	iterator
+}
+
+// Value returns the certificate the iterator is positioned on
+func (certificateIterator *CertificateIterator) Value() *Certificate {
+	certificate, _ := certificateIterator.value.(*Certificate)
+	return certificate
+}
+
 func (certificateClient *CertificateClient) UpdateById(id string, certificateRequest *CertificateRequest) (*Certificate, error) {
+	return certificateClient.UpdateByIdWithContext(context.Background(), id, certificateRequest)
+}
 
-	r, body, errs := newPatch(certificateClient.config, certificateClient.config.HostAddress+CertificatesPath+id).Send(certificateRequest).End()
+func (certificateClient *CertificateClient) UpdateByIdWithContext(ctx context.Context, id string, certificateRequest *CertificateRequest) (*Certificate, error) {
+
+	r, body, errs := newPatch(ctx, certificateClient.config, certificateClient.config.HostAddress+CertificatesPath+id).Send(certificateRequest).End()
 	if errs != nil {
 		return nil, fmt.Errorf("could not update certificate, error: %v", errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return nil, fmt.Errorf("not authorised, message from kong: %s", body)
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
 	}
 
 	updatedCertificate := &Certificate{}
@@ -133,3 +196,33 @@ func (certificateClient *CertificateClient) UpdateById(id string, certificateReq
 
 	return updatedCertificate, nil
 }
+
+func (certificateClient *CertificateClient) Upsert(id string, certificateRequest *CertificateRequest) (*Certificate, error) {
+	return certificateClient.UpsertWithContext(context.Background(), id, certificateRequest)
+}
+
+// UpsertWithContext creates the certificate with the id or, when it exists, replaces it in full with the request so
+// fields missing from the request go back to their defaults
+func (certificateClient *CertificateClient) UpsertWithContext(ctx context.Context, id string, certificateRequest *CertificateRequest) (*Certificate, error) {
+
+	r, body, errs := newPut(ctx, certificateClient.config, certificateClient.config.HostAddress+CertificatesPath+id).Send(certificateRequest).End()
+	if errs != nil {
+		return nil, fmt.Errorf("could not upsert certificate, error: %v", errs)
+	}
+
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
+	}
+
+	upsertedCertificate := &Certificate{}
+	err := json.Unmarshal([]byte(body), upsertedCertificate)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse certificate upsert response, error: %v", err)
+	}
+
+	if upsertedCertificate.Id == nil {
+		return nil, fmt.Errorf("could not upsert certificate, error: %v", body)
+	}
+
+	return upsertedCertificate, nil
+}
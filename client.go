@@ -1,6 +1,7 @@
 package gokong
 
 import (
+	"net/http"
 	"net/url"
 	"os"
 	"reflect"
@@ -16,9 +17,11 @@ const EnvKongAdminPassword = "KONG_ADMIN_PASSWORD"
 const EnvKongTLSSkipVerify = "TLS_SKIP_VERIFY"
 const EnvKongApiKey = "KONG_API_KEY"
 const EnvKongAdminToken = "KONG_ADMIN_TOKEN"
+const EnvKongVersion = "KONG_ADMIN_VERSION"
 
 type KongAdminClient struct {
-	config *Config
+	config  *Config
+	schemas *schemaCache
 }
 
 type Config struct {
@@ -28,6 +31,17 @@ type Config struct {
 	InsecureSkipVerify bool
 	ApiKey             string
 	AdminToken         string
+	// HttpClient when set is used to send every request to kong, InsecureSkipVerify and Transport are ignored
+	HttpClient *http.Client
+	// Transport when set is used to send every request to kong, InsecureSkipVerify is ignored
+	Transport http.RoundTripper
+	// RetryPolicy when set retries requests that fail with a transient error, see NewDefaultRetryPolicy
+	RetryPolicy *RetryPolicy
+	// KongVersion when set is the version of kong being called, e.g. "2.1.4", request fields added in later versions
+	// of kong are left out of requests.  Status().GetVersion returns the version of kong
+	KongVersion string
+	// rootAddress is the HostAddress before Workspace prefixed it with a workspace
+	rootAddress string
 }
 
 func addQueryString(currentUrl string, filter interface{}) (string, error) {
@@ -79,13 +93,45 @@ func NewDefaultConfig() *Config {
 	if os.Getenv(EnvKongAdminToken) != "" {
 		config.AdminToken = os.Getenv(EnvKongAdminToken)
 	}
+	if os.Getenv(EnvKongVersion) != "" {
+		config.KongVersion = os.Getenv(EnvKongVersion)
+	}
 
 	return config
 }
 
 func NewClient(config *Config) *KongAdminClient {
 	return &KongAdminClient{
-		config: config,
+		config:  config,
+		schemas: newSchemaCache(),
+	}
+}
+
+// Workspace returns a client whose sub clients all target the given workspace (kong enterprise) by prefixing
+// every path with /{workspace}, calling it on a workspace client switches to the other workspace
+func (kongAdminClient *KongAdminClient) Workspace(name string) *KongAdminClient {
+	config := *kongAdminClient.config
+	config.rootAddress = config.adminAddress()
+	config.HostAddress = strings.TrimRight(config.rootAddress, "/") + "/" + url.PathEscape(name)
+
+	return &KongAdminClient{
+		config:  &config,
+		schemas: kongAdminClient.schemas,
+	}
+}
+
+// adminAddress returns the address of the kong admin api without the workspace prefix added by Workspace, for the
+// endpoints kong does not scope to a workspace
+func (config *Config) adminAddress() string {
+	if config.rootAddress != "" {
+		return config.rootAddress
+	}
+	return config.HostAddress
+}
+
+func (kongAdminClient *KongAdminClient) Workspaces() *WorkspaceClient {
+	return &WorkspaceClient{
+		config: kongAdminClient.config,
 	}
 }
 
@@ -114,6 +160,12 @@ func (kongAdminClient *KongAdminClient) Certificates() *CertificateClient {
 	}
 }
 
+func (kongAdminClient *KongAdminClient) CACertificates() *CACertificateClient {
+	return &CACertificateClient{
+		config: kongAdminClient.config,
+	}
+}
+
 func (kongAdminClient *KongAdminClient) Snis() *SnisClient {
 	return &SnisClient{
 		config: kongAdminClient.config,
@@ -143,3 +195,88 @@ func (kongAdminClient *KongAdminClient) Targets() *TargetClient {
 		config: kongAdminClient.config,
 	}
 }
+
+func (kongAdminClient *KongAdminClient) KeyAuths() *KeyAuthClient {
+	return &KeyAuthClient{
+		credentialClient{
+			config:       kongAdminClient.config,
+			name:         "key auth",
+			consumerPath: "key-auth",
+			path:         KeyAuthsPath,
+			newPage:      func() listPage { return &KeyAuths{} },
+		},
+	}
+}
+
+func (kongAdminClient *KongAdminClient) BasicAuths() *BasicAuthClient {
+	return &BasicAuthClient{
+		credentialClient{
+			config:       kongAdminClient.config,
+			name:         "basic auth",
+			consumerPath: "basic-auth",
+			path:         BasicAuthsPath,
+			newPage:      func() listPage { return &BasicAuths{} },
+		},
+	}
+}
+
+func (kongAdminClient *KongAdminClient) Jwts() *JwtClient {
+	return &JwtClient{
+		credentialClient{
+			config:       kongAdminClient.config,
+			name:         "jwt",
+			consumerPath: "jwt",
+			path:         JwtsPath,
+			newPage:      func() listPage { return &Jwts{} },
+		},
+	}
+}
+
+func (kongAdminClient *KongAdminClient) HmacAuths() *HmacAuthClient {
+	return &HmacAuthClient{
+		credentialClient{
+			config:       kongAdminClient.config,
+			name:         "hmac auth",
+			consumerPath: "hmac-auth",
+			path:         HmacAuthsPath,
+			newPage:      func() listPage { return &HmacAuths{} },
+		},
+	}
+}
+
+func (kongAdminClient *KongAdminClient) OAuth2Credentials() *OAuth2CredentialClient {
+	return &OAuth2CredentialClient{
+		credentialClient{
+			config:       kongAdminClient.config,
+			name:         "oauth2 credential",
+			consumerPath: "oauth2",
+			path:         OAuth2CredentialsPath,
+			newPage:      func() listPage { return &OAuth2Credentials{} },
+		},
+	}
+}
+
+func (kongAdminClient *KongAdminClient) Acls() *AclClient {
+	return &AclClient{
+		credentialClient{
+			config:       kongAdminClient.config,
+			name:         "acl",
+			consumerPath: "acls",
+			path:         AclsPath,
+			newPage:      func() listPage { return &Acls{} },
+		},
+	}
+}
+
+func (kongAdminClient *KongAdminClient) Schemas() *SchemaClient {
+	return &SchemaClient{
+		config: kongAdminClient.config,
+		cache:  kongAdminClient.schemas,
+	}
+}
+
+func (kongAdminClient *KongAdminClient) Tags() *TagClient {
+	return &TagClient{
+		config: kongAdminClient.config,
+	}
+}
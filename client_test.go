@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"strconv"
 	"testing"
 
 	"github.com/phayes/freeport"
@@ -75,3 +77,50 @@ func StartServer(port int, ch <-chan bool) {
 		log.Fatal(err)
 	}
 }
+
+type countingTransport struct {
+	requests int
+}
+
+func (c *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	c.requests++
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func Test_ClientUsesConfiguredTransport(t *testing.T) {
+	transport := &countingTransport{}
+	config := NewDefaultConfig()
+	config.Transport = transport
+
+	result, err := NewClient(config).Status().Get()
+
+	assert.Nil(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, 1, transport.requests)
+}
+
+func Test_ClientUsesConfiguredHttpClient(t *testing.T) {
+	transport := &countingTransport{}
+	config := NewDefaultConfig()
+	config.HttpClient = &http.Client{Transport: transport}
+
+	result, err := NewClient(config).Status().Get()
+
+	assert.Nil(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, 1, transport.requests)
+}
+
+// newPagedServer serves each of pages in turn as a page of a kong list response, recording the query string of every request
+func newPagedServer(queries *[]string, pages []string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*queries = append(*queries, r.URL.RawQuery)
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		if page+1 < len(pages) {
+			fmt.Fprintf(w, `{"data":[%s],"next":"%s?offset=%d","offset":"%d"}`, pages[page], r.URL.Path, page+1, page+1)
+			return
+		}
+		fmt.Fprintf(w, `{"data":[%s],"next":null}`, pages[page])
+	}))
+}
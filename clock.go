@@ -0,0 +1,7 @@
+package gokong
+
+import "time"
+
+// clock returns the current time, it is shared by key rotation and the certificate checks so tests can replace it
+// to move time forwards
+var clock = time.Now
@@ -1,6 +1,7 @@
 package gokong
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 )
@@ -10,19 +11,30 @@ type ConsumerClient struct {
 }
 
 type ConsumerRequest struct {
-	Username string `json:"username,omitempty" yaml:"username,omitempty"`
-	CustomId string `json:"custom_id,omitempty" yaml:"custom_id,omitempty"`
+	Username string    `json:"username,omitempty" yaml:"username,omitempty"`
+	CustomId string    `json:"custom_id,omitempty" yaml:"custom_id,omitempty"`
+	Tags     []*string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Fields   `json:"-" yaml:"-"`
 }
 
 type Consumer struct {
-	Id       string `json:"id,omitempty" yaml:"id,omitempty"`
-	CustomId string `json:"custom_id,omitempty" yaml:"custom_id,omitempty"`
-	Username string `json:"username,omitempty" yaml:"username,omitempty"`
+	Id       string    `json:"id,omitempty" yaml:"id,omitempty"`
+	CustomId string    `json:"custom_id,omitempty" yaml:"custom_id,omitempty"`
+	Username string    `json:"username,omitempty" yaml:"username,omitempty"`
+	Tags     []*string `json:"tags,omitempty" yaml:"tags,omitempty"`
 }
 
 type Consumers struct {
 	Results []*Consumer `json:"data,omitempty" yaml:"data,omitempty"`
 	Next    string      `json:"next,omitempty" yaml:"next,omitempty"`
+	Offset  string      `json:"offset,omitempty" yaml:"offset,omitempty"`
+}
+
+type ConsumerQueryString struct {
+	Offset   string `json:"offset,omitempty"`
+	Size     int    `json:"size"`
+	CustomId string `json:"custom_id,omitempty"`
+	Tags     string `json:"tags,omitempty"`
 }
 
 type ConsumerPluginConfig struct {
@@ -33,18 +45,30 @@ type ConsumerPluginConfig struct {
 const ConsumersPath = "/consumers/"
 
 func (consumerClient *ConsumerClient) GetByUsername(username string) (*Consumer, error) {
-	return consumerClient.GetById(username)
+	return consumerClient.GetByUsernameWithContext(context.Background(), username)
+}
+
+func (consumerClient *ConsumerClient) GetByUsernameWithContext(ctx context.Context, username string) (*Consumer, error) {
+	return consumerClient.GetByIdWithContext(ctx, username)
 }
 
 func (consumerClient *ConsumerClient) GetById(id string) (*Consumer, error) {
+	return consumerClient.GetByIdWithContext(context.Background(), id)
+}
+
+func (consumerClient *ConsumerClient) GetByIdWithContext(ctx context.Context, id string) (*Consumer, error) {
 
-	r, body, errs := newGet(consumerClient.config, consumerClient.config.HostAddress+ConsumersPath+id).End()
+	r, body, errs := newGet(ctx, consumerClient.config, consumerClient.config.HostAddress+ConsumersPath+id).End()
 	if errs != nil {
 		return nil, fmt.Errorf("could not get consumer, error: %v", errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return nil, fmt.Errorf("not authorised, message from kong: %s", body)
+	if r.StatusCode == 404 {
+		return nil, nil
+	}
+
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
 	}
 
 	consumer := &Consumer{}
@@ -61,14 +85,18 @@ func (consumerClient *ConsumerClient) GetById(id string) (*Consumer, error) {
 }
 
 func (consumerClient *ConsumerClient) Create(consumerRequest *ConsumerRequest) (*Consumer, error) {
+	return consumerClient.CreateWithContext(context.Background(), consumerRequest)
+}
 
-	r, body, errs := newPost(consumerClient.config, consumerClient.config.HostAddress+ConsumersPath).Send(consumerRequest).End()
+func (consumerClient *ConsumerClient) CreateWithContext(ctx context.Context, consumerRequest *ConsumerRequest) (*Consumer, error) {
+
+	r, body, errs := newPost(ctx, consumerClient.config, consumerClient.config.HostAddress+ConsumersPath).Send(consumerRequest).End()
 	if errs != nil {
 		return nil, fmt.Errorf("could not create new consumer, error: %v", errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return nil, fmt.Errorf("not authorised, message from kong: %s", body)
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
 	}
 
 	createdConsumer := &Consumer{}
@@ -85,56 +113,103 @@ func (consumerClient *ConsumerClient) Create(consumerRequest *ConsumerRequest) (
 }
 
 func (consumerClient *ConsumerClient) List() (*Consumers, error) {
+	return consumerClient.ListWithContext(context.Background())
+}
 
-	r, body, errs := newGet(consumerClient.config, consumerClient.config.HostAddress+ConsumersPath).End()
-	if errs != nil {
-		return nil, fmt.Errorf("could not get consumers, error: %v", errs)
+// ListWithContext returns every consumer, following pagination
+func (consumerClient *ConsumerClient) ListWithContext(ctx context.Context) (*Consumers, error) {
+	consumers, err := consumerClient.GetConsumersWithContext(ctx, &ConsumerQueryString{})
+	if err != nil {
+		return nil, err
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return nil, fmt.Errorf("not authorised, message from kong: %s", body)
-	}
+	return &Consumers{Results: consumers}, nil
+}
 
-	consumers := &Consumers{}
-	err := json.Unmarshal([]byte(body), consumers)
-	if err != nil {
-		return nil, fmt.Errorf("could not parse consumers list response, error: %v", err)
+func (consumerClient *ConsumerClient) GetConsumers(query *ConsumerQueryString) ([]*Consumer, error) {
+	return consumerClient.GetConsumersWithContext(context.Background(), query)
+}
+
+// GetConsumersWithContext returns every consumer matching the query, following pagination
+func (consumerClient *ConsumerClient) GetConsumersWithContext(ctx context.Context, query *ConsumerQueryString) ([]*Consumer, error) {
+	consumers := make([]*Consumer, 0)
+
+	pages := newPager(ctx, consumerClient.config, consumerClient.config.HostAddress+ConsumersPath, "consumers", *query)
+	for pages.more() {
+		data := &Consumers{}
+		if err := pages.next(data); err != nil {
+			return nil, err
+		}
+
+		consumers = append(consumers, data.Results...)
 	}
 
 	return consumers, nil
 }
 
+// Iter returns an iterator over the consumers matching the query
+func (consumerClient *ConsumerClient) Iter(ctx context.Context, query *ConsumerQueryString) *ConsumerIterator {
+	pages := newPager(ctx, consumerClient.config, consumerClient.config.HostAddress+ConsumersPath, "consumers", *query)
+	return &ConsumerIterator{iterator: newIterator(pages, func() listPage { return &Consumers{} })}
+}
+
+type ConsumerIterator struct {
+	iterator
+}
+
+// Value returns the consumer the iterator is positioned on
+func (consumerIterator *ConsumerIterator) Value() *Consumer {
+	consumer, _ := consumerIterator.value.(*Consumer)
+	return consumer
+}
+
 func (consumerClient *ConsumerClient) DeleteByUsername(username string) error {
-	return consumerClient.DeleteById(username)
+	return consumerClient.DeleteByUsernameWithContext(context.Background(), username)
+}
+
+func (consumerClient *ConsumerClient) DeleteByUsernameWithContext(ctx context.Context, username string) error {
+	return consumerClient.DeleteByIdWithContext(ctx, username)
 }
 
 func (consumerClient *ConsumerClient) DeleteById(id string) error {
+	return consumerClient.DeleteByIdWithContext(context.Background(), id)
+}
 
-	r, body, errs := newDelete(consumerClient.config, consumerClient.config.HostAddress+ConsumersPath+id).End()
+func (consumerClient *ConsumerClient) DeleteByIdWithContext(ctx context.Context, id string) error {
+
+	r, body, errs := newDelete(ctx, consumerClient.config, consumerClient.config.HostAddress+ConsumersPath+id).End()
 	if errs != nil {
 		return fmt.Errorf("could not delete consumer, result: %v error: %v", r, errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return fmt.Errorf("not authorised, message from kong: %s", body)
+	if err := checkResponse(r, body); err != nil {
+		return err
 	}
 
 	return nil
 }
 
 func (consumerClient *ConsumerClient) UpdateByUsername(username string, consumerRequest *ConsumerRequest) (*Consumer, error) {
-	return consumerClient.UpdateById(username, consumerRequest)
+	return consumerClient.UpdateByUsernameWithContext(context.Background(), username, consumerRequest)
+}
+
+func (consumerClient *ConsumerClient) UpdateByUsernameWithContext(ctx context.Context, username string, consumerRequest *ConsumerRequest) (*Consumer, error) {
+	return consumerClient.UpdateByIdWithContext(ctx, username, consumerRequest)
 }
 
 func (consumerClient *ConsumerClient) UpdateById(id string, consumerRequest *ConsumerRequest) (*Consumer, error) {
+	return consumerClient.UpdateByIdWithContext(context.Background(), id, consumerRequest)
+}
 
-	r, body, errs := newPatch(consumerClient.config, consumerClient.config.HostAddress+ConsumersPath+id).Send(consumerRequest).End()
+func (consumerClient *ConsumerClient) UpdateByIdWithContext(ctx context.Context, id string, consumerRequest *ConsumerRequest) (*Consumer, error) {
+
+	r, body, errs := newPatch(ctx, consumerClient.config, consumerClient.config.HostAddress+ConsumersPath+id).Send(consumerRequest).End()
 	if errs != nil {
 		return nil, fmt.Errorf("could not update consumer, error: %v", errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return nil, fmt.Errorf("not authorised, message from kong: %s", body)
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
 	}
 
 	updatedConsumer := &Consumer{}
@@ -150,15 +225,49 @@ func (consumerClient *ConsumerClient) UpdateById(id string, consumerRequest *Con
 	return updatedConsumer, nil
 }
 
+func (consumerClient *ConsumerClient) Upsert(usernameOrId string, consumerRequest *ConsumerRequest) (*Consumer, error) {
+	return consumerClient.UpsertWithContext(context.Background(), usernameOrId, consumerRequest)
+}
+
+// UpsertWithContext creates the consumer with the username or id or, when it exists, replaces it in full with the
+// request so fields missing from the request go back to their defaults
+func (consumerClient *ConsumerClient) UpsertWithContext(ctx context.Context, usernameOrId string, consumerRequest *ConsumerRequest) (*Consumer, error) {
+
+	r, body, errs := newPut(ctx, consumerClient.config, consumerClient.config.HostAddress+ConsumersPath+usernameOrId).Send(consumerRequest).End()
+	if errs != nil {
+		return nil, fmt.Errorf("could not upsert consumer, error: %v", errs)
+	}
+
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
+	}
+
+	upsertedConsumer := &Consumer{}
+	err := json.Unmarshal([]byte(body), upsertedConsumer)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse consumer upsert response, error: %v", err)
+	}
+
+	if upsertedConsumer.Id == "" {
+		return nil, fmt.Errorf("could not upsert consumer, error: %v", body)
+	}
+
+	return upsertedConsumer, nil
+}
+
 func (consumerClient *ConsumerClient) CreatePluginConfig(consumerId string, pluginName string, pluginConfig string) (*ConsumerPluginConfig, error) {
+	return consumerClient.CreatePluginConfigWithContext(context.Background(), consumerId, pluginName, pluginConfig)
+}
+
+func (consumerClient *ConsumerClient) CreatePluginConfigWithContext(ctx context.Context, consumerId string, pluginName string, pluginConfig string) (*ConsumerPluginConfig, error) {
 
-	r, body, errs := newPost(consumerClient.config, consumerClient.config.HostAddress+ConsumersPath+consumerId+"/"+pluginName).Send(pluginConfig).End()
+	r, body, errs := newPost(ctx, consumerClient.config, consumerClient.config.HostAddress+ConsumersPath+consumerId+"/"+pluginName).Send(pluginConfig).End()
 	if errs != nil {
 		return nil, fmt.Errorf("could not configure plugin for consumer, error: %v", errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return nil, fmt.Errorf("not authorised, message from kong: %s", body)
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
 	}
 
 	createdConsumerPluginConfig := &ConsumerPluginConfig{}
@@ -177,14 +286,22 @@ func (consumerClient *ConsumerClient) CreatePluginConfig(consumerId string, plug
 }
 
 func (consumerClient *ConsumerClient) GetPluginConfig(consumerId string, pluginName string, id string) (*ConsumerPluginConfig, error) {
+	return consumerClient.GetPluginConfigWithContext(context.Background(), consumerId, pluginName, id)
+}
+
+func (consumerClient *ConsumerClient) GetPluginConfigWithContext(ctx context.Context, consumerId string, pluginName string, id string) (*ConsumerPluginConfig, error) {
 
-	r, body, errs := newGet(consumerClient.config, consumerClient.config.HostAddress+ConsumersPath+consumerId+"/"+pluginName+"/"+id).End()
+	r, body, errs := newGet(ctx, consumerClient.config, consumerClient.config.HostAddress+ConsumersPath+consumerId+"/"+pluginName+"/"+id).End()
 	if errs != nil {
 		return nil, fmt.Errorf("could not get plugin config for consumer, error: %v", errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return nil, fmt.Errorf("not authorised, message from kong: %s", body)
+	if r.StatusCode == 404 {
+		return nil, nil
+	}
+
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
 	}
 
 	consumerPluginConfig := &ConsumerPluginConfig{}
@@ -203,14 +320,18 @@ func (consumerClient *ConsumerClient) GetPluginConfig(consumerId string, pluginN
 }
 
 func (consumerClient *ConsumerClient) DeletePluginConfig(consumerId string, pluginName string, id string) error {
+	return consumerClient.DeletePluginConfigWithContext(context.Background(), consumerId, pluginName, id)
+}
+
+func (consumerClient *ConsumerClient) DeletePluginConfigWithContext(ctx context.Context, consumerId string, pluginName string, id string) error {
 
-	r, body, errs := newDelete(consumerClient.config, consumerClient.config.HostAddress+ConsumersPath+consumerId+"/"+pluginName+"/"+id).End()
+	r, body, errs := newDelete(ctx, consumerClient.config, consumerClient.config.HostAddress+ConsumersPath+consumerId+"/"+pluginName+"/"+id).End()
 	if errs != nil {
 		return fmt.Errorf("could not delete plugin config for consumer, error: %v", errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return fmt.Errorf("not authorised, message from kong: %s", body)
+	if err := checkResponse(r, body); err != nil {
+		return err
 	}
 
 	return nil
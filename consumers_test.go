@@ -1,6 +1,9 @@
 package gokong
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	uuid "github.com/satori/go.uuid"
@@ -131,6 +134,71 @@ func Test_ConsumersList(t *testing.T) {
 
 }
 
+func Test_ConsumersGetConsumersFilteredByCustomId(t *testing.T) {
+	client := NewClient(NewDefaultConfig())
+
+	consumerRequest := &ConsumerRequest{
+		Username: "username-" + uuid.NewV4().String(),
+		CustomId: "test-" + uuid.NewV4().String(),
+	}
+	createdConsumer, err := client.Consumers().Create(consumerRequest)
+	assert.Nil(t, err)
+
+	otherConsumer, err := client.Consumers().Create(&ConsumerRequest{Username: "username-" + uuid.NewV4().String()})
+	assert.Nil(t, err)
+
+	results, err := client.Consumers().GetConsumers(&ConsumerQueryString{CustomId: consumerRequest.CustomId})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []*Consumer{createdConsumer}, results)
+
+	err = client.Consumers().DeleteById(createdConsumer.Id)
+	assert.Nil(t, err)
+	err = client.Consumers().DeleteById(otherConsumer.Id)
+	assert.Nil(t, err)
+}
+
+func Test_ConsumersListFollowsEveryPage(t *testing.T) {
+	queries := []string{}
+	server := newPagedServer(&queries, []string{`{"id":"1"},{"id":"2"}`, `{"id":"3"}`, `{"id":"4"}`})
+	defer server.Close()
+
+	results, err := NewClient(&Config{HostAddress: server.URL}).Consumers().List()
+
+	assert.Nil(t, err)
+	assert.Equal(t, []*Consumer{{Id: "1"}, {Id: "2"}, {Id: "3"}, {Id: "4"}}, results.Results)
+	assert.Equal(t, []string{"size=100", "offset=1&size=100", "offset=2&size=100"}, queries)
+}
+
+func Test_ConsumersIterStopsFetchingWhenAbandoned(t *testing.T) {
+	queries := []string{}
+	server := newPagedServer(&queries, []string{`{"id":"1"},{"id":"2"}`, `{"id":"3"}`})
+	defer server.Close()
+
+	iterator := NewClient(&Config{HostAddress: server.URL}).Consumers().Iter(context.Background(), &ConsumerQueryString{Size: 200, Tags: "a"})
+
+	assert.True(t, iterator.Next())
+	assert.Equal(t, "1", iterator.Value().Id)
+	assert.True(t, iterator.Next())
+	assert.Equal(t, "2", iterator.Value().Id)
+	assert.Nil(t, iterator.Err())
+	assert.Equal(t, []string{"size=200&tags=a"}, queries)
+}
+
+func Test_ConsumersIterReportsErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(500)
+		w.Write([]byte(`{"message":"An unexpected error occurred"}`))
+	}))
+	defer server.Close()
+
+	iterator := NewClient(&Config{HostAddress: server.URL}).Consumers().Iter(context.Background(), &ConsumerQueryString{})
+
+	assert.False(t, iterator.Next())
+	assert.Nil(t, iterator.Value())
+	assert.NotNil(t, iterator.Err())
+}
+
 func Test_ConsumersDeleteById(t *testing.T) {
 	consumerRequest := &ConsumerRequest{
 		Username: "username-" + uuid.NewV4().String(),
@@ -359,3 +427,17 @@ func Test_AllConsumerEndpointsShouldReturnErrorWhenRequestUnauthorised(t *testin
 	assert.NotNil(t, err)
 
 }
+
+func Test_ConsumersUpsertCreatesAndReplaces(t *testing.T) {
+	client := NewClient(NewDefaultConfig())
+	username := "username-" + uuid.NewV4().String()
+
+	created, err := client.Consumers().Upsert(username, &ConsumerRequest{CustomId: "test-" + uuid.NewV4().String()})
+	assert.Nil(t, err)
+	assert.Equal(t, username, created.Username)
+
+	replaced, err := client.Consumers().Upsert(username, &ConsumerRequest{Username: username})
+	assert.Nil(t, err)
+	assert.Equal(t, created.Id, replaced.Id)
+	assert.Equal(t, "", replaced.CustomId)
+}
@@ -0,0 +1,169 @@
+package gokong
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+)
+
+// credentialClient sends the requests shared by every type of consumer credential, credentials are created and
+// managed beneath their consumer at /consumers/{consumer}/{consumerPath} and listed across every consumer at path.
+// newPage returns an empty page of the list response of the credential type
+type credentialClient struct {
+	config       *Config
+	name         string
+	consumerPath string
+	path         string
+	newPage      func() listPage
+}
+
+type CredentialQueryString struct {
+	Offset string `json:"offset,omitempty"`
+	Size   int    `json:"size"`
+	Tags   string `json:"tags,omitempty"`
+}
+
+func (credentialClient *credentialClient) consumerAddress(consumerUsernameOrId string) string {
+	return credentialClient.config.HostAddress + ConsumersPath + url.PathEscape(consumerUsernameOrId) + "/" + credentialClient.consumerPath + "/"
+}
+
+func (credentialClient *credentialClient) create(ctx context.Context, consumerUsernameOrId string, request interface{}, credential interface{}) error {
+
+	r, body, errs := newPost(ctx, credentialClient.config, credentialClient.consumerAddress(consumerUsernameOrId)).Send(request).End()
+	if errs != nil {
+		return fmt.Errorf("could not create new %s, error: %v", credentialClient.name, errs)
+	}
+
+	if err := checkResponse(r, body); err != nil {
+		return err
+	}
+
+	err := json.Unmarshal([]byte(body), credential)
+	if err != nil {
+		return fmt.Errorf("could not parse %s creation response, error: %v", credentialClient.name, err)
+	}
+
+	return nil
+}
+
+// get fetches the credential at the address, it returns false when kong has no such credential
+func (credentialClient *credentialClient) get(ctx context.Context, address string, credential interface{}) (bool, error) {
+
+	r, body, errs := newGet(ctx, credentialClient.config, address).End()
+	if errs != nil {
+		return false, fmt.Errorf("could not get %s, error: %v", credentialClient.name, errs)
+	}
+
+	if r.StatusCode == 404 {
+		return false, nil
+	}
+
+	if err := checkResponse(r, body); err != nil {
+		return false, err
+	}
+
+	err := json.Unmarshal([]byte(body), credential)
+	if err != nil {
+		return false, fmt.Errorf("could not parse %s get response, error: %v", credentialClient.name, err)
+	}
+
+	return true, nil
+}
+
+// getById fetches the credential of the consumer with the id, it returns false when kong has no such credential
+func (credentialClient *credentialClient) getById(ctx context.Context, consumerUsernameOrId string, id string, credential interface{}) (bool, error) {
+	return credentialClient.get(ctx, credentialClient.consumerAddress(consumerUsernameOrId)+url.PathEscape(id), credential)
+}
+
+func (credentialClient *credentialClient) update(ctx context.Context, consumerUsernameOrId string, id string, request interface{}, credential interface{}) error {
+
+	r, body, errs := newPatch(ctx, credentialClient.config, credentialClient.consumerAddress(consumerUsernameOrId)+url.PathEscape(id)).Send(request).End()
+	if errs != nil {
+		return fmt.Errorf("could not update %s, error: %v", credentialClient.name, errs)
+	}
+
+	if err := checkResponse(r, body); err != nil {
+		return err
+	}
+
+	err := json.Unmarshal([]byte(body), credential)
+	if err != nil {
+		return fmt.Errorf("could not parse %s update response, error: %v", credentialClient.name, err)
+	}
+
+	return nil
+}
+
+func (credentialClient *credentialClient) upsert(ctx context.Context, consumerUsernameOrId string, id string, request interface{}, credential interface{}) error {
+
+	r, body, errs := newPut(ctx, credentialClient.config, credentialClient.consumerAddress(consumerUsernameOrId)+url.PathEscape(id)).Send(request).End()
+	if errs != nil {
+		return fmt.Errorf("could not upsert %s, error: %v", credentialClient.name, errs)
+	}
+
+	if err := checkResponse(r, body); err != nil {
+		return err
+	}
+
+	err := json.Unmarshal([]byte(body), credential)
+	if err != nil {
+		return fmt.Errorf("could not parse %s upsert response, error: %v", credentialClient.name, err)
+	}
+
+	return nil
+}
+
+func (credentialClient *credentialClient) delete(ctx context.Context, consumerUsernameOrId string, id string) error {
+
+	r, body, errs := newDelete(ctx, credentialClient.config, credentialClient.consumerAddress(consumerUsernameOrId)+url.PathEscape(id)).End()
+	if errs != nil {
+		return fmt.Errorf("could not delete %s, result: %v error: %v", credentialClient.name, r, errs)
+	}
+
+	if err := checkResponse(r, body); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// consumer returns the consumer owning the credential, or nil when kong has no such credential
+func (credentialClient *credentialClient) consumer(ctx context.Context, id string) (*Consumer, error) {
+	consumer := &Consumer{}
+	found, err := credentialClient.get(ctx, credentialClient.config.HostAddress+credentialClient.path+url.PathEscape(id)+"/consumer", consumer)
+	if err != nil || !found || consumer.Id == "" {
+		return nil, err
+	}
+
+	return consumer, nil
+}
+
+// pager returns a pager over the credentials of the consumer, or over the credentials of every consumer when
+// consumerUsernameOrId is empty
+func (credentialClient *credentialClient) pager(ctx context.Context, consumerUsernameOrId string, query *CredentialQueryString) *pager {
+	address := credentialClient.config.HostAddress + credentialClient.path
+	if consumerUsernameOrId != "" {
+		address = credentialClient.consumerAddress(consumerUsernameOrId)
+	}
+
+	return newPager(ctx, credentialClient.config, address, credentialClient.name+"s", *query)
+}
+
+// iter returns an iterator over the credentials of the consumer, or over the credentials of every consumer when
+// consumerUsernameOrId is empty
+func (credentialClient *credentialClient) iter(ctx context.Context, consumerUsernameOrId string, query *CredentialQueryString) iterator {
+	return newIterator(credentialClient.pager(ctx, consumerUsernameOrId, query), credentialClient.newPage)
+}
+
+// list appends the credentials iter walks to the slice credentials points to, following pagination
+func (credentialClient *credentialClient) list(ctx context.Context, consumerUsernameOrId string, query *CredentialQueryString, credentials interface{}) error {
+	results := reflect.ValueOf(credentials).Elem()
+	credentialIterator := credentialClient.iter(ctx, consumerUsernameOrId, query)
+	for credentialIterator.Next() {
+		results.Set(reflect.Append(results, reflect.ValueOf(credentialIterator.value)))
+	}
+
+	return credentialIterator.Err()
+}
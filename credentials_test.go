@@ -0,0 +1,221 @@
+package gokong
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func createCredentialConsumer(t *testing.T, client *KongAdminClient) *Consumer {
+	consumer, err := client.Consumers().Create(&ConsumerRequest{Username: "username-" + uuid.NewV4().String()})
+	assert.Nil(t, err)
+	return consumer
+}
+
+func Test_KeyAuthsCreateGetUpdateAndDelete(t *testing.T) {
+	client := NewClient(NewDefaultConfig())
+	consumer := createCredentialConsumer(t, client)
+
+	key := "key-" + uuid.NewV4().String()
+	keyAuth, err := client.KeyAuths().Create(consumer.Username, &KeyAuthRequest{Key: key, Tags: StringSlice([]string{"team-a"})})
+	assert.Nil(t, err)
+	assert.Equal(t, key, keyAuth.Key)
+	assert.Equal(t, consumer.Id, IdToString(keyAuth.Consumer))
+
+	result, err := client.KeyAuths().GetById(consumer.Id, keyAuth.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, keyAuth, result)
+
+	result, err = client.KeyAuths().GetById(consumer.Id, key)
+	assert.Nil(t, err)
+	assert.Equal(t, keyAuth, result)
+
+	owner, err := client.KeyAuths().GetConsumer(key)
+	assert.Nil(t, err)
+	assert.Equal(t, consumer.Id, owner.Id)
+
+	updatedKey := "key-" + uuid.NewV4().String()
+	updated, err := client.KeyAuths().UpdateById(consumer.Id, keyAuth.Id, &KeyAuthRequest{Key: updatedKey})
+	assert.Nil(t, err)
+	assert.Equal(t, updatedKey, updated.Key)
+
+	err = client.KeyAuths().DeleteById(consumer.Id, keyAuth.Id)
+	assert.Nil(t, err)
+
+	result, err = client.KeyAuths().GetById(consumer.Id, keyAuth.Id)
+	assert.Nil(t, err)
+	assert.Nil(t, result)
+
+	owner, err = client.KeyAuths().GetConsumer(keyAuth.Id)
+	assert.Nil(t, err)
+	assert.Nil(t, owner)
+}
+
+func Test_CredentialsGetByIdEscapesTheId(t *testing.T) {
+	requests := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.RequestURI)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{HostAddress: server.URL})
+
+	keyAuth, err := client.KeyAuths().GetById("bob", "team/a?key#1")
+	assert.Nil(t, err)
+	assert.Nil(t, keyAuth)
+
+	acl, err := client.Acls().GetById("bob", "../admins")
+	assert.Nil(t, err)
+	assert.Nil(t, acl)
+
+	assert.Equal(t, []string{"/consumers/bob/key-auth/team%2Fa%3Fkey%231", "/consumers/bob/acls/..%2Fadmins"}, requests)
+}
+
+func Test_KeyAuthsListFromConsumerAndAcrossConsumers(t *testing.T) {
+	client := NewClient(NewDefaultConfig())
+	consumer := createCredentialConsumer(t, client)
+	other := createCredentialConsumer(t, client)
+	tag := "tag-" + uuid.NewV4().String()
+
+	for i := 0; i < 120; i++ {
+		_, err := client.KeyAuths().Create(consumer.Id, &KeyAuthRequest{Tags: StringSlice([]string{tag})})
+		assert.Nil(t, err)
+	}
+	_, err := client.KeyAuths().Create(other.Id, &KeyAuthRequest{Tags: StringSlice([]string{tag})})
+	assert.Nil(t, err)
+
+	keyAuths, err := client.KeyAuths().ListFromConsumer(consumer.Id, &CredentialQueryString{})
+	assert.Nil(t, err)
+	assert.Equal(t, 120, len(keyAuths))
+
+	keyAuths, err = client.KeyAuths().List(&CredentialQueryString{Tags: tag})
+	assert.Nil(t, err)
+	assert.Equal(t, 121, len(keyAuths))
+}
+
+func Test_BasicAuthsCreateGetAndDelete(t *testing.T) {
+	client := NewClient(NewDefaultConfig())
+	consumer := createCredentialConsumer(t, client)
+
+	username := "user-" + uuid.NewV4().String()
+	basicAuth, err := client.BasicAuths().Create(consumer.Id, &BasicAuthRequest{Username: username, Password: "secret"})
+	assert.Nil(t, err)
+	assert.Equal(t, username, basicAuth.Username)
+	assert.NotEqual(t, "secret", basicAuth.Password)
+
+	result, err := client.BasicAuths().GetById(consumer.Id, username)
+	assert.Nil(t, err)
+	assert.Equal(t, basicAuth, result)
+
+	owner, err := client.BasicAuths().GetConsumer(username)
+	assert.Nil(t, err)
+	assert.Equal(t, consumer.Id, owner.Id)
+
+	err = client.BasicAuths().DeleteById(consumer.Id, basicAuth.Id)
+	assert.Nil(t, err)
+
+	basicAuths, err := client.BasicAuths().ListFromConsumer(consumer.Id, &CredentialQueryString{})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(basicAuths))
+}
+
+func Test_JwtsCreateAndUpdate(t *testing.T) {
+	client := NewClient(NewDefaultConfig())
+	consumer := createCredentialConsumer(t, client)
+
+	jwt, err := client.Jwts().Create(consumer.Id, &JwtRequest{Algorithm: "HS512"})
+	assert.Nil(t, err)
+	assert.NotEqual(t, "", jwt.Key)
+	assert.NotEqual(t, "", jwt.Secret)
+	assert.Equal(t, "HS512", jwt.Algorithm)
+
+	updated, err := client.Jwts().UpdateById(consumer.Id, jwt.Id, &JwtRequest{Secret: "new-secret"})
+	assert.Nil(t, err)
+	assert.Equal(t, "new-secret", updated.Secret)
+	assert.Equal(t, jwt.Key, updated.Key)
+
+	owner, err := client.Jwts().GetConsumer(jwt.Key)
+	assert.Nil(t, err)
+	assert.Equal(t, consumer.Id, owner.Id)
+}
+
+func Test_HmacAuthsCreateAndList(t *testing.T) {
+	client := NewClient(NewDefaultConfig())
+	consumer := createCredentialConsumer(t, client)
+
+	hmacAuth, err := client.HmacAuths().Create(consumer.Id, &HmacAuthRequest{Username: "hmac-" + uuid.NewV4().String()})
+	assert.Nil(t, err)
+	assert.NotEqual(t, "", hmacAuth.Secret)
+
+	hmacAuths, err := client.HmacAuths().ListFromConsumer(consumer.Id, &CredentialQueryString{})
+	assert.Nil(t, err)
+	assert.Equal(t, []*HmacAuth{hmacAuth}, hmacAuths)
+}
+
+func Test_OAuth2CredentialsCreateAndGetByClientId(t *testing.T) {
+	client := NewClient(NewDefaultConfig())
+	consumer := createCredentialConsumer(t, client)
+
+	oauth2Credential, err := client.OAuth2Credentials().Create(consumer.Id, &OAuth2CredentialRequest{
+		Name:         "my-app",
+		RedirectUris: []string{"https://example.com/callback"},
+	})
+	assert.Nil(t, err)
+	assert.NotEqual(t, "", oauth2Credential.ClientId)
+	assert.NotEqual(t, "", oauth2Credential.ClientSecret)
+
+	result, err := client.OAuth2Credentials().GetById(consumer.Id, oauth2Credential.ClientId)
+	assert.Nil(t, err)
+	assert.Equal(t, oauth2Credential, result)
+
+	owner, err := client.OAuth2Credentials().GetConsumer(oauth2Credential.ClientId)
+	assert.Nil(t, err)
+	assert.Equal(t, consumer.Id, owner.Id)
+}
+
+func Test_AclsCreateListAndDelete(t *testing.T) {
+	client := NewClient(NewDefaultConfig())
+	consumer := createCredentialConsumer(t, client)
+
+	admins, err := client.Acls().Create(consumer.Id, &AclRequest{Group: "admins"})
+	assert.Nil(t, err)
+	_, err = client.Acls().Create(consumer.Id, &AclRequest{Group: "readers"})
+	assert.Nil(t, err)
+
+	_, err = client.Acls().Create(consumer.Id, &AclRequest{Group: "admins"})
+	assert.True(t, IsConflict(err))
+
+	acls, err := client.Acls().ListFromConsumer(consumer.Id, &CredentialQueryString{})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(acls))
+
+	owner, err := client.Acls().GetConsumer(admins.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, consumer.Id, owner.Id)
+
+	err = client.Acls().DeleteById(consumer.Id, admins.Id)
+	assert.Nil(t, err)
+
+	acls, err = client.Acls().ListFromConsumer(consumer.Id, &CredentialQueryString{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(acls))
+}
+
+func Test_KeyAuthsUpsert(t *testing.T) {
+	client := NewClient(NewDefaultConfig())
+	consumer := createCredentialConsumer(t, client)
+	id := uuid.NewV4().String()
+
+	created, err := client.KeyAuths().Upsert(consumer.Id, id, &KeyAuthRequest{Key: "key-" + id})
+	assert.Nil(t, err)
+	assert.Equal(t, id, created.Id)
+
+	replaced, err := client.KeyAuths().Upsert(consumer.Username, id, &KeyAuthRequest{Key: "new-key-" + id})
+	assert.Nil(t, err)
+	assert.Equal(t, id, replaced.Id)
+	assert.Equal(t, "new-key-"+id, replaced.Key)
+}
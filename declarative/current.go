@@ -0,0 +1,170 @@
+package declarative
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kevholditch/gokong"
+)
+
+// current is the state of kong keyed the same way as the desired state, entities without a name are keyed by their id
+type current struct {
+	services     map[string]*gokong.Service
+	routes       map[string]*gokong.Route
+	consumers    map[string]*gokong.Consumer
+	upstreams    map[string]*gokong.Upstream
+	targets      map[string]map[string]*gokong.Target
+	certificates map[string]*gokong.Certificate
+	snis         map[string][]string
+	plugins      map[pluginKey]*gokong.Plugin
+}
+
+type pluginKey struct {
+	name     string
+	service  string
+	route    string
+	consumer string
+}
+
+func (key pluginKey) String() string {
+	scope := []string{}
+	if key.service != "" {
+		scope = append(scope, "service="+key.service)
+	}
+	if key.route != "" {
+		scope = append(scope, "route="+key.route)
+	}
+	if key.consumer != "" {
+		scope = append(scope, "consumer="+key.consumer)
+	}
+	if len(scope) == 0 {
+		return key.name
+	}
+	return fmt.Sprintf("%s (%s)", key.name, strings.Join(scope, ", "))
+}
+
+func certificateKey(cert *string) string {
+	if cert == nil {
+		return ""
+	}
+	return strings.TrimSpace(*cert)
+}
+
+func nameOrId(name *string, id *string) string {
+	if name != nil && *name != "" {
+		return *name
+	}
+	return *id
+}
+
+func readCurrent(ctx context.Context, client *gokong.KongAdminClient) (*current, *ids, error) {
+	state := &current{
+		services:     map[string]*gokong.Service{},
+		routes:       map[string]*gokong.Route{},
+		consumers:    map[string]*gokong.Consumer{},
+		upstreams:    map[string]*gokong.Upstream{},
+		targets:      map[string]map[string]*gokong.Target{},
+		certificates: map[string]*gokong.Certificate{},
+		snis:         map[string][]string{},
+		plugins:      map[pluginKey]*gokong.Plugin{},
+	}
+	known := &ids{
+		services:     map[string]string{},
+		routes:       map[string]string{},
+		consumers:    map[string]string{},
+		certificates: map[string]string{},
+	}
+	serviceNames := map[string]string{}
+	routeNames := map[string]string{}
+	consumerNames := map[string]string{}
+
+	services, err := client.Services().GetServicesWithContext(ctx, &gokong.ServiceQueryString{})
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, service := range services {
+		name := nameOrId(service.Name, service.Id)
+		state.services[name] = service
+		known.services[name] = *service.Id
+		serviceNames[*service.Id] = name
+	}
+
+	routes, err := client.Routes().ListWithContext(ctx, &gokong.RouteQueryString{})
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, route := range routes {
+		name := nameOrId(route.Name, route.Id)
+		state.routes[name] = route
+		known.routes[name] = *route.Id
+		routeNames[*route.Id] = name
+	}
+
+	consumers, err := client.Consumers().ListWithContext(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, consumer := range consumers.Results {
+		name := consumerName(consumer.Username, consumer.CustomId)
+		if name == "" {
+			name = consumer.Id
+		}
+		state.consumers[name] = consumer
+		known.consumers[name] = consumer.Id
+		consumerNames[consumer.Id] = name
+	}
+
+	upstreams, err := client.Upstreams().ListWithContext(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, upstream := range upstreams.Results {
+		state.upstreams[upstream.Name] = upstream
+		targets, err := client.Targets().GetTargetsFromUpstreamIdWithContext(ctx, upstream.Id)
+		if err != nil {
+			return nil, nil, err
+		}
+		state.targets[upstream.Name] = map[string]*gokong.Target{}
+		for _, target := range targets {
+			state.targets[upstream.Name][*target.Target] = target
+		}
+	}
+
+	certificates, err := client.Certificates().ListWithContext(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	certificateKeys := map[string]string{}
+	for _, certificate := range certificates.Results {
+		key := certificateKey(certificate.Cert)
+		state.certificates[key] = certificate
+		known.certificates[key] = *certificate.Id
+		certificateKeys[*certificate.Id] = key
+	}
+
+	snis, err := client.Snis().ListWithContext(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, sni := range snis.Results {
+		key := certificateKeys[gokong.IdToString(sni.CertificateId)]
+		state.snis[key] = append(state.snis[key], sni.Name)
+	}
+
+	plugins, err := client.Plugins().ListWithContext(ctx, &gokong.PluginQueryString{})
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, plugin := range plugins {
+		key := pluginKey{
+			name:     plugin.Name,
+			service:  serviceNames[gokong.IdToString(plugin.ServiceId)],
+			route:    routeNames[gokong.IdToString(plugin.RouteId)],
+			consumer: consumerNames[gokong.IdToString(plugin.ConsumerId)],
+		}
+		state.plugins[key] = plugin
+	}
+
+	return state, known, nil
+}
@@ -0,0 +1,91 @@
+package declarative
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/kevholditch/gokong"
+)
+
+// differs returns true when any field set in desired does not match current.  Fields left unset in desired are
+// not compared so values defaulted by kong do not cause spurious updates.
+func differs(desired interface{}, current interface{}) (bool, error) {
+	desiredValue, err := normalise(desired)
+	if err != nil {
+		return false, err
+	}
+
+	currentValue, err := normalise(current)
+	if err != nil {
+		return false, err
+	}
+
+	return !isSubset(desiredValue, currentValue), nil
+}
+
+func normalise(v interface{}) (interface{}, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var result interface{}
+	err = json.Unmarshal(body, &result)
+	return result, err
+}
+
+func isSubset(desired interface{}, current interface{}) bool {
+	switch d := desired.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		c, ok := current.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for key, value := range d {
+			if value == nil {
+				continue
+			}
+			if !isSubset(value, c[key]) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		c, ok := current.([]interface{})
+		if !ok || len(c) != len(d) {
+			return len(d) == 0 && current == nil
+		}
+		for i := range d {
+			if !isSubset(d[i], c[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(desired, current)
+	}
+}
+
+// sortedKeys returns the keys of a map keyed by string in order so plans are deterministic
+func sortedKeys(m interface{}) []string {
+	keys := []string{}
+	for _, key := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedPluginKeys(m map[pluginKey]*gokong.Plugin) []pluginKey {
+	keys := []pluginKey{}
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+	return keys
+}
@@ -0,0 +1,69 @@
+package declarative
+
+import (
+	"context"
+	"fmt"
+)
+
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+type Kind string
+
+const (
+	KindCertificate Kind = "certificate"
+	KindUpstream    Kind = "upstream"
+	KindTarget      Kind = "target"
+	KindService     Kind = "service"
+	KindRoute       Kind = "route"
+	KindConsumer    Kind = "consumer"
+	KindPlugin      Kind = "plugin"
+)
+
+// Change is a single create, update or delete that needs to be made to kong to reach the desired state
+type Change struct {
+	Action Action `json:"action" yaml:"action"`
+	Kind   Kind   `json:"kind" yaml:"kind"`
+	Name   string `json:"name" yaml:"name"`
+	apply  func(ctx context.Context, ids *ids) error
+}
+
+func (change *Change) String() string {
+	return fmt.Sprintf("%s %s %s", change.Action, change.Kind, change.Name)
+}
+
+// Plan is the ordered list of changes needed to reach the desired state.  Creates and updates come first in
+// dependency order (certificates, upstreams, targets, services, routes, consumers, plugins) followed by deletes in
+// the reverse order.
+type Plan struct {
+	Changes []*Change `json:"changes" yaml:"changes"`
+}
+
+func (plan *Plan) add(action Action, kind Kind, name string, apply func(ctx context.Context, ids *ids) error) {
+	plan.Changes = append(plan.Changes, &Change{Action: action, Kind: kind, Name: name, apply: apply})
+}
+
+// Empty returns true when kong is already in the desired state
+func (plan *Plan) Empty() bool {
+	return len(plan.Changes) == 0
+}
+
+// ids tracks the kong id of every named entity, it is updated as entities are created so later changes can reference them
+type ids struct {
+	services     map[string]string
+	routes       map[string]string
+	consumers    map[string]string
+	certificates map[string]string
+}
+
+func (ids *ids) lookup(kind Kind, names map[string]string, name string) (string, error) {
+	if id, ok := names[name]; ok {
+		return id, nil
+	}
+	return "", fmt.Errorf("could not find the id of %s %s", kind, name)
+}
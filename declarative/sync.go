@@ -0,0 +1,616 @@
+package declarative
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kevholditch/gokong"
+)
+
+type Options struct {
+	// DryRun computes and returns the plan without making any changes to kong
+	DryRun bool
+}
+
+// Sync reads the current state of kong, computes the changes needed to reach the desired state and applies them
+// in dependency order.  Entities in kong that are not in the desired state are deleted.  When options.DryRun is set
+// the plan is returned without being applied.  If applying a change fails the plan is returned along with the error,
+// changes before the failing one have been applied.
+func Sync(ctx context.Context, client *gokong.KongAdminClient, desired *gokong.State, options *Options) (*Plan, error) {
+	state, known, err := readCurrent(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("could not read current state from kong, error: %v", err)
+	}
+
+	plan, err := newPlanner(client, state, known).plan(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	if options != nil && options.DryRun {
+		return plan, nil
+	}
+
+	for _, change := range plan.Changes {
+		if err := change.apply(ctx, known); err != nil {
+			return plan, fmt.Errorf("could not %s, error: %v", change, err)
+		}
+	}
+
+	return plan, nil
+}
+
+type planner struct {
+	client  *gokong.KongAdminClient
+	current *current
+	known   *ids
+	result  *Plan
+
+	desiredServices     map[string]bool
+	desiredRoutes       map[string]bool
+	desiredConsumers    map[string]bool
+	desiredUpstreams    map[string]bool
+	desiredTargets      map[string]map[string]bool
+	desiredCertificates map[string]bool
+	desiredPlugins      map[pluginKey]bool
+}
+
+func newPlanner(client *gokong.KongAdminClient, current *current, known *ids) *planner {
+	return &planner{
+		client:              client,
+		current:             current,
+		known:               known,
+		result:              &Plan{Changes: []*Change{}},
+		desiredServices:     map[string]bool{},
+		desiredRoutes:       map[string]bool{},
+		desiredConsumers:    map[string]bool{},
+		desiredUpstreams:    map[string]bool{},
+		desiredTargets:      map[string]map[string]bool{},
+		desiredCertificates: map[string]bool{},
+		desiredPlugins:      map[pluginKey]bool{},
+	}
+}
+
+func (p *planner) plan(desired *gokong.State) (*Plan, error) {
+	steps := []func(*gokong.State) error{
+		p.planCertificates,
+		p.planUpstreams,
+		p.planServices,
+		p.planConsumers,
+		p.planPlugins,
+	}
+
+	for _, step := range steps {
+		if err := step(desired); err != nil {
+			return nil, err
+		}
+	}
+
+	p.planDeletes()
+
+	return p.result, nil
+}
+
+func (p *planner) planCertificates(desired *gokong.State) error {
+	for _, certificateState := range desired.Certificates {
+		request := certificateState.CertificateRequest
+		key := certificateKey(request.Cert)
+		if key == "" {
+			return fmt.Errorf("certificate is missing cert")
+		}
+		p.desiredCertificates[key] = true
+		name := certificateName(key, request.SNIs)
+
+		existing, ok := p.current.certificates[key]
+		if !ok {
+			p.result.add(ActionCreate, KindCertificate, name, func(ctx context.Context, ids *ids) error {
+				created, err := p.client.Certificates().CreateWithContext(ctx, &request)
+				if err != nil {
+					return err
+				}
+				ids.certificates[key] = *created.Id
+				return nil
+			})
+			continue
+		}
+
+		changed, err := differs(gokong.CertificateRequest{Cert: request.Cert, Key: request.Key}, existing)
+		if err != nil {
+			return err
+		}
+
+		if request.SNIs == nil {
+			snis := p.current.snis[key]
+			request.SNIs = &snis
+		} else if !sameStrings(*request.SNIs, p.current.snis[key]) {
+			changed = true
+		}
+
+		if changed {
+			id := *existing.Id
+			p.result.add(ActionUpdate, KindCertificate, name, func(ctx context.Context, ids *ids) error {
+				_, err := p.client.Certificates().UpdateByIdWithContext(ctx, id, &request)
+				return err
+			})
+		}
+	}
+
+	return nil
+}
+
+func (p *planner) planUpstreams(desired *gokong.State) error {
+	for _, upstreamState := range desired.Upstreams {
+		request := upstreamState.UpstreamRequest
+		name := request.Name
+		if name == "" {
+			return fmt.Errorf("upstream is missing name")
+		}
+		p.desiredUpstreams[name] = true
+		p.desiredTargets[name] = map[string]bool{}
+
+		existing, ok := p.current.upstreams[name]
+		if !ok {
+			p.result.add(ActionCreate, KindUpstream, name, func(ctx context.Context, ids *ids) error {
+				_, err := p.client.Upstreams().CreateWithContext(ctx, &request)
+				return err
+			})
+		} else {
+			changed, err := differs(request, existing)
+			if err != nil {
+				return err
+			}
+			if changed {
+				id := existing.Id
+				p.result.add(ActionUpdate, KindUpstream, name, func(ctx context.Context, ids *ids) error {
+					_, err := p.client.Upstreams().UpdateByIdWithContext(ctx, id, &request)
+					return err
+				})
+			}
+		}
+
+		for _, target := range upstreamState.Targets {
+			targetRequest := *target
+			p.desiredTargets[name][targetRequest.Target] = true
+			targetName := name + "/" + targetRequest.Target
+
+			existingTarget, ok := p.current.targets[name][targetRequest.Target]
+			if !ok {
+				p.result.add(ActionCreate, KindTarget, targetName, func(ctx context.Context, ids *ids) error {
+					_, err := p.client.Targets().CreateFromUpstreamNameWithContext(ctx, name, &targetRequest)
+					return err
+				})
+				continue
+			}
+
+			changed, err := differs(targetRequest, existingTarget)
+			if err != nil {
+				return err
+			}
+
+			if changed {
+				id := *existingTarget.Id
+				p.result.add(ActionUpdate, KindTarget, targetName, func(ctx context.Context, ids *ids) error {
+					if err := p.client.Targets().DeleteFromUpstreamByIdWithContext(ctx, name, id); err != nil {
+						return err
+					}
+					_, err := p.client.Targets().CreateFromUpstreamNameWithContext(ctx, name, &targetRequest)
+					return err
+				})
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *planner) planServices(desired *gokong.State) error {
+	for _, serviceState := range desired.Services {
+		request := serviceState.ServiceRequest
+		if request.Name == nil || *request.Name == "" {
+			return fmt.Errorf("service is missing name")
+		}
+		name := *request.Name
+		p.desiredServices[name] = true
+
+		existing, ok := p.current.services[name]
+		if !ok {
+			p.result.add(ActionCreate, KindService, name, func(ctx context.Context, ids *ids) error {
+				created, err := p.client.Services().CreateWithContext(ctx, &request)
+				if err != nil {
+					return err
+				}
+				ids.services[name] = *created.Id
+				return nil
+			})
+		} else {
+			comparable, err := expandUrl(request)
+			if err != nil {
+				return err
+			}
+			changed, err := differs(comparable, existing)
+			if err != nil {
+				return err
+			}
+			if changed {
+				id := *existing.Id
+				p.result.add(ActionUpdate, KindService, name, func(ctx context.Context, ids *ids) error {
+					_, err := p.client.Services().UpdateServiceByIdWithContext(ctx, id, &request)
+					return err
+				})
+			}
+		}
+
+		for _, routeState := range serviceState.Routes {
+			if err := p.planRoute(name, routeState); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, routeState := range desired.Routes {
+		if err := p.planRoute("", routeState); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// planRoute plans the route under the named service, or without a service when serviceName is empty
+func (p *planner) planRoute(serviceName string, routeState *gokong.RouteState) error {
+	request := routeState.RouteRequest
+	if request.Name == nil || *request.Name == "" {
+		if serviceName == "" {
+			return fmt.Errorf("route is missing name")
+		}
+		return fmt.Errorf("route on service %s is missing name", serviceName)
+	}
+	name := *request.Name
+	p.desiredRoutes[name] = true
+
+	resolve := func(ids *ids) error {
+		request.Service = nil
+		if serviceName == "" {
+			request.SetNull("service")
+			return nil
+		}
+		serviceId, err := ids.lookup(KindService, ids.services, serviceName)
+		if err != nil {
+			return err
+		}
+		request.Service = gokong.ToId(serviceId)
+		return nil
+	}
+
+	existing, ok := p.current.routes[name]
+	if !ok {
+		p.result.add(ActionCreate, KindRoute, name, func(ctx context.Context, ids *ids) error {
+			if err := resolve(ids); err != nil {
+				return err
+			}
+			created, err := p.client.Routes().CreateWithContext(ctx, &request)
+			if err != nil {
+				return err
+			}
+			ids.routes[name] = *created.Id
+			return nil
+		})
+		return nil
+	}
+
+	request.Service = nil
+	changed, err := differs(request, existing)
+	if err != nil {
+		return err
+	}
+	if serviceName == "" {
+		changed = changed || existing.Service != nil
+	} else if serviceId, ok := p.known.services[serviceName]; !ok || serviceId != gokong.IdToString(existing.Service) {
+		changed = true
+	}
+
+	if changed {
+		id := *existing.Id
+		p.result.add(ActionUpdate, KindRoute, name, func(ctx context.Context, ids *ids) error {
+			if err := resolve(ids); err != nil {
+				return err
+			}
+			_, err := p.client.Routes().UpdateByIdWithContext(ctx, id, &request)
+			return err
+		})
+	}
+
+	return nil
+}
+
+func (p *planner) planConsumers(desired *gokong.State) error {
+	for _, consumerState := range desired.Consumers {
+		request := consumerState.ConsumerRequest
+		name := consumerName(request.Username, request.CustomId)
+		if name == "" {
+			return fmt.Errorf("consumer is missing username and custom_id")
+		}
+		p.desiredConsumers[name] = true
+
+		existing, ok := p.current.consumers[name]
+		if !ok {
+			p.result.add(ActionCreate, KindConsumer, name, func(ctx context.Context, ids *ids) error {
+				created, err := p.client.Consumers().CreateWithContext(ctx, &request)
+				if err != nil {
+					return err
+				}
+				ids.consumers[name] = created.Id
+				return nil
+			})
+			continue
+		}
+
+		changed, err := differs(request, existing)
+		if err != nil {
+			return err
+		}
+		if changed {
+			id := existing.Id
+			p.result.add(ActionUpdate, KindConsumer, name, func(ctx context.Context, ids *ids) error {
+				_, err := p.client.Consumers().UpdateByIdWithContext(ctx, id, &request)
+				return err
+			})
+		}
+	}
+
+	return nil
+}
+
+func (p *planner) planPlugins(desired *gokong.State) error {
+	for _, serviceState := range desired.Services {
+		for _, pluginState := range serviceState.Plugins {
+			if err := p.planPlugin(pluginKey{name: pluginState.Name, service: *serviceState.Name}, pluginState); err != nil {
+				return err
+			}
+		}
+		for _, routeState := range serviceState.Routes {
+			for _, pluginState := range routeState.Plugins {
+				if err := p.planPlugin(pluginKey{name: pluginState.Name, route: *routeState.Name}, pluginState); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for _, routeState := range desired.Routes {
+		for _, pluginState := range routeState.Plugins {
+			if err := p.planPlugin(pluginKey{name: pluginState.Name, route: *routeState.Name}, pluginState); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, consumerState := range desired.Consumers {
+		for _, pluginState := range consumerState.Plugins {
+			key := pluginKey{name: pluginState.Name, consumer: consumerName(consumerState.Username, consumerState.CustomId)}
+			if err := p.planPlugin(key, pluginState); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, pluginState := range desired.Plugins {
+		key := pluginKey{name: pluginState.Name, service: pluginState.ServiceName, route: pluginState.RouteName, consumer: pluginState.ConsumerName}
+		if err := p.planPlugin(key, pluginState); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *planner) planPlugin(key pluginKey, pluginState *gokong.PluginState) error {
+	if key.name == "" {
+		return fmt.Errorf("plugin is missing name")
+	}
+	if p.desiredPlugins[key] {
+		return fmt.Errorf("plugin %s is configured more than once", key)
+	}
+	p.desiredPlugins[key] = true
+
+	request := pluginState.PluginRequest
+	request.ServiceId, request.RouteId, request.ConsumerId = nil, nil, nil
+
+	resolve := func(ids *ids) error {
+		if key.service != "" {
+			id, err := ids.lookup(KindService, ids.services, key.service)
+			if err != nil {
+				return err
+			}
+			request.ServiceId = gokong.ToId(id)
+		}
+		if key.route != "" {
+			id, err := ids.lookup(KindRoute, ids.routes, key.route)
+			if err != nil {
+				return err
+			}
+			request.RouteId = gokong.ToId(id)
+		}
+		if key.consumer != "" {
+			id, err := ids.lookup(KindConsumer, ids.consumers, key.consumer)
+			if err != nil {
+				return err
+			}
+			request.ConsumerId = gokong.ToId(id)
+		}
+		return nil
+	}
+
+	existing, ok := p.current.plugins[key]
+	if !ok {
+		p.result.add(ActionCreate, KindPlugin, key.String(), func(ctx context.Context, ids *ids) error {
+			if err := resolve(ids); err != nil {
+				return err
+			}
+			_, err := p.client.Plugins().CreateWithContext(ctx, &request)
+			return err
+		})
+		return nil
+	}
+
+	changed, err := differs(request, map[string]interface{}{
+		"name":    existing.Name,
+		"run_on":  existing.RunOn,
+		"config":  existing.Config,
+		"enabled": existing.Enabled,
+	})
+	if err != nil {
+		return err
+	}
+
+	if changed {
+		id := existing.Id
+		p.result.add(ActionUpdate, KindPlugin, key.String(), func(ctx context.Context, ids *ids) error {
+			if err := resolve(ids); err != nil {
+				return err
+			}
+			_, err := p.client.Plugins().UpdateByIdWithContext(ctx, id, &request)
+			return err
+		})
+	}
+
+	return nil
+}
+
+func (p *planner) planDeletes() {
+	for _, key := range sortedPluginKeys(p.current.plugins) {
+		if !p.desiredPlugins[key] {
+			id := p.current.plugins[key].Id
+			p.result.add(ActionDelete, KindPlugin, key.String(), func(ctx context.Context, ids *ids) error {
+				return p.client.Plugins().DeleteByIdWithContext(ctx, id)
+			})
+		}
+	}
+
+	for _, name := range sortedKeys(p.current.consumers) {
+		if !p.desiredConsumers[name] {
+			id := p.current.consumers[name].Id
+			p.result.add(ActionDelete, KindConsumer, name, func(ctx context.Context, ids *ids) error {
+				return p.client.Consumers().DeleteByIdWithContext(ctx, id)
+			})
+		}
+	}
+
+	for _, name := range sortedKeys(p.current.routes) {
+		if !p.desiredRoutes[name] {
+			id := *p.current.routes[name].Id
+			p.result.add(ActionDelete, KindRoute, name, func(ctx context.Context, ids *ids) error {
+				return p.client.Routes().DeleteByIdWithContext(ctx, id)
+			})
+		}
+	}
+
+	for _, name := range sortedKeys(p.current.services) {
+		if !p.desiredServices[name] {
+			id := *p.current.services[name].Id
+			p.result.add(ActionDelete, KindService, name, func(ctx context.Context, ids *ids) error {
+				return p.client.Services().DeleteServiceByIdWithContext(ctx, id)
+			})
+		}
+	}
+
+	for _, upstreamName := range sortedKeys(p.current.targets) {
+		if !p.desiredUpstreams[upstreamName] {
+			continue
+		}
+		for _, target := range sortedKeys(p.current.targets[upstreamName]) {
+			if !p.desiredTargets[upstreamName][target] {
+				upstream, id := upstreamName, *p.current.targets[upstreamName][target].Id
+				p.result.add(ActionDelete, KindTarget, upstream+"/"+target, func(ctx context.Context, ids *ids) error {
+					return p.client.Targets().DeleteFromUpstreamByIdWithContext(ctx, upstream, id)
+				})
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(p.current.upstreams) {
+		if !p.desiredUpstreams[name] {
+			id := p.current.upstreams[name].Id
+			p.result.add(ActionDelete, KindUpstream, name, func(ctx context.Context, ids *ids) error {
+				return p.client.Upstreams().DeleteByIdWithContext(ctx, id)
+			})
+		}
+	}
+
+	for _, key := range sortedKeys(p.current.certificates) {
+		if !p.desiredCertificates[key] {
+			snis := p.current.snis[key]
+			id := *p.current.certificates[key].Id
+			p.result.add(ActionDelete, KindCertificate, certificateName(key, &snis), func(ctx context.Context, ids *ids) error {
+				return p.client.Certificates().DeleteByIdWithContext(ctx, id)
+			})
+		}
+	}
+}
+
+// expandUrl replaces the url of a service request with the protocol, host, port and path kong stores it as so the
+// request can be compared to the service returned by kong
+func expandUrl(request gokong.ServiceRequest) (gokong.ServiceRequest, error) {
+	if request.Url == nil {
+		return request, nil
+	}
+
+	u, err := url.Parse(*request.Url)
+	if err != nil {
+		return request, fmt.Errorf("could not parse url of service %s, error: %v", *request.Name, err)
+	}
+
+	request.Url = nil
+	request.Protocol = gokong.String(u.Scheme)
+	request.Host = gokong.String(u.Hostname())
+	if u.Port() != "" {
+		port, err := strconv.Atoi(u.Port())
+		if err != nil {
+			return request, fmt.Errorf("could not parse port of service %s, error: %v", *request.Name, err)
+		}
+		request.Port = gokong.Int(port)
+	}
+	if u.Path != "" {
+		request.Path = gokong.String(u.Path)
+	}
+
+	return request, nil
+}
+
+func consumerName(username string, customId string) string {
+	if username != "" {
+		return username
+	}
+	return customId
+}
+
+func certificateName(key string, snis *[]string) string {
+	if snis != nil && len(*snis) > 0 {
+		return strings.Join(*snis, ",")
+	}
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(key)))[:12]
+}
+
+func sameStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	sortedA := append([]string{}, a...)
+	sortedB := append([]string{}, b...)
+	sort.Strings(sortedA)
+	sort.Strings(sortedB)
+
+	for i := range sortedA {
+		if sortedA[i] != sortedB[i] {
+			return false
+		}
+	}
+
+	return true
+}
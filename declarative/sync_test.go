@@ -0,0 +1,223 @@
+package declarative
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kevholditch/gokong"
+	"github.com/kevholditch/gokong/kongtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestPlanner(state *current) *planner {
+	known := &ids{
+		services:     map[string]string{},
+		routes:       map[string]string{},
+		consumers:    map[string]string{},
+		certificates: map[string]string{},
+	}
+	for name, service := range state.services {
+		known.services[name] = *service.Id
+	}
+	return newPlanner(gokong.NewClient(gokong.NewDefaultConfig()), state, known)
+}
+
+func emptyCurrent() *current {
+	return &current{
+		services:     map[string]*gokong.Service{},
+		routes:       map[string]*gokong.Route{},
+		consumers:    map[string]*gokong.Consumer{},
+		upstreams:    map[string]*gokong.Upstream{},
+		targets:      map[string]map[string]*gokong.Target{},
+		certificates: map[string]*gokong.Certificate{},
+		snis:         map[string][]string{},
+		plugins:      map[pluginKey]*gokong.Plugin{},
+	}
+}
+
+func changesOf(plan *Plan) []string {
+	result := []string{}
+	for _, change := range plan.Changes {
+		result = append(result, change.String())
+	}
+	return result
+}
+
+func Test_PlanCreatesEntitiesInDependencyOrder(t *testing.T) {
+	desired := &gokong.State{
+		Plugins: []*gokong.PluginState{{PluginRequest: gokong.PluginRequest{Name: "cors"}}},
+		Consumers: []*gokong.ConsumerState{{
+			ConsumerRequest: gokong.ConsumerRequest{Username: "bob"},
+			Plugins:         []*gokong.PluginState{{PluginRequest: gokong.PluginRequest{Name: "key-auth"}}},
+		}},
+		Services: []*gokong.ServiceState{{
+			ServiceRequest: gokong.ServiceRequest{Name: gokong.String("orders"), Url: gokong.String("http://orders:8080")},
+			Routes:         []*gokong.RouteState{{RouteRequest: gokong.RouteRequest{Name: gokong.String("orders-route"), Paths: gokong.StringSlice([]string{"/orders"})}}},
+		}},
+		Upstreams: []*gokong.UpstreamState{{
+			UpstreamRequest: gokong.UpstreamRequest{Name: "orders"},
+			Targets:         []*gokong.TargetRequest{{Target: "10.0.0.1:8080", Weight: 100}},
+		}},
+		Certificates: []*gokong.CertificateState{{
+			CertificateRequest: gokong.CertificateRequest{Cert: gokong.String("cert"), Key: gokong.String("key"), SNIs: &[]string{"example.com"}},
+		}},
+	}
+
+	plan, err := newTestPlanner(emptyCurrent()).plan(desired)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"create certificate example.com",
+		"create upstream orders",
+		"create target orders/10.0.0.1:8080",
+		"create service orders",
+		"create route orders-route",
+		"create consumer bob",
+		"create plugin key-auth (consumer=bob)",
+		"create plugin cors",
+	}, changesOf(plan))
+}
+
+func Test_PlanUpdatesChangedEntitiesAndDeletesUnknownOnes(t *testing.T) {
+	state := emptyCurrent()
+	state.services["orders"] = &gokong.Service{Id: gokong.String("1"), Name: gokong.String("orders"), Protocol: gokong.String("http"), Host: gokong.String("orders"), Port: gokong.Int(8080), Retries: gokong.Int(5)}
+	state.services["old"] = &gokong.Service{Id: gokong.String("2"), Name: gokong.String("old")}
+	state.routes["orders-route"] = &gokong.Route{Id: gokong.String("3"), Name: gokong.String("orders-route"), Paths: gokong.StringSlice([]string{"/orders"}), Service: gokong.ToId("1")}
+	state.routes["old-route"] = &gokong.Route{Id: gokong.String("4"), Name: gokong.String("old-route"), Service: gokong.ToId("2")}
+	state.plugins[pluginKey{name: "cors", route: "old-route"}] = &gokong.Plugin{Id: "5", Name: "cors"}
+
+	desired := &gokong.State{
+		Services: []*gokong.ServiceState{{
+			ServiceRequest: gokong.ServiceRequest{Name: gokong.String("orders"), Url: gokong.String("http://orders:8080"), Retries: gokong.Int(3)},
+			Routes:         []*gokong.RouteState{{RouteRequest: gokong.RouteRequest{Name: gokong.String("orders-route"), Paths: gokong.StringSlice([]string{"/orders"})}}},
+		}},
+	}
+
+	plan, err := newTestPlanner(state).plan(desired)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"update service orders",
+		"delete plugin cors (route=old-route)",
+		"delete route old-route",
+		"delete service old",
+	}, changesOf(plan))
+}
+
+func Test_PlanIsEmptyWhenInDesiredState(t *testing.T) {
+	state := emptyCurrent()
+	state.consumers["bob"] = &gokong.Consumer{Id: "1", Username: "bob", CustomId: "123"}
+	state.plugins[pluginKey{name: "rate-limiting", consumer: "bob"}] = &gokong.Plugin{Id: "2", Name: "rate-limiting", Enabled: true, Config: map[string]interface{}{"minute": 20, "policy": "local"}}
+
+	desired := &gokong.State{
+		Consumers: []*gokong.ConsumerState{{
+			ConsumerRequest: gokong.ConsumerRequest{Username: "bob"},
+			Plugins: []*gokong.PluginState{{PluginRequest: gokong.PluginRequest{
+				Name:   "rate-limiting",
+				Config: map[string]interface{}{"minute": 20},
+			}}},
+		}},
+	}
+
+	plan, err := newTestPlanner(state).plan(desired)
+
+	assert.Nil(t, err)
+	assert.True(t, plan.Empty())
+}
+
+func Test_PlanRejectsUnnamedServices(t *testing.T) {
+	desired := &gokong.State{
+		Services: []*gokong.ServiceState{{ServiceRequest: gokong.ServiceRequest{Host: gokong.String("foo.com")}}},
+	}
+
+	plan, err := newTestPlanner(emptyCurrent()).plan(desired)
+
+	assert.Nil(t, plan)
+	assert.NotNil(t, err)
+}
+
+func Test_DiffersIgnoresFieldsNotSetInDesired(t *testing.T) {
+	changed, err := differs(gokong.ServiceRequest{Host: gokong.String("foo.com")}, &gokong.Service{Host: gokong.String("foo.com"), Port: gokong.Int(80)})
+	assert.Nil(t, err)
+	assert.False(t, changed)
+
+	changed, err = differs(gokong.ServiceRequest{Host: gokong.String("bar.com")}, &gokong.Service{Host: gokong.String("foo.com")})
+	assert.Nil(t, err)
+	assert.True(t, changed)
+}
+
+func Test_SyncReachesTheDesiredState(t *testing.T) {
+	server := kongtest.NewServer()
+	defer server.Close()
+	client := gokong.NewClient(&gokong.Config{HostAddress: server.URL})
+
+	desired := &gokong.State{
+		Services: []*gokong.ServiceState{{
+			ServiceRequest: gokong.ServiceRequest{Name: gokong.String("orders"), Url: gokong.String("http://orders:8080")},
+			Routes:         []*gokong.RouteState{{RouteRequest: gokong.RouteRequest{Name: gokong.String("orders-route"), Paths: gokong.StringSlice([]string{"/orders"})}}},
+			Plugins:        []*gokong.PluginState{{PluginRequest: gokong.PluginRequest{Name: "cors"}}},
+		}},
+		Consumers: []*gokong.ConsumerState{{ConsumerRequest: gokong.ConsumerRequest{Username: "bob"}}},
+		Upstreams: []*gokong.UpstreamState{{
+			UpstreamRequest: gokong.UpstreamRequest{Name: "orders"},
+			Targets:         []*gokong.TargetRequest{{Target: "10.0.0.1:8080", Weight: 100}},
+		}},
+	}
+
+	plan, err := Sync(context.Background(), client, desired, &Options{})
+	assert.Nil(t, err)
+	assert.Equal(t, 6, len(plan.Changes))
+
+	plan, err = Sync(context.Background(), client, desired, &Options{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{}, changesOf(plan))
+
+	plan, err = Sync(context.Background(), client, &gokong.State{}, &Options{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"delete plugin cors (service=orders)",
+		"delete consumer bob",
+		"delete route orders-route",
+		"delete service orders",
+		"delete upstream orders",
+	}, changesOf(plan))
+
+	dump, err := client.Dump()
+	assert.Nil(t, err)
+	assert.Equal(t, &gokong.State{}, dump)
+}
+
+func Test_SyncManagesRoutesWithoutAService(t *testing.T) {
+	server := kongtest.NewServer()
+	defer server.Close()
+	client := gokong.NewClient(&gokong.Config{HostAddress: server.URL})
+
+	desired := &gokong.State{
+		Services: []*gokong.ServiceState{{
+			ServiceRequest: gokong.ServiceRequest{Name: gokong.String("orders"), Url: gokong.String("http://orders:8080")},
+			Routes:         []*gokong.RouteState{{RouteRequest: gokong.RouteRequest{Name: gokong.String("orders-route"), Paths: gokong.StringSlice([]string{"/orders"})}}},
+		}},
+	}
+	_, err := Sync(context.Background(), client, desired, &Options{})
+	assert.Nil(t, err)
+
+	desired.Routes, desired.Services[0].Routes = desired.Services[0].Routes, nil
+	desired.Routes[0].Plugins = []*gokong.PluginState{{PluginRequest: gokong.PluginRequest{Name: "cors"}}}
+
+	plan, err := Sync(context.Background(), client, desired, &Options{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"update route orders-route", "create plugin cors (route=orders-route)"}, changesOf(plan))
+
+	route, err := client.Routes().GetByName("orders-route")
+	assert.Nil(t, err)
+	assert.Nil(t, route.Service)
+
+	dump, err := client.Dump()
+	assert.Nil(t, err)
+	assert.Len(t, dump.Routes, 1)
+	assert.Len(t, dump.Routes[0].Plugins, 1)
+
+	plan, err = Sync(context.Background(), client, dump, &Options{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{}, changesOf(plan))
+}
@@ -0,0 +1,309 @@
+package gokong
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+type ExportFormat string
+
+const (
+	ExportFormatYAML ExportFormat = "yaml"
+	ExportFormatJSON ExportFormat = "json"
+)
+
+func (kongAdminClient *KongAdminClient) Dump() (*State, error) {
+	return kongAdminClient.DumpWithContext(context.Background())
+}
+
+// DumpWithContext reads every entity from kong and returns them as a State with references between entities
+// resolved by nesting (or by name for plugins that apply to more than one entity).  Entities are sorted so the
+// same configuration always produces the same State.
+func (kongAdminClient *KongAdminClient) DumpWithContext(ctx context.Context) (*State, error) {
+	state := &State{}
+
+	services, err := kongAdminClient.Services().GetServicesWithContext(ctx, &ServiceQueryString{})
+	if err != nil {
+		return nil, fmt.Errorf("could not dump services, error: %v", err)
+	}
+
+	routes, err := kongAdminClient.Routes().ListWithContext(ctx, &RouteQueryString{})
+	if err != nil {
+		return nil, fmt.Errorf("could not dump routes, error: %v", err)
+	}
+
+	consumers, err := kongAdminClient.Consumers().ListWithContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not dump consumers, error: %v", err)
+	}
+
+	plugins, err := kongAdminClient.Plugins().ListWithContext(ctx, &PluginQueryString{})
+	if err != nil {
+		return nil, fmt.Errorf("could not dump plugins, error: %v", err)
+	}
+
+	upstreams, err := kongAdminClient.Upstreams().ListWithContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not dump upstreams, error: %v", err)
+	}
+
+	certificates, err := kongAdminClient.Certificates().ListWithContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not dump certificates, error: %v", err)
+	}
+
+	snis, err := kongAdminClient.Snis().ListWithContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not dump snis, error: %v", err)
+	}
+
+	caCertificates, err := kongAdminClient.CACertificates().GetCACertificatesWithContext(ctx, &CACertificateQueryString{})
+	if err != nil {
+		return nil, fmt.Errorf("could not dump ca certificates, error: %v", err)
+	}
+
+	serviceStates := map[string]*ServiceState{}
+	serviceNames := map[string]string{}
+	for _, service := range services {
+		serviceState := &ServiceState{ServiceRequest: ServiceRequest{
+			Name:              service.Name,
+			Protocol:          service.Protocol,
+			Host:              service.Host,
+			Port:              service.Port,
+			Path:              service.Path,
+			Retries:           service.Retries,
+			ConnectTimeout:    service.ConnectTimeout,
+			WriteTimeout:      service.WriteTimeout,
+			ReadTimeout:       service.ReadTimeout,
+			ClientCertificate: service.ClientCertificate,
+			TlsVerify:         service.TlsVerify,
+			TlsVerifyDepth:    service.TlsVerifyDepth,
+			CaCertificates:    service.CaCertificates,
+			Enabled:           service.Enabled,
+			Tags:              service.Tags,
+		}, Id: *service.Id}
+		serviceStates[*service.Id] = serviceState
+		serviceNames[*service.Id] = dumpName(service.Name, *service.Id)
+		state.Services = append(state.Services, serviceState)
+	}
+
+	routeStates := map[string]*RouteState{}
+	routeNames := map[string]string{}
+	for _, route := range routes {
+		routeState := &RouteState{RouteRequest: RouteRequest{
+			Name:                    route.Name,
+			Protocols:               route.Protocols,
+			Methods:                 route.Methods,
+			Hosts:                   route.Hosts,
+			Paths:                   route.Paths,
+			Headers:                 route.Headers,
+			HttpsRedirectStatusCode: route.HttpsRedirectStatusCode,
+			RegexPriority:           route.RegexPriority,
+			StripPath:               route.StripPath,
+			PathHandling:            route.PathHandling,
+			PreserveHost:            route.PreserveHost,
+			RequestBuffering:        route.RequestBuffering,
+			ResponseBuffering:       route.ResponseBuffering,
+			Snis:                    route.Snis,
+			Sources:                 route.Sources,
+			Destinations:            route.Destinations,
+			Tags:                    route.Tags,
+		}, Id: *route.Id}
+		routeStates[*route.Id] = routeState
+		routeNames[*route.Id] = dumpName(route.Name, *route.Id)
+
+		if serviceState, ok := serviceStates[IdToString(route.Service)]; ok {
+			serviceState.Routes = append(serviceState.Routes, routeState)
+		} else {
+			state.Routes = append(state.Routes, routeState)
+		}
+	}
+
+	consumerStates := map[string]*ConsumerState{}
+	consumerNames := map[string]string{}
+	for _, consumer := range consumers.Results {
+		consumerState := &ConsumerState{ConsumerRequest: ConsumerRequest{
+			Username: consumer.Username,
+			CustomId: consumer.CustomId,
+			Tags:     consumer.Tags,
+		}, Id: consumer.Id}
+		consumerStates[consumer.Id] = consumerState
+		consumerNames[consumer.Id] = consumer.Username
+		if consumer.Username == "" {
+			consumerNames[consumer.Id] = consumer.CustomId
+		}
+		state.Consumers = append(state.Consumers, consumerState)
+	}
+
+	for _, plugin := range plugins {
+		serviceId, routeId, consumerId := IdToString(plugin.ServiceId), IdToString(plugin.RouteId), IdToString(plugin.ConsumerId)
+		pluginState := &PluginState{PluginRequest: PluginRequest{
+			Name:    plugin.Name,
+			RunOn:   plugin.RunOn,
+			Config:  plugin.Config,
+			Enabled: Bool(plugin.Enabled),
+			Tags:    plugin.Tags,
+		}, Id: plugin.Id}
+
+		serviceState, isServicePlugin := serviceStates[serviceId]
+		routeState, isRoutePlugin := routeStates[routeId]
+		consumerState, isConsumerPlugin := consumerStates[consumerId]
+
+		switch {
+		case isServicePlugin && routeId == "" && consumerId == "":
+			serviceState.Plugins = append(serviceState.Plugins, pluginState)
+		case isRoutePlugin && serviceId == "" && consumerId == "":
+			routeState.Plugins = append(routeState.Plugins, pluginState)
+		case isConsumerPlugin && serviceId == "" && routeId == "":
+			consumerState.Plugins = append(consumerState.Plugins, pluginState)
+		default:
+			pluginState.ServiceName = serviceNames[serviceId]
+			pluginState.RouteName = routeNames[routeId]
+			pluginState.ConsumerName = consumerNames[consumerId]
+			state.Plugins = append(state.Plugins, pluginState)
+		}
+	}
+
+	for _, upstream := range upstreams.Results {
+		targets, err := kongAdminClient.Targets().GetTargetsFromUpstreamIdWithContext(ctx, upstream.Id)
+		if err != nil {
+			return nil, fmt.Errorf("could not dump targets of upstream %s, error: %v", upstream.Name, err)
+		}
+
+		upstreamState := &UpstreamState{UpstreamRequest: upstream.UpstreamRequest, Id: upstream.Id}
+		for _, target := range targets {
+			upstreamState.Targets = append(upstreamState.Targets, &TargetRequest{Target: *target.Target, Weight: *target.Weight, Tags: target.Tags})
+		}
+		sort.Slice(upstreamState.Targets, func(i, j int) bool {
+			return upstreamState.Targets[i].Target < upstreamState.Targets[j].Target
+		})
+		state.Upstreams = append(state.Upstreams, upstreamState)
+	}
+
+	certificateSnis := map[string][]string{}
+	for _, sni := range snis.Results {
+		certificateSnis[IdToString(sni.CertificateId)] = append(certificateSnis[IdToString(sni.CertificateId)], sni.Name)
+	}
+	for _, certificate := range certificates.Results {
+		certificateState := &CertificateState{CertificateRequest: CertificateRequest{Cert: certificate.Cert, Key: certificate.Key, Tags: certificate.Tags}, Id: *certificate.Id}
+		if names, ok := certificateSnis[*certificate.Id]; ok {
+			sort.Strings(names)
+			certificateState.SNIs = &names
+		}
+		state.Certificates = append(state.Certificates, certificateState)
+	}
+
+	for _, caCertificate := range caCertificates {
+		state.CACertificates = append(state.CACertificates, &CACertificateState{CACertificateRequest: CACertificateRequest{Cert: caCertificate.Cert, Tags: caCertificate.Tags}, Id: *caCertificate.Id})
+	}
+
+	state.sort()
+
+	return state, nil
+}
+
+func (kongAdminClient *KongAdminClient) Export(w io.Writer, format ExportFormat) error {
+	return kongAdminClient.ExportWithContext(context.Background(), w, format)
+}
+
+// ExportWithContext dumps every entity from kong and writes them to w as yaml or json
+func (kongAdminClient *KongAdminClient) ExportWithContext(ctx context.Context, w io.Writer, format ExportFormat) error {
+	state, err := kongAdminClient.DumpWithContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	return state.Write(w, format)
+}
+
+// Write writes the state to w as yaml or json
+func (state *State) Write(w io.Writer, format ExportFormat) error {
+	var body []byte
+	var err error
+
+	switch format {
+	case ExportFormatYAML:
+		body, err = yaml.Marshal(state)
+	case ExportFormatJSON:
+		body, err = json.MarshalIndent(state, "", "  ")
+	default:
+		return fmt.Errorf("unknown export format: %s", format)
+	}
+
+	if err != nil {
+		return fmt.Errorf("could not marshal state, error: %v", err)
+	}
+
+	_, err = w.Write(body)
+	return err
+}
+
+func (state *State) sort() {
+	sort.SliceStable(state.Services, func(i, j int) bool {
+		return stringValue(state.Services[i].Name) < stringValue(state.Services[j].Name)
+	})
+	for _, serviceState := range state.Services {
+		sort.SliceStable(serviceState.Routes, func(i, j int) bool {
+			return stringValue(serviceState.Routes[i].Name) < stringValue(serviceState.Routes[j].Name)
+		})
+		sortPlugins(serviceState.Plugins)
+		for _, routeState := range serviceState.Routes {
+			sortPlugins(routeState.Plugins)
+		}
+	}
+
+	sort.SliceStable(state.Routes, func(i, j int) bool {
+		return stringValue(state.Routes[i].Name) < stringValue(state.Routes[j].Name)
+	})
+	for _, routeState := range state.Routes {
+		sortPlugins(routeState.Plugins)
+	}
+
+	sort.SliceStable(state.Consumers, func(i, j int) bool {
+		return state.Consumers[i].Username+"/"+state.Consumers[i].CustomId < state.Consumers[j].Username+"/"+state.Consumers[j].CustomId
+	})
+	for _, consumerState := range state.Consumers {
+		sortPlugins(consumerState.Plugins)
+	}
+
+	sort.SliceStable(state.Upstreams, func(i, j int) bool {
+		return state.Upstreams[i].Name < state.Upstreams[j].Name
+	})
+
+	sort.SliceStable(state.Certificates, func(i, j int) bool {
+		return stringValue(state.Certificates[i].Cert) < stringValue(state.Certificates[j].Cert)
+	})
+
+	sort.SliceStable(state.CACertificates, func(i, j int) bool {
+		return stringValue(state.CACertificates[i].Cert) < stringValue(state.CACertificates[j].Cert)
+	})
+
+	sortPlugins(state.Plugins)
+}
+
+func sortPlugins(plugins []*PluginState) {
+	sort.SliceStable(plugins, func(i, j int) bool {
+		a := plugins[i].Name + "/" + plugins[i].ServiceName + "/" + plugins[i].RouteName + "/" + plugins[i].ConsumerName
+		b := plugins[j].Name + "/" + plugins[j].ServiceName + "/" + plugins[j].RouteName + "/" + plugins[j].ConsumerName
+		return a < b
+	})
+}
+
+func dumpName(name *string, id string) string {
+	if name != nil && *name != "" {
+		return *name
+	}
+	return id
+}
+
+func stringValue(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
@@ -0,0 +1,180 @@
+package gokong
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func findServiceState(state *State, name string) *ServiceState {
+	for _, serviceState := range state.Services {
+		if serviceState.Name != nil && *serviceState.Name == name {
+			return serviceState
+		}
+	}
+	return nil
+}
+
+func Test_DumpNestsRoutesAndPluginsUnderTheirService(t *testing.T) {
+	client := NewClient(NewDefaultConfig())
+
+	createdService, err := client.Services().Create(&ServiceRequest{
+		Name:     String(fmt.Sprintf("service-name-%s", uuid.NewV4().String())),
+		Protocol: String("http"),
+		Host:     String("foo.com"),
+	})
+	assert.Nil(t, err)
+
+	createdRoute, err := client.Routes().Create(&RouteRequest{
+		Name:      String(fmt.Sprintf("route-name-%s", uuid.NewV4().String())),
+		Protocols: StringSlice([]string{"http"}),
+		Paths:     StringSlice([]string{"/dump"}),
+		Service:   ToId(*createdService.Id),
+	})
+	assert.Nil(t, err)
+
+	createdPlugin, err := client.Plugins().Create(&PluginRequest{
+		Name:      "response-ratelimiting",
+		ServiceId: ToId(*createdService.Id),
+		Config: map[string]interface{}{
+			"limits.sms.minute": 20,
+		},
+	})
+	assert.Nil(t, err)
+
+	state, err := client.Dump()
+	assert.Nil(t, err)
+
+	serviceState := findServiceState(state, *createdService.Name)
+	assert.NotNil(t, serviceState)
+	assert.Equal(t, createdService.Host, serviceState.Host)
+	assert.Len(t, serviceState.Routes, 1)
+	assert.Equal(t, createdRoute.Name, serviceState.Routes[0].Name)
+	assert.Len(t, serviceState.Plugins, 1)
+	assert.Equal(t, "response-ratelimiting", serviceState.Plugins[0].Name)
+	assert.Nil(t, serviceState.Plugins[0].ServiceId)
+
+	client.Plugins().DeleteById(createdPlugin.Id)
+	client.Routes().DeleteById(*createdRoute.Id)
+	client.Services().DeleteServiceById(*createdService.Id)
+}
+
+func Test_ExportWritesDeterministicYamlAndJson(t *testing.T) {
+	client := NewClient(NewDefaultConfig())
+
+	createdConsumer, err := client.Consumers().Create(&ConsumerRequest{Username: "username-" + uuid.NewV4().String()})
+	assert.Nil(t, err)
+
+	first := &bytes.Buffer{}
+	err = client.Export(first, ExportFormatYAML)
+	assert.Nil(t, err)
+
+	second := &bytes.Buffer{}
+	err = client.Export(second, ExportFormatYAML)
+	assert.Nil(t, err)
+	assert.Equal(t, first.String(), second.String())
+
+	fromYaml := &State{}
+	err = yaml.Unmarshal(first.Bytes(), fromYaml)
+	assert.Nil(t, err)
+	assert.Contains(t, fromYaml.Consumers, &ConsumerState{ConsumerRequest: ConsumerRequest{Username: createdConsumer.Username}, Id: createdConsumer.Id})
+
+	asJson := &bytes.Buffer{}
+	err = client.Export(asJson, ExportFormatJSON)
+	assert.Nil(t, err)
+
+	fromJson := &State{}
+	err = json.Unmarshal(asJson.Bytes(), fromJson)
+	assert.Nil(t, err)
+	assert.Equal(t, fromYaml.Consumers, fromJson.Consumers)
+
+	client.Consumers().DeleteById(createdConsumer.Id)
+}
+
+func Test_StateWriteRejectsUnknownFormat(t *testing.T) {
+	err := (&State{}).Write(&bytes.Buffer{}, ExportFormat("xml"))
+	assert.NotNil(t, err)
+}
+
+func Test_DumpKeepsRoutesWithoutAServiceAtTheTopLevel(t *testing.T) {
+	client := NewClient(NewDefaultConfig())
+
+	createdRoute, err := client.Routes().Create(&RouteRequest{
+		Name:      String(fmt.Sprintf("route-name-%s", uuid.NewV4().String())),
+		Protocols: StringSlice([]string{"http"}),
+		Paths:     StringSlice([]string{"/no-service"}),
+	})
+	assert.Nil(t, err)
+
+	createdPlugin, err := client.Plugins().Create(&PluginRequest{
+		Name:    "response-ratelimiting",
+		RouteId: ToId(*createdRoute.Id),
+		Config: map[string]interface{}{
+			"limits.sms.minute": 20,
+		},
+	})
+	assert.Nil(t, err)
+
+	state, err := client.Dump()
+	assert.Nil(t, err)
+
+	var routeState *RouteState
+	for _, candidate := range state.Routes {
+		if stringValue(candidate.Name) == *createdRoute.Name {
+			routeState = candidate
+		}
+	}
+	assert.NotNil(t, routeState)
+	assert.Nil(t, routeState.Service)
+	assert.Len(t, routeState.Plugins, 1)
+	assert.Equal(t, "response-ratelimiting", routeState.Plugins[0].Name)
+
+	client.Plugins().DeleteById(createdPlugin.Id)
+	client.Routes().DeleteById(*createdRoute.Id)
+}
+
+func Test_DumpKeepsTheCertificatesOfServices(t *testing.T) {
+	client := NewClient(NewDefaultConfig())
+
+	cert, key := newTestServerCertificate(t, time.Now().Add(24*time.Hour), "foo.com")
+	certificate, err := client.Certificates().Create(&CertificateRequest{Cert: String(cert), Key: String(key)})
+	assert.Nil(t, err)
+	caCertificate, err := client.CACertificates().Create(&CACertificateRequest{Cert: String(newTestCACertificate(t, true, time.Now().Add(24*time.Hour)))})
+	assert.Nil(t, err)
+
+	service, err := client.Services().Create(&ServiceRequest{
+		Name:              String("service-name-" + uuid.NewV4().String()),
+		Url:               String("https://foo.com"),
+		ClientCertificate: ToId(*certificate.Id),
+		TlsVerify:         Bool(true),
+		CaCertificates:    []*string{caCertificate.Id},
+	})
+	assert.Nil(t, err)
+
+	state, err := client.Dump()
+	assert.Nil(t, err)
+
+	serviceState := findServiceState(state, *service.Name)
+	assert.NotNil(t, serviceState)
+	assert.Equal(t, *certificate.Id, IdToString(serviceState.ClientCertificate))
+	assert.Equal(t, []*string{caCertificate.Id}, serviceState.CaCertificates)
+
+	var caCertificateState *CACertificateState
+	for _, candidate := range state.CACertificates {
+		if candidate.Id == *caCertificate.Id {
+			caCertificateState = candidate
+		}
+	}
+	assert.NotNil(t, caCertificateState)
+	assert.Equal(t, caCertificate.Cert, caCertificateState.Cert)
+
+	assert.Nil(t, client.Services().DeleteServiceById(*service.Id))
+	assert.Nil(t, client.CACertificates().DeleteById(*caCertificate.Id))
+	assert.Nil(t, client.Certificates().DeleteById(*certificate.Id))
+}
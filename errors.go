@@ -0,0 +1,86 @@
+package gokong
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// KongError is returned whenever the kong admin api responds with an error status code.  It carries the
+// http status along with the error body returned by kong so callers can inspect why a call failed.
+type KongError struct {
+	StatusCode int                    `json:"-" yaml:"-"`
+	Code       int                    `json:"code,omitempty" yaml:"code,omitempty"`
+	Name       string                 `json:"name,omitempty" yaml:"name,omitempty"`
+	Message    string                 `json:"message,omitempty" yaml:"message,omitempty"`
+	Fields     map[string]interface{} `json:"fields,omitempty" yaml:"fields,omitempty"`
+	Body       string                 `json:"-" yaml:"-"`
+}
+
+func (kongError *KongError) Error() string {
+	if kongError.StatusCode == http.StatusUnauthorized || kongError.StatusCode == http.StatusForbidden {
+		return fmt.Sprintf("not authorised, message from kong: %s", kongError.Body)
+	}
+
+	if kongError.Message != "" {
+		return fmt.Sprintf("kong responded with status %d, message from kong: %s", kongError.StatusCode, kongError.Message)
+	}
+
+	return fmt.Sprintf("kong responded with status %d, body: %s", kongError.StatusCode, kongError.Body)
+}
+
+// checkResponse returns a *KongError when the response from kong has an error status code and nil otherwise.
+func checkResponse(r *http.Response, body string) error {
+	if r.StatusCode < 400 {
+		return nil
+	}
+
+	kongError := &KongError{}
+	_ = json.Unmarshal([]byte(body), kongError)
+	kongError.StatusCode = r.StatusCode
+	kongError.Body = body
+
+	return kongError
+}
+
+func hasStatusCode(err error, statusCodes ...int) bool {
+	kongError, ok := err.(*KongError)
+	if !ok {
+		return false
+	}
+
+	for _, statusCode := range statusCodes {
+		if kongError.StatusCode == statusCode {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsNotFound returns true if err is a *KongError caused by kong responding 404
+func IsNotFound(err error) bool {
+	return hasStatusCode(err, http.StatusNotFound)
+}
+
+// IsConflict returns true if err is a *KongError caused by kong responding 409 (e.g. a unique constraint violation)
+func IsConflict(err error) bool {
+	return hasStatusCode(err, http.StatusConflict)
+}
+
+// IsUnauthorized returns true if err is a *KongError caused by kong responding 401 or 403
+func IsUnauthorized(err error) bool {
+	return hasStatusCode(err, http.StatusUnauthorized, http.StatusForbidden)
+}
+
+// IsBadRequest returns true if err is a *KongError caused by kong responding 400
+func IsBadRequest(err error) bool {
+	return hasStatusCode(err, http.StatusBadRequest)
+}
+
+// IsSchemaViolation returns true if err is a *KongError caused by the request failing kong's schema validation,
+// the offending fields can be found in KongError.Fields
+func IsSchemaViolation(err error) bool {
+	kongError, ok := err.(*KongError)
+	return ok && kongError.StatusCode == http.StatusBadRequest && kongError.Name == "schema violation"
+}
@@ -0,0 +1,140 @@
+package gokong
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// Fields is embedded in every request so each field of a request is in one of three states: unset fields are not
+// sent, so a PATCH leaves them as they are in kong, fields named in NullFields are sent as null, clearing them, and
+// every other field is sent with its value.  Fields are named by their json name, e.g. "hosts", and the fields of
+// nested objects by their dotted path, e.g. "healthchecks.active.unhealthy.timeouts"
+type Fields struct {
+	// NullFields are sent as null, whatever value the request holds for them
+	NullFields []string `json:"-" yaml:"-"`
+	// ForceSendFields are sent even when they hold their zero value, e.g. an empty list or a zero int
+	ForceSendFields []string `json:"-" yaml:"-"`
+}
+
+// SetNull sends the fields as null
+func (fields *Fields) SetNull(names ...string) {
+	fields.NullFields = append(fields.NullFields, names...)
+}
+
+// ForceSend sends the fields even when they hold their zero value
+func (fields *Fields) ForceSend(names ...string) {
+	fields.ForceSendFields = append(fields.ForceSendFields, names...)
+}
+
+func (fields *Fields) requestFields() *Fields {
+	return fields
+}
+
+// fieldsRequest is implemented by every request embedding Fields
+type fieldsRequest interface {
+	requestFields() *Fields
+}
+
+// versionedRequest is implemented by requests with fields that older versions of kong do not accept, fieldVersions
+// maps the json name of each such field to the version of kong that added it
+type versionedRequest interface {
+	fieldVersions() map[string]string
+}
+
+// requestBody returns the body sent to kong for the request: the request without its unset fields, with its empty
+// lists and force sent fields, and with its null fields set to null.  Fields added after the version of kong being
+// called are left out
+func requestBody(request fieldsRequest, version string) (map[string]interface{}, error) {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	body := map[string]interface{}{}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil, err
+	}
+
+	forceSend := map[string]bool{}
+	for _, name := range request.requestFields().ForceSendFields {
+		forceSend[name] = true
+	}
+
+	sendEmptyFields(body, reflect.ValueOf(request), "", forceSend)
+
+	for _, name := range request.requestFields().NullFields {
+		setBodyField(body, name, nil)
+	}
+
+	if versionedRequest, ok := request.(versionedRequest); ok {
+		for name, since := range versionedRequest.fieldVersions() {
+			if !supports(version, since) {
+				delete(body, name)
+			}
+		}
+	}
+
+	return body, nil
+}
+
+// sendEmptyFields adds the fields left out of the body that are force sent or are empty lists, descending into the
+// nested objects of the body with prefix holding the dotted path of the object
+func sendEmptyFields(body map[string]interface{}, v reflect.Value, prefix string, forceSend map[string]bool) {
+	eachJsonField(v, func(name string, value reflect.Value) {
+		if nested, ok := body[name].(map[string]interface{}); ok {
+			sendEmptyFields(nested, value, prefix+name+".", forceSend)
+			return
+		}
+		if _, ok := body[name]; ok {
+			return
+		}
+		if forceSend[prefix+name] || (value.Kind() == reflect.Slice && !value.IsNil()) {
+			body[name] = value.Interface()
+		}
+	})
+}
+
+// setBodyField sets the field of the body named by its dotted path, creating the objects along the path that the
+// body does not have
+func setBodyField(body map[string]interface{}, path string, value interface{}) {
+	names := strings.Split(path, ".")
+	for _, name := range names[:len(names)-1] {
+		nested, ok := body[name].(map[string]interface{})
+		if !ok {
+			nested = map[string]interface{}{}
+			body[name] = nested
+		}
+		body = nested
+	}
+	body[names[len(names)-1]] = value
+}
+
+// eachJsonField calls fn with the json name and value of each field of the struct, or pointer to a struct, including
+// the fields of embedded structs
+func eachJsonField(v reflect.Value, fn func(name string, value reflect.Value)) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		structField := v.Type().Field(i)
+		name := strings.Split(structField.Tag.Get("json"), ",")[0]
+		switch {
+		case name == "-" || structField.PkgPath != "":
+			continue
+		case structField.Anonymous && name == "":
+			eachJsonField(v.Field(i), fn)
+		case name == "":
+			fn(structField.Name, v.Field(i))
+		default:
+			fn(name, v.Field(i))
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package gokong
+
+import (
+	"testing"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RequestBodyLeavesOutUnsetFields(t *testing.T) {
+	body, err := requestBody(&RouteRequest{Paths: StringSlice([]string{"/foo"})}, "")
+
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"paths": []interface{}{"/foo"}}, body)
+}
+
+func Test_RequestBodySendsEmptyListsNullAndForcedFields(t *testing.T) {
+	routeRequest := &RouteRequest{Hosts: StringSlice([]string{}), RegexPriority: Int(0)}
+	routeRequest.SetNull("name", "service")
+
+	body, err := requestBody(routeRequest, "")
+
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"hosts": []*string{}, "regex_priority": float64(0), "name": nil, "service": nil}, body)
+
+	upstreamRequest := &UpstreamRequest{HashOn: "ip"}
+	upstreamRequest.ForceSend("hash_on_header", "slots")
+
+	body, err = requestBody(upstreamRequest, "")
+
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"hash_on": "ip", "hash_on_header": "", "slots": 0}, body)
+}
+
+func Test_RequestBodyNamesNestedFieldsByTheirPath(t *testing.T) {
+	upstreamRequest := &UpstreamRequest{HealthChecks: &UpstreamHealthCheck{
+		Active: &UpstreamHealthCheckActive{Type: "http", Healthy: &ActiveHealthy{HttpStatuses: []int{}}},
+	}}
+	upstreamRequest.ForceSend("healthchecks.active.http_path")
+	upstreamRequest.SetNull("healthchecks.active.unhealthy.timeouts", "healthchecks.passive")
+
+	body, err := requestBody(upstreamRequest, "")
+
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"healthchecks": map[string]interface{}{
+			"active": map[string]interface{}{
+				"type":                     "http",
+				"http_path":                "",
+				"https_verify_certificate": false,
+				"healthy":                  map[string]interface{}{"http_statuses": []int{}, "interval": float64(0), "successes": float64(0)},
+				"unhealthy":                map[string]interface{}{"timeouts": nil},
+			},
+			"passive": nil,
+		},
+	}, body)
+}
+
+func Test_RoutesUpdateOnlySendsFieldsThatAreSet(t *testing.T) {
+	client := NewClient(NewDefaultConfig())
+	service, err := client.Services().Create(&ServiceRequest{
+		Name:     String("service-name" + uuid.NewV4().String()),
+		Protocol: String("http"),
+		Host:     String("foo.com"),
+	})
+	assert.Nil(t, err)
+
+	route, err := client.Routes().Create(&RouteRequest{
+		Name:    String("route-" + uuid.NewV4().String()),
+		Hosts:   StringSlice([]string{"foo.com"}),
+		Paths:   StringSlice([]string{"/foo"}),
+		Service: ToId(*service.Id),
+	})
+	assert.Nil(t, err)
+
+	updated, err := client.Routes().UpdateById(*route.Id, &RouteRequest{Paths: StringSlice([]string{"/bar"})})
+	assert.Nil(t, err)
+	assert.Equal(t, route.Name, updated.Name)
+	assert.Equal(t, route.Hosts, updated.Hosts)
+	assert.Equal(t, route.Service, updated.Service)
+	assert.Equal(t, StringSlice([]string{"/bar"}), updated.Paths)
+
+	routeRequest := &RouteRequest{}
+	routeRequest.SetNull("name")
+	updated, err = client.Routes().UpdateById(*route.Id, routeRequest)
+	assert.Nil(t, err)
+	assert.Nil(t, updated.Name)
+	assert.Equal(t, route.Hosts, updated.Hosts)
+
+	err = client.Routes().DeleteById(*route.Id)
+	assert.Nil(t, err)
+	err = client.Services().DeleteServiceById(*service.Id)
+	assert.Nil(t, err)
+}
+
+func Test_UpstreamsUpdateCanClearFields(t *testing.T) {
+	client := NewClient(NewDefaultConfig())
+	upstream, err := client.Upstreams().Create(&UpstreamRequest{
+		Name:         "upstream-" + uuid.NewV4().String(),
+		HashOn:       "header",
+		HashOnHeader: "X-Foo",
+	})
+	assert.Nil(t, err)
+
+	upstreamRequest := &UpstreamRequest{HashOn: "ip"}
+	upstreamRequest.SetNull("hash_on_header")
+	updated, err := client.Upstreams().UpdateById(upstream.Id, upstreamRequest)
+	assert.Nil(t, err)
+	assert.Equal(t, upstream.Name, updated.Name)
+	assert.Equal(t, "ip", updated.HashOn)
+	assert.Equal(t, "", updated.HashOnHeader)
+
+	err = client.Upstreams().DeleteById(upstream.Id)
+	assert.Nil(t, err)
+}
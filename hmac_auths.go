@@ -0,0 +1,158 @@
+package gokong
+
+import (
+	"context"
+)
+
+// HmacAuthClient manages hmac-auth credentials of consumers
+type HmacAuthClient struct {
+	credentialClient
+}
+
+type HmacAuthRequest struct {
+	Username string    `json:"username,omitempty" yaml:"username,omitempty"`
+	Secret   string    `json:"secret,omitempty" yaml:"secret,omitempty"`
+	Tags     []*string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Fields   `json:"-" yaml:"-"`
+}
+
+type HmacAuth struct {
+	Id        string    `json:"id,omitempty" yaml:"id,omitempty"`
+	Username  string    `json:"username,omitempty" yaml:"username,omitempty"`
+	Secret    string    `json:"secret,omitempty" yaml:"secret,omitempty"`
+	Consumer  *Id       `json:"consumer,omitempty" yaml:"consumer,omitempty"`
+	CreatedAt int       `json:"created_at,omitempty" yaml:"created_at,omitempty"`
+	Tags      []*string `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+type HmacAuths struct {
+	Results []*HmacAuth `json:"data" yaml:"data,omitempty"`
+	Next    *string     `json:"next" yaml:"next,omitempty"`
+	Offset  string      `json:"offset,omitempty" yaml:"offset,omitempty"`
+}
+
+const HmacAuthsPath = "/hmac-auths/"
+
+func (hmacAuthClient *HmacAuthClient) Create(consumerUsernameOrId string, hmacAuthRequest *HmacAuthRequest) (*HmacAuth, error) {
+	return hmacAuthClient.CreateWithContext(context.Background(), consumerUsernameOrId, hmacAuthRequest)
+}
+
+func (hmacAuthClient *HmacAuthClient) CreateWithContext(ctx context.Context, consumerUsernameOrId string, hmacAuthRequest *HmacAuthRequest) (*HmacAuth, error) {
+	hmacAuth := &HmacAuth{}
+	if err := hmacAuthClient.create(ctx, consumerUsernameOrId, hmacAuthRequest, hmacAuth); err != nil {
+		return nil, err
+	}
+
+	return hmacAuth, nil
+}
+
+func (hmacAuthClient *HmacAuthClient) GetById(consumerUsernameOrId string, id string) (*HmacAuth, error) {
+	return hmacAuthClient.GetByIdWithContext(context.Background(), consumerUsernameOrId, id)
+}
+
+// GetByIdWithContext returns the hmac-auth credential of the consumer with the id or username, or nil when the
+// consumer has no such credential
+func (hmacAuthClient *HmacAuthClient) GetByIdWithContext(ctx context.Context, consumerUsernameOrId string, id string) (*HmacAuth, error) {
+	hmacAuth := &HmacAuth{}
+	found, err := hmacAuthClient.getById(ctx, consumerUsernameOrId, id, hmacAuth)
+	if err != nil || !found || hmacAuth.Id == "" {
+		return nil, err
+	}
+
+	return hmacAuth, nil
+}
+
+func (hmacAuthClient *HmacAuthClient) GetConsumer(id string) (*Consumer, error) {
+	return hmacAuthClient.GetConsumerWithContext(context.Background(), id)
+}
+
+// GetConsumerWithContext returns the consumer owning the hmac-auth credential with the id or username, or nil when
+// there is no such credential
+func (hmacAuthClient *HmacAuthClient) GetConsumerWithContext(ctx context.Context, id string) (*Consumer, error) {
+	return hmacAuthClient.consumer(ctx, id)
+}
+
+func (hmacAuthClient *HmacAuthClient) List(query *CredentialQueryString) ([]*HmacAuth, error) {
+	return hmacAuthClient.ListWithContext(context.Background(), query)
+}
+
+// ListWithContext returns the hmac-auth credentials of every consumer matching the query, following pagination
+func (hmacAuthClient *HmacAuthClient) ListWithContext(ctx context.Context, query *CredentialQueryString) ([]*HmacAuth, error) {
+	hmacAuths := make([]*HmacAuth, 0)
+	if err := hmacAuthClient.list(ctx, "", query, &hmacAuths); err != nil {
+		return nil, err
+	}
+
+	return hmacAuths, nil
+}
+
+func (hmacAuthClient *HmacAuthClient) ListFromConsumer(consumerUsernameOrId string, query *CredentialQueryString) ([]*HmacAuth, error) {
+	return hmacAuthClient.ListFromConsumerWithContext(context.Background(), consumerUsernameOrId, query)
+}
+
+// ListFromConsumerWithContext returns the hmac-auth credentials of the consumer matching the query, following
+// pagination
+func (hmacAuthClient *HmacAuthClient) ListFromConsumerWithContext(ctx context.Context, consumerUsernameOrId string, query *CredentialQueryString) ([]*HmacAuth, error) {
+	hmacAuths := make([]*HmacAuth, 0)
+	if err := hmacAuthClient.list(ctx, consumerUsernameOrId, query, &hmacAuths); err != nil {
+		return nil, err
+	}
+
+	return hmacAuths, nil
+}
+
+// Iter returns an iterator over the hmac-auth credentials of every consumer matching the query
+func (hmacAuthClient *HmacAuthClient) Iter(ctx context.Context, query *CredentialQueryString) *HmacAuthIterator {
+	return &HmacAuthIterator{iterator: hmacAuthClient.iter(ctx, "", query)}
+}
+
+// IterFromConsumer returns an iterator over the hmac-auth credentials of the consumer matching the query
+func (hmacAuthClient *HmacAuthClient) IterFromConsumer(ctx context.Context, consumerUsernameOrId string, query *CredentialQueryString) *HmacAuthIterator {
+	return &HmacAuthIterator{iterator: hmacAuthClient.iter(ctx, consumerUsernameOrId, query)}
+}
+
+type HmacAuthIterator struct {
+	iterator
+}
+
+// Value returns the hmac-auth credential the iterator is positioned on
+func (hmacAuthIterator *HmacAuthIterator) Value() *HmacAuth {
+	hmacAuth, _ := hmacAuthIterator.value.(*HmacAuth)
+	return hmacAuth
+}
+
+func (hmacAuthClient *HmacAuthClient) UpdateById(consumerUsernameOrId string, id string, hmacAuthRequest *HmacAuthRequest) (*HmacAuth, error) {
+	return hmacAuthClient.UpdateByIdWithContext(context.Background(), consumerUsernameOrId, id, hmacAuthRequest)
+}
+
+func (hmacAuthClient *HmacAuthClient) UpdateByIdWithContext(ctx context.Context, consumerUsernameOrId string, id string, hmacAuthRequest *HmacAuthRequest) (*HmacAuth, error) {
+	hmacAuth := &HmacAuth{}
+	if err := hmacAuthClient.update(ctx, consumerUsernameOrId, id, hmacAuthRequest, hmacAuth); err != nil {
+		return nil, err
+	}
+
+	return hmacAuth, nil
+}
+
+func (hmacAuthClient *HmacAuthClient) Upsert(consumerUsernameOrId string, id string, hmacAuthRequest *HmacAuthRequest) (*HmacAuth, error) {
+	return hmacAuthClient.UpsertWithContext(context.Background(), consumerUsernameOrId, id, hmacAuthRequest)
+}
+
+// UpsertWithContext creates the hmac-auth credential of the consumer with the id or username or, when it exists,
+// replaces it in full with the request
+func (hmacAuthClient *HmacAuthClient) UpsertWithContext(ctx context.Context, consumerUsernameOrId string, id string, hmacAuthRequest *HmacAuthRequest) (*HmacAuth, error) {
+	hmacAuth := &HmacAuth{}
+	if err := hmacAuthClient.upsert(ctx, consumerUsernameOrId, id, hmacAuthRequest, hmacAuth); err != nil {
+		return nil, err
+	}
+
+	return hmacAuth, nil
+}
+
+func (hmacAuthClient *HmacAuthClient) DeleteById(consumerUsernameOrId string, id string) error {
+	return hmacAuthClient.DeleteByIdWithContext(context.Background(), consumerUsernameOrId, id)
+}
+
+func (hmacAuthClient *HmacAuthClient) DeleteByIdWithContext(ctx context.Context, consumerUsernameOrId string, id string) error {
+	return hmacAuthClient.delete(ctx, consumerUsernameOrId, id)
+}
@@ -0,0 +1,512 @@
+package gokong
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ImportResult records the outcome of creating a single entity from a snapshot, Id is the id kong assigned to it.
+// Message is the text of Error so the reason an entity failed is kept when the report is written out
+type ImportResult struct {
+	Kind    string `json:"kind" yaml:"kind"`
+	Name    string `json:"name" yaml:"name"`
+	Id      string `json:"id,omitempty" yaml:"id,omitempty"`
+	Error   error  `json:"-" yaml:"-"`
+	Message string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+type ImportReport struct {
+	Results []*ImportResult `json:"results" yaml:"results"`
+}
+
+// Failed returns the results of the entities that could not be imported
+func (importReport *ImportReport) Failed() []*ImportResult {
+	failed := []*ImportResult{}
+	for _, result := range importReport.Results {
+		if result.Error != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+func (importReport *ImportReport) add(kind string, name string, id string, err error) {
+	result := &ImportResult{Kind: kind, Name: name, Id: id, Error: err}
+	if err != nil {
+		result.Message = err.Error()
+	}
+	importReport.Results = append(importReport.Results, result)
+}
+
+func (importReport *ImportReport) skipPlugins(plugins []*PluginState, err error) {
+	for _, pluginState := range plugins {
+		importReport.add("plugin", pluginState.Name, "", err)
+	}
+}
+
+// ReadState reads a snapshot written by State.Write
+func ReadState(r io.Reader, format ExportFormat) (*State, error) {
+	body, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("could not read state, error: %v", err)
+	}
+
+	state := &State{}
+	switch format {
+	case ExportFormatYAML:
+		err = yaml.Unmarshal(body, state)
+	case ExportFormatJSON:
+		err = json.Unmarshal(body, state)
+	default:
+		return nil, fmt.Errorf("unknown export format: %s", format)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("could not parse state, error: %v", err)
+	}
+
+	return state, nil
+}
+
+func (kongAdminClient *KongAdminClient) Import(state *State) (*ImportReport, error) {
+	return kongAdminClient.ImportWithContext(context.Background(), state)
+}
+
+// ImportWithContext creates or replaces every entity in the snapshot in dependency order (certificates, ca
+// certificates, upstreams, targets, services, their routes, routes without a service, consumers then plugins) so it
+// can be imported into an empty or an existing kong.  Entities are replaced by name when they have one, otherwise by
+// the entity already in kong they match (a certificate or ca certificate with the same cert, a consumer with the same
+// custom_id or a plugin with the same name and scope) or else by their id in the snapshot.  References between entities, by name or by id in the
+// snapshot, are remapped to the ids in kong.  Entities that fail to import are recorded in the report and anything
+// depending on them is skipped, an error is returned if any entity failed.
+func (kongAdminClient *KongAdminClient) ImportWithContext(ctx context.Context, state *State) (*ImportReport, error) {
+	importer, err := kongAdminClient.newImporter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, certificateState := range state.Certificates {
+		importer.importCertificate(ctx, certificateState)
+	}
+
+	for _, caCertificateState := range state.CACertificates {
+		importer.importCACertificate(ctx, caCertificateState)
+	}
+
+	for _, upstreamState := range state.Upstreams {
+		importer.importUpstream(ctx, upstreamState)
+	}
+
+	for _, serviceState := range state.Services {
+		importer.importService(ctx, serviceState)
+	}
+
+	for _, routeState := range state.Routes {
+		importer.importRoute(ctx, routeState, nil)
+	}
+
+	for _, consumerState := range state.Consumers {
+		importer.importConsumer(ctx, consumerState)
+	}
+
+	for _, pluginState := range state.Plugins {
+		scoped := *pluginState
+		scoped.ServiceId = remapId(importer.serviceIds, pluginState.ServiceName)
+		scoped.RouteId = remapId(importer.routeIds, pluginState.RouteName)
+		scoped.ConsumerId = remapId(importer.consumerIds, pluginState.ConsumerName)
+
+		if (pluginState.ServiceName != "" && scoped.ServiceId == nil) ||
+			(pluginState.RouteName != "" && scoped.RouteId == nil) ||
+			(pluginState.ConsumerName != "" && scoped.ConsumerId == nil) {
+			importer.report.add("plugin", pluginState.Name, "", fmt.Errorf("an entity plugin %s applies to was not imported", pluginState.Name))
+			continue
+		}
+		importer.plugins = append(importer.plugins, &scoped)
+	}
+
+	for _, pluginState := range importer.plugins {
+		importer.importPlugin(ctx, pluginState)
+	}
+
+	report := importer.report
+	if failed := report.Failed(); len(failed) > 0 {
+		return report, fmt.Errorf("could not import %d of %d entities", len(failed), len(report.Results))
+	}
+
+	return report, nil
+}
+
+// importer holds the ids in kong of the entities imported so far, keyed by both their name and their id in the
+// snapshot, along with the plugins waiting to be imported once the entities they apply to are
+type importer struct {
+	client *KongAdminClient
+	report *ImportReport
+
+	certificateIds   map[string]string
+	caCertificateIds map[string]string
+	serviceIds       map[string]string
+	routeIds         map[string]string
+	consumerIds      map[string]string
+
+	existingCertificates   map[string]string
+	existingCACertificates map[string]string
+	existingPlugins        map[string]string
+
+	plugins []*PluginState
+}
+
+func (kongAdminClient *KongAdminClient) newImporter(ctx context.Context) (*importer, error) {
+	importer := &importer{
+		client:                 kongAdminClient,
+		report:                 &ImportReport{Results: []*ImportResult{}},
+		certificateIds:         map[string]string{},
+		caCertificateIds:       map[string]string{},
+		serviceIds:             map[string]string{},
+		routeIds:               map[string]string{},
+		consumerIds:            map[string]string{},
+		existingCertificates:   map[string]string{},
+		existingCACertificates: map[string]string{},
+		existingPlugins:        map[string]string{},
+	}
+
+	certificates, err := kongAdminClient.Certificates().GetCertificatesWithContext(ctx, &CertificateQueryString{})
+	if err != nil {
+		return nil, fmt.Errorf("could not read the certificates in kong, error: %v", err)
+	}
+	for _, certificate := range certificates {
+		importer.existingCertificates[strings.TrimSpace(stringValue(certificate.Cert))] = *certificate.Id
+	}
+
+	caCertificates, err := kongAdminClient.CACertificates().GetCACertificatesWithContext(ctx, &CACertificateQueryString{})
+	if err != nil {
+		return nil, fmt.Errorf("could not read the ca certificates in kong, error: %v", err)
+	}
+	for _, caCertificate := range caCertificates {
+		importer.existingCACertificates[stringValue(caCertificate.CertDigest)] = *caCertificate.Id
+	}
+
+	plugins, err := kongAdminClient.Plugins().ListWithContext(ctx, &PluginQueryString{})
+	if err != nil {
+		return nil, fmt.Errorf("could not read the plugins in kong, error: %v", err)
+	}
+	for _, plugin := range plugins {
+		importer.existingPlugins[pluginScope(plugin.Name, plugin.ServiceId, plugin.RouteId, plugin.ConsumerId)] = plugin.Id
+	}
+
+	return importer, nil
+}
+
+func (importer *importer) importCertificate(ctx context.Context, certificateState *CertificateState) {
+	request := certificateState.CertificateRequest
+	name := "certificate"
+	if request.SNIs != nil && len(*request.SNIs) > 0 {
+		name = strings.Join(*request.SNIs, ",")
+	}
+
+	id, ok := importer.existingCertificates[strings.TrimSpace(stringValue(request.Cert))]
+	if !ok {
+		id = certificateState.Id
+	}
+
+	var imported *Certificate
+	var err error
+	if id != "" {
+		imported, err = importer.client.Certificates().UpsertWithContext(ctx, id, &request)
+	} else {
+		imported, err = importer.client.Certificates().CreateWithContext(ctx, &request)
+	}
+	if err != nil {
+		importer.report.add("certificate", name, "", err)
+		return
+	}
+	importer.report.add("certificate", name, *imported.Id, nil)
+	rememberId(importer.certificateIds, *imported.Id, certificateState.Id)
+}
+
+// importCACertificate replaces the ca certificate in kong with the same digest, kong allows one per digest
+func (importer *importer) importCACertificate(ctx context.Context, caCertificateState *CACertificateState) {
+	request := caCertificateState.CACertificateRequest
+	name := caCertificateState.Id
+	if name == "" {
+		name = "ca certificate"
+	}
+
+	id := caCertificateState.Id
+	if digest, err := CACertificateDigest(stringValue(request.Cert)); err == nil {
+		if existing, ok := importer.existingCACertificates[digest]; ok {
+			id = existing
+		}
+	}
+
+	var imported *CACertificate
+	var err error
+	if id != "" {
+		imported, err = importer.client.CACertificates().UpsertWithContext(ctx, id, &request)
+	} else {
+		imported, err = importer.client.CACertificates().CreateWithContext(ctx, &request)
+	}
+	if err != nil {
+		importer.report.add("ca certificate", name, "", err)
+		return
+	}
+	importer.report.add("ca certificate", name, *imported.Id, nil)
+	rememberId(importer.caCertificateIds, *imported.Id, caCertificateState.Id)
+}
+
+// importUpstream replaces the upstream in kong with the same name or, when there is none, the upstream with its id in
+// the snapshot so an upstream that was renamed is still matched
+func (importer *importer) importUpstream(ctx context.Context, upstreamState *UpstreamState) {
+	request := upstreamState.UpstreamRequest
+
+	key := request.Name
+	named, err := importer.client.Upstreams().GetByNameWithContext(ctx, request.Name)
+	if err == nil && named == nil && upstreamState.Id != "" {
+		key = upstreamState.Id
+	}
+
+	var imported *Upstream
+	if err == nil {
+		imported, err = importer.client.Upstreams().UpsertWithContext(ctx, key, &request)
+	}
+	if err != nil {
+		importer.report.add("upstream", request.Name, "", err)
+		for _, target := range upstreamState.Targets {
+			importer.report.add("target", request.Name+"/"+target.Target, "", fmt.Errorf("upstream %s was not imported", request.Name))
+		}
+		return
+	}
+	importer.report.add("upstream", request.Name, imported.Id, nil)
+
+	existing, err := importer.client.Targets().GetTargetsFromUpstreamIdWithContext(ctx, imported.Id)
+	if err != nil {
+		for _, target := range upstreamState.Targets {
+			importer.report.add("target", request.Name+"/"+target.Target, "", err)
+		}
+		return
+	}
+	existingTargets := map[string]*Target{}
+	for _, target := range existing {
+		existingTargets[*target.Target] = target
+	}
+
+	for _, target := range upstreamState.Targets {
+		name := request.Name + "/" + target.Target
+
+		// targets can not be updated, so one with a different weight is deleted and created again
+		if existingTarget, ok := existingTargets[target.Target]; ok {
+			if *existingTarget.Weight == target.Weight {
+				importer.report.add("target", name, *existingTarget.Id, nil)
+				continue
+			}
+			if err := importer.client.Targets().DeleteFromUpstreamByIdWithContext(ctx, imported.Id, *existingTarget.Id); err != nil {
+				importer.report.add("target", name, "", err)
+				continue
+			}
+		}
+
+		createdTarget, err := importer.client.Targets().CreateFromUpstreamIdWithContext(ctx, imported.Id, target)
+		if err != nil {
+			importer.report.add("target", name, "", err)
+			continue
+		}
+		importer.report.add("target", name, *createdTarget.Id, nil)
+	}
+}
+
+func (importer *importer) importService(ctx context.Context, serviceState *ServiceState) {
+	request := serviceState.ServiceRequest
+	name := stringValue(request.Name)
+
+	if err := importer.remapCertificates(&request); err != nil {
+		importer.skipService(serviceState, name, err)
+		return
+	}
+
+	var imported *Service
+	var err error
+	if key := dumpName(request.Name, serviceState.Id); key != "" {
+		imported, err = importer.client.Services().UpsertWithContext(ctx, key, &request)
+	} else {
+		imported, err = importer.client.Services().CreateWithContext(ctx, &request)
+	}
+	if err != nil {
+		importer.skipService(serviceState, name, err)
+		return
+	}
+	importer.report.add("service", name, *imported.Id, nil)
+	rememberId(importer.serviceIds, *imported.Id, name, serviceState.Id)
+	importer.plugins = append(importer.plugins, scopePlugins(serviceState.Plugins, imported.Id, nil, nil)...)
+
+	for _, routeState := range serviceState.Routes {
+		importer.importRoute(ctx, routeState, ToId(*imported.Id))
+	}
+}
+
+// remapCertificates points the client certificate and ca certificates of the service at the certificates imported
+// in their place, it fails when one of them was not imported
+func (importer *importer) remapCertificates(request *ServiceRequest) error {
+	if request.ClientCertificate != nil {
+		id, ok := importer.certificateIds[IdToString(request.ClientCertificate)]
+		if !ok {
+			return fmt.Errorf("client certificate %s was not imported", IdToString(request.ClientCertificate))
+		}
+		request.ClientCertificate = ToId(id)
+	}
+
+	if request.CaCertificates != nil {
+		caCertificates := make([]*string, 0, len(request.CaCertificates))
+		for _, caCertificate := range StringValueSlice(request.CaCertificates) {
+			id, ok := importer.caCertificateIds[caCertificate]
+			if !ok {
+				return fmt.Errorf("ca certificate %s was not imported", caCertificate)
+			}
+			caCertificates = append(caCertificates, String(id))
+		}
+		request.CaCertificates = caCertificates
+	}
+
+	return nil
+}
+
+// skipService records the service as failed and skips its routes and plugins
+func (importer *importer) skipService(serviceState *ServiceState, name string, err error) {
+	importer.report.add("service", name, "", err)
+	skipped := fmt.Errorf("service %s was not imported", name)
+	importer.report.skipPlugins(serviceState.Plugins, skipped)
+	for _, routeState := range serviceState.Routes {
+		importer.report.add("route", stringValue(routeState.Name), "", skipped)
+		importer.report.skipPlugins(routeState.Plugins, skipped)
+	}
+}
+
+// importRoute imports the route under the service, or without a service when serviceId is nil
+func (importer *importer) importRoute(ctx context.Context, routeState *RouteState, serviceId *Id) {
+	request := routeState.RouteRequest
+	request.Service = serviceId
+	name := stringValue(request.Name)
+
+	var imported *Route
+	var err error
+	if key := dumpName(request.Name, routeState.Id); key != "" {
+		imported, err = importer.client.Routes().UpsertWithContext(ctx, key, &request)
+	} else {
+		imported, err = importer.client.Routes().CreateWithContext(ctx, &request)
+	}
+	if err != nil {
+		importer.report.add("route", name, "", err)
+		importer.report.skipPlugins(routeState.Plugins, fmt.Errorf("route %s was not imported", name))
+		return
+	}
+	importer.report.add("route", name, *imported.Id, nil)
+	rememberId(importer.routeIds, *imported.Id, name, routeState.Id)
+	importer.plugins = append(importer.plugins, scopePlugins(routeState.Plugins, nil, imported.Id, nil)...)
+}
+
+func (importer *importer) importConsumer(ctx context.Context, consumerState *ConsumerState) {
+	request := consumerState.ConsumerRequest
+	name := request.Username
+	if name == "" {
+		name = request.CustomId
+	}
+
+	key := request.Username
+	if key == "" && request.CustomId != "" {
+		existing, err := importer.client.Consumers().GetConsumersWithContext(ctx, &ConsumerQueryString{CustomId: request.CustomId})
+		if err != nil {
+			importer.report.add("consumer", name, "", err)
+			importer.report.skipPlugins(consumerState.Plugins, fmt.Errorf("consumer %s was not imported", name))
+			return
+		}
+		if len(existing) > 0 {
+			key = existing[0].Id
+		}
+	}
+	if key == "" {
+		key = consumerState.Id
+	}
+
+	var imported *Consumer
+	var err error
+	if key != "" {
+		imported, err = importer.client.Consumers().UpsertWithContext(ctx, key, &request)
+	} else {
+		imported, err = importer.client.Consumers().CreateWithContext(ctx, &request)
+	}
+	if err != nil {
+		importer.report.add("consumer", name, "", err)
+		importer.report.skipPlugins(consumerState.Plugins, fmt.Errorf("consumer %s was not imported", name))
+		return
+	}
+	importer.report.add("consumer", name, imported.Id, nil)
+	rememberId(importer.consumerIds, imported.Id, request.Username, request.CustomId, consumerState.Id)
+	importer.plugins = append(importer.plugins, scopePlugins(consumerState.Plugins, nil, nil, &imported.Id)...)
+}
+
+func (importer *importer) importPlugin(ctx context.Context, pluginState *PluginState) {
+	request := pluginState.PluginRequest
+
+	id, ok := importer.existingPlugins[pluginScope(request.Name, request.ServiceId, request.RouteId, request.ConsumerId)]
+	if !ok {
+		id = pluginState.Id
+	}
+
+	var imported *Plugin
+	var err error
+	if id != "" {
+		imported, err = importer.client.Plugins().UpsertWithContext(ctx, id, &request)
+	} else {
+		imported, err = importer.client.Plugins().CreateWithContext(ctx, &request)
+	}
+	if err != nil {
+		importer.report.add("plugin", request.Name, "", err)
+		return
+	}
+	importer.report.add("plugin", request.Name, imported.Id, nil)
+}
+
+// scopePlugins returns copies of the plugins with their service, route or consumer set to the newly created entity
+func scopePlugins(plugins []*PluginState, serviceId *string, routeId *string, consumerId *string) []*PluginState {
+	scoped := []*PluginState{}
+	for _, pluginState := range plugins {
+		plugin := *pluginState
+		plugin.ServiceId, plugin.RouteId, plugin.ConsumerId = nil, nil, nil
+		if serviceId != nil {
+			plugin.ServiceId = ToId(*serviceId)
+		}
+		if routeId != nil {
+			plugin.RouteId = ToId(*routeId)
+		}
+		if consumerId != nil {
+			plugin.ConsumerId = ToId(*consumerId)
+		}
+		scoped = append(scoped, &plugin)
+	}
+	return scoped
+}
+
+// pluginScope identifies a plugin by its name and the entities it applies to, kong allows one plugin per scope
+func pluginScope(name string, serviceId *Id, routeId *Id, consumerId *Id) string {
+	return strings.Join([]string{name, IdToString(serviceId), IdToString(routeId), IdToString(consumerId)}, "/")
+}
+
+// rememberId records the id in kong of an entity under each of the non empty names and snapshot ids it is known by
+func rememberId(ids map[string]string, id string, keys ...string) {
+	for _, key := range keys {
+		if key != "" {
+			ids[key] = id
+		}
+	}
+}
+
+func remapId(ids map[string]string, name string) *Id {
+	if id, ok := ids[name]; ok {
+		return ToId(id)
+	}
+	return nil
+}
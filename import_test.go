@@ -0,0 +1,297 @@
+package gokong
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ImportCreatesEntitiesAndRemapsReferences(t *testing.T) {
+	serviceName := "service-name-" + uuid.NewV4().String()
+	routeName := "route-name-" + uuid.NewV4().String()
+	username := "username-" + uuid.NewV4().String()
+
+	state := &State{
+		Services: []*ServiceState{{
+			ServiceRequest: ServiceRequest{Name: String(serviceName), Protocol: String("http"), Host: String("foo.com")},
+			Routes: []*RouteState{{
+				RouteRequest: RouteRequest{Name: String(routeName), Protocols: StringSlice([]string{"http"}), Paths: StringSlice([]string{"/import"})},
+			}},
+		}},
+		Consumers: []*ConsumerState{{ConsumerRequest: ConsumerRequest{Username: username}}},
+		Plugins: []*PluginState{{
+			PluginRequest: PluginRequest{Name: "rate-limiting", Config: map[string]interface{}{"minute": 20}},
+			RouteName:     routeName,
+			ConsumerName:  username,
+		}},
+	}
+
+	client := NewClient(NewDefaultConfig())
+	report, err := client.Import(state)
+
+	assert.Nil(t, err)
+	assert.Len(t, report.Results, 4)
+	assert.Empty(t, report.Failed())
+
+	service, err := client.Services().GetServiceByName(serviceName)
+	assert.Nil(t, err)
+	route, err := client.Routes().GetByName(routeName)
+	assert.Nil(t, err)
+	assert.Equal(t, *service.Id, IdToString(route.Service))
+	consumer, err := client.Consumers().GetByUsername(username)
+	assert.Nil(t, err)
+
+	plugin, err := client.Plugins().GetById(report.Results[3].Id)
+	assert.Nil(t, err)
+	assert.Equal(t, *route.Id, IdToString(plugin.RouteId))
+	assert.Equal(t, consumer.Id, IdToString(plugin.ConsumerId))
+
+	client.Plugins().DeleteById(plugin.Id)
+	client.Consumers().DeleteById(consumer.Id)
+	client.Routes().DeleteById(*route.Id)
+	client.Services().DeleteServiceById(*service.Id)
+}
+
+func Test_ImportCreatesRoutesWithoutAService(t *testing.T) {
+	routeName := "route-name-" + uuid.NewV4().String()
+	state := &State{
+		Routes: []*RouteState{{
+			RouteRequest: RouteRequest{Name: String(routeName), Protocols: StringSlice([]string{"http"}), Paths: StringSlice([]string{"/import"})},
+		}},
+		Plugins: []*PluginState{{
+			PluginRequest: PluginRequest{Name: "rate-limiting", Config: map[string]interface{}{"minute": 20}},
+			RouteName:     routeName,
+		}},
+	}
+
+	client := NewClient(NewDefaultConfig())
+	report, err := client.Import(state)
+
+	assert.Nil(t, err)
+	assert.Empty(t, report.Failed())
+
+	route, err := client.Routes().GetByName(routeName)
+	assert.Nil(t, err)
+	assert.Nil(t, route.Service)
+
+	plugin, err := client.Plugins().GetById(report.Results[1].Id)
+	assert.Nil(t, err)
+	assert.Equal(t, *route.Id, IdToString(plugin.RouteId))
+
+	client.Plugins().DeleteById(plugin.Id)
+	client.Routes().DeleteById(*route.Id)
+}
+
+func Test_ImportReplacesEntitiesThatAlreadyExist(t *testing.T) {
+	serviceName := "service-name-" + uuid.NewV4().String()
+	upstreamName := "upstream-" + uuid.NewV4().String()
+	customId := "custom-id-" + uuid.NewV4().String()
+
+	state := &State{
+		Certificates: []*CertificateState{{CertificateRequest: CertificateRequest{Cert: String(testCert1), Key: String(testKey1)}}},
+		Upstreams: []*UpstreamState{{
+			UpstreamRequest: UpstreamRequest{Name: upstreamName},
+			Targets:         []*TargetRequest{{Target: "10.0.0.1:80", Weight: 10}},
+		}},
+		Services: []*ServiceState{{
+			ServiceRequest: ServiceRequest{Name: String(serviceName), Protocol: String("http"), Host: String("foo.com")},
+			Plugins:        []*PluginState{{PluginRequest: PluginRequest{Name: "cors"}}},
+		}},
+		Consumers: []*ConsumerState{{ConsumerRequest: ConsumerRequest{CustomId: customId}}},
+	}
+
+	client := NewClient(NewDefaultConfig())
+	first, err := client.Import(state)
+	assert.Nil(t, err)
+	assert.Empty(t, first.Failed())
+
+	state.Services[0].Host = String("bar.com")
+	state.Upstreams[0].Targets[0].Weight = 20
+	second, err := client.Import(state)
+	assert.Nil(t, err)
+	assert.Empty(t, second.Failed())
+
+	assert.Equal(t, len(first.Results), len(second.Results))
+	for i, result := range second.Results {
+		if result.Kind != "target" {
+			assert.Equal(t, first.Results[i].Id, result.Id)
+		}
+	}
+
+	service, err := client.Services().GetServiceByName(serviceName)
+	assert.Nil(t, err)
+	assert.Equal(t, "bar.com", *service.Host)
+	targets, err := client.Targets().GetTargetsFromUpstreamName(upstreamName)
+	assert.Nil(t, err)
+	assert.Len(t, targets, 1)
+	assert.Equal(t, 20, *targets[0].Weight)
+
+	for _, result := range second.Results {
+		switch result.Kind {
+		case "plugin":
+			client.Plugins().DeleteById(result.Id)
+		case "consumer":
+			client.Consumers().DeleteById(result.Id)
+		case "certificate":
+			client.Certificates().DeleteById(result.Id)
+		}
+	}
+	client.Services().DeleteServiceById(*service.Id)
+	client.Upstreams().DeleteById(second.Results[1].Id)
+}
+
+func Test_ImportResolvesReferencesToUnnamedEntitiesByTheirSnapshotId(t *testing.T) {
+	serviceId := uuid.NewV4().String()
+	routeId := uuid.NewV4().String()
+
+	state := &State{
+		Services: []*ServiceState{{
+			ServiceRequest: ServiceRequest{Protocol: String("http"), Host: String("foo.com")},
+			Id:             serviceId,
+			Routes: []*RouteState{{
+				RouteRequest: RouteRequest{Protocols: StringSlice([]string{"http"}), Paths: StringSlice([]string{"/unnamed"})},
+				Id:           routeId,
+			}},
+		}},
+		Plugins: []*PluginState{{
+			PluginRequest: PluginRequest{Name: "rate-limiting", Config: map[string]interface{}{"minute": 20}},
+			ServiceName:   serviceId,
+			RouteName:     routeId,
+		}},
+	}
+
+	client := NewClient(NewDefaultConfig())
+	report, err := client.Import(state)
+	assert.Nil(t, err)
+	assert.Empty(t, report.Failed())
+
+	plugin, err := client.Plugins().GetById(report.Results[2].Id)
+	assert.Nil(t, err)
+	assert.Equal(t, report.Results[0].Id, IdToString(plugin.ServiceId))
+	assert.Equal(t, report.Results[1].Id, IdToString(plugin.RouteId))
+
+	client.Plugins().DeleteById(plugin.Id)
+	client.Routes().DeleteById(report.Results[1].Id)
+	client.Services().DeleteServiceById(report.Results[0].Id)
+}
+
+func Test_ImportRemapsTheCertificatesOfServices(t *testing.T) {
+	client := NewClient(NewDefaultConfig())
+
+	cert, key := newTestServerCertificate(t, time.Now().Add(24*time.Hour), "foo.com")
+	caCert := newTestCACertificate(t, true, time.Now().Add(24*time.Hour))
+
+	certificate, err := client.Certificates().Create(&CertificateRequest{Cert: String(cert), Key: String(key)})
+	assert.Nil(t, err)
+	caCertificate, err := client.CACertificates().Create(&CACertificateRequest{Cert: String(caCert)})
+	assert.Nil(t, err)
+
+	certificateId := uuid.NewV4().String()
+	caCertificateId := uuid.NewV4().String()
+	serviceName := "service-name-" + uuid.NewV4().String()
+	state := &State{
+		Certificates:   []*CertificateState{{CertificateRequest: CertificateRequest{Cert: String(cert), Key: String(key)}, Id: certificateId}},
+		CACertificates: []*CACertificateState{{CACertificateRequest: CACertificateRequest{Cert: String(caCert)}, Id: caCertificateId}},
+		Services: []*ServiceState{{ServiceRequest: ServiceRequest{
+			Name:              String(serviceName),
+			Url:               String("https://foo.com"),
+			ClientCertificate: ToId(certificateId),
+			TlsVerify:         Bool(true),
+			CaCertificates:    StringSlice([]string{caCertificateId}),
+		}}},
+	}
+
+	report, err := client.Import(state)
+	assert.Nil(t, err)
+	assert.Equal(t, *certificate.Id, report.Results[0].Id)
+	assert.Equal(t, *caCertificate.Id, report.Results[1].Id)
+
+	service, err := client.Services().GetServiceByName(serviceName)
+	assert.Nil(t, err)
+	assert.Equal(t, *certificate.Id, IdToString(service.ClientCertificate))
+	assert.Equal(t, []*string{caCertificate.Id}, service.CaCertificates)
+
+	state = &State{Services: []*ServiceState{{ServiceRequest: ServiceRequest{
+		Name:           String("service-name-" + uuid.NewV4().String()),
+		Url:            String("https://foo.com"),
+		CaCertificates: StringSlice([]string{caCertificateId}),
+	}}}}
+
+	report, err = client.Import(state)
+	assert.NotNil(t, err)
+	assert.Equal(t, "ca certificate "+caCertificateId+" was not imported", report.Results[0].Error.Error())
+
+	assert.Nil(t, client.Services().DeleteServiceById(*service.Id))
+	assert.Nil(t, client.CACertificates().DeleteById(*caCertificate.Id))
+	assert.Nil(t, client.Certificates().DeleteById(*certificate.Id))
+}
+
+func Test_ImportReportsFailuresAndSkipsDependants(t *testing.T) {
+	state := &State{
+		Services: []*ServiceState{{
+			ServiceRequest: ServiceRequest{Name: String("service-name-" + uuid.NewV4().String()), Protocol: String("foo"), Host: String("foo.com")},
+			Routes:         []*RouteState{{RouteRequest: RouteRequest{Name: String("route-name-" + uuid.NewV4().String())}}},
+		}},
+	}
+
+	report, err := NewClient(NewDefaultConfig()).Import(state)
+
+	assert.NotNil(t, err)
+	assert.Len(t, report.Failed(), 2)
+	assert.True(t, IsSchemaViolation(report.Results[0].Error))
+
+	body, err := json.Marshal(report)
+	assert.Nil(t, err)
+	written := &ImportReport{}
+	assert.Nil(t, json.Unmarshal(body, written))
+	assert.Equal(t, report.Results[0].Error.Error(), written.Results[0].Message)
+	assert.Equal(t, "service "+*state.Services[0].Name+" was not imported", written.Results[1].Message)
+}
+
+func Test_ImportMatchesARenamedUpstreamByItsSnapshotId(t *testing.T) {
+	client := NewClient(NewDefaultConfig())
+	created, err := client.Upstreams().Create(&UpstreamRequest{Name: "upstream-" + uuid.NewV4().String()})
+	assert.Nil(t, err)
+
+	renamed := "upstream-" + uuid.NewV4().String()
+	report, err := client.Import(&State{Upstreams: []*UpstreamState{{UpstreamRequest: UpstreamRequest{Name: renamed, Slots: 20}, Id: created.Id}}})
+	assert.Nil(t, err)
+	assert.Equal(t, created.Id, report.Results[0].Id)
+
+	upstream, err := client.Upstreams().GetById(created.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, renamed, upstream.Name)
+	assert.Equal(t, 20, upstream.Slots)
+
+	state, err := client.Dump()
+	assert.Nil(t, err)
+	for _, upstreamState := range state.Upstreams {
+		if upstreamState.Name == renamed {
+			assert.Equal(t, created.Id, upstreamState.Id)
+		}
+	}
+
+	assert.Nil(t, client.Upstreams().DeleteById(created.Id))
+}
+
+func Test_ReadStateReadsWrittenState(t *testing.T) {
+	state := &State{
+		Consumers: []*ConsumerState{{ConsumerRequest: ConsumerRequest{Username: "foo"}}},
+		Upstreams: []*UpstreamState{{UpstreamRequest: UpstreamRequest{Name: "bar"}, Targets: []*TargetRequest{{Target: "a:80", Weight: 10}}}},
+	}
+
+	for _, format := range []ExportFormat{ExportFormatYAML, ExportFormatJSON} {
+		buffer := &bytes.Buffer{}
+		err := state.Write(buffer, format)
+		assert.Nil(t, err)
+
+		result, err := ReadState(buffer, format)
+		assert.Nil(t, err)
+		assert.Equal(t, state.Consumers, result.Consumers)
+		assert.Equal(t, state.Upstreams[0].Targets, result.Upstreams[0].Targets)
+	}
+}
@@ -0,0 +1,51 @@
+package gokong
+
+import "reflect"
+
+// iterator walks the results of a kong list endpoint one at a time, it is embedded by the iterator of each entity
+// which adds a typed Value method.  Pages are only fetched from kong as the iterator reaches them so stopping early
+// avoids loading the remaining pages
+type iterator struct {
+	pages   *pager
+	newPage func() listPage
+	page    reflect.Value
+	value   interface{}
+	err     error
+}
+
+// newIterator returns an iterator over the pages, newPage returns an empty page of the list response to fetch into
+func newIterator(pages *pager, newPage func() listPage) iterator {
+	return iterator{pages: pages, newPage: newPage}
+}
+
+// Next advances to the next result, fetching the next page when the current one is used up.  It returns false when
+// there are no more results or a page could not be fetched, in which case Err returns the error
+func (iterator *iterator) Next() bool {
+	for !iterator.page.IsValid() || iterator.page.Len() == 0 {
+		page := iterator.newPage()
+		if !iterator.fetch(page) {
+			iterator.value = nil
+			return false
+		}
+		iterator.page = reflect.ValueOf(page.items())
+	}
+
+	iterator.value = iterator.page.Index(0).Interface()
+	iterator.page = iterator.page.Slice(1, iterator.page.Len())
+	return true
+}
+
+// fetch fetches the next page into page, it returns false once there are no more pages or a page could not be fetched
+func (iterator *iterator) fetch(page listPage) bool {
+	if iterator.err != nil || !iterator.pages.more() {
+		return false
+	}
+
+	iterator.err = iterator.pages.next(page)
+	return iterator.err == nil
+}
+
+// Err returns the error that stopped the iteration, if any
+func (iterator *iterator) Err() error {
+	return iterator.err
+}
@@ -0,0 +1,69 @@
+package gokong
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_IteratorWalksEveryPage(t *testing.T) {
+	queries := []string{}
+	server := newPagedServer(&queries, []string{`{"id":"1"},{"id":"2"}`, `{"id":"3"}`})
+	defer server.Close()
+
+	iterator := NewClient(&Config{HostAddress: server.URL}).Routes().Iter(context.Background(), &RouteQueryString{})
+
+	ids := []string{}
+	for iterator.Next() {
+		ids = append(ids, *iterator.Value().Id)
+	}
+
+	assert.Nil(t, iterator.Err())
+	assert.Nil(t, iterator.Value())
+	assert.Equal(t, []string{"1", "2", "3"}, ids)
+	assert.Equal(t, []string{"size=100", "offset=1&size=100"}, queries)
+}
+
+func Test_IteratorOnlyFetchesPagesItReaches(t *testing.T) {
+	queries := []string{}
+	server := newPagedServer(&queries, []string{`{"id":"1"}`, `{"id":"2"}`, `{"id":"3"}`})
+	defer server.Close()
+
+	iterator := NewClient(&Config{HostAddress: server.URL}).Plugins().Iter(context.Background(), &PluginQueryString{Size: 500})
+
+	assert.True(t, iterator.Next())
+	assert.Equal(t, "1", iterator.Value().Id)
+	assert.True(t, iterator.Next())
+	assert.Equal(t, "2", iterator.Value().Id)
+	assert.Equal(t, []string{"size=500", "offset=1&size=500"}, queries)
+}
+
+func Test_IteratorSkipsEmptyPages(t *testing.T) {
+	queries := []string{}
+	server := newPagedServer(&queries, []string{``, `{"id":"1"}`})
+	defer server.Close()
+
+	iterator := NewClient(&Config{HostAddress: server.URL}).Targets().IterFromUpstreamId(context.Background(), "upstream", &TargetQueryString{})
+
+	assert.True(t, iterator.Next())
+	assert.Equal(t, "1", *iterator.Value().Id)
+	assert.False(t, iterator.Next())
+	assert.Nil(t, iterator.Err())
+}
+
+func Test_IteratorStopsWhenTheContextIsCancelled(t *testing.T) {
+	queries := []string{}
+	server := newPagedServer(&queries, []string{`{"id":"1"}`, `{"id":"2"}`})
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	iterator := NewClient(&Config{HostAddress: server.URL}).Services().Iter(ctx, &ServiceQueryString{})
+
+	assert.True(t, iterator.Next())
+	cancel()
+
+	assert.False(t, iterator.Next())
+	assert.NotNil(t, iterator.Err())
+	assert.Equal(t, 1, len(queries))
+}
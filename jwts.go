@@ -0,0 +1,161 @@
+package gokong
+
+import (
+	"context"
+)
+
+// JwtClient manages jwt credentials of consumers
+type JwtClient struct {
+	credentialClient
+}
+
+type JwtRequest struct {
+	Key          string    `json:"key,omitempty" yaml:"key,omitempty"`
+	Secret       string    `json:"secret,omitempty" yaml:"secret,omitempty"`
+	Algorithm    string    `json:"algorithm,omitempty" yaml:"algorithm,omitempty"`
+	RsaPublicKey string    `json:"rsa_public_key,omitempty" yaml:"rsa_public_key,omitempty"`
+	Tags         []*string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Fields       `json:"-" yaml:"-"`
+}
+
+type Jwt struct {
+	Id           string    `json:"id,omitempty" yaml:"id,omitempty"`
+	Key          string    `json:"key,omitempty" yaml:"key,omitempty"`
+	Secret       string    `json:"secret,omitempty" yaml:"secret,omitempty"`
+	Algorithm    string    `json:"algorithm,omitempty" yaml:"algorithm,omitempty"`
+	RsaPublicKey string    `json:"rsa_public_key,omitempty" yaml:"rsa_public_key,omitempty"`
+	Consumer     *Id       `json:"consumer,omitempty" yaml:"consumer,omitempty"`
+	CreatedAt    int       `json:"created_at,omitempty" yaml:"created_at,omitempty"`
+	Tags         []*string `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+type Jwts struct {
+	Results []*Jwt  `json:"data" yaml:"data,omitempty"`
+	Next    *string `json:"next" yaml:"next,omitempty"`
+	Offset  string  `json:"offset,omitempty" yaml:"offset,omitempty"`
+}
+
+const JwtsPath = "/jwts/"
+
+func (jwtClient *JwtClient) Create(consumerUsernameOrId string, jwtRequest *JwtRequest) (*Jwt, error) {
+	return jwtClient.CreateWithContext(context.Background(), consumerUsernameOrId, jwtRequest)
+}
+
+func (jwtClient *JwtClient) CreateWithContext(ctx context.Context, consumerUsernameOrId string, jwtRequest *JwtRequest) (*Jwt, error) {
+	jwt := &Jwt{}
+	if err := jwtClient.create(ctx, consumerUsernameOrId, jwtRequest, jwt); err != nil {
+		return nil, err
+	}
+
+	return jwt, nil
+}
+
+func (jwtClient *JwtClient) GetById(consumerUsernameOrId string, id string) (*Jwt, error) {
+	return jwtClient.GetByIdWithContext(context.Background(), consumerUsernameOrId, id)
+}
+
+// GetByIdWithContext returns the jwt credential of the consumer with the id or key, or nil when the consumer has no
+// such credential
+func (jwtClient *JwtClient) GetByIdWithContext(ctx context.Context, consumerUsernameOrId string, id string) (*Jwt, error) {
+	jwt := &Jwt{}
+	found, err := jwtClient.getById(ctx, consumerUsernameOrId, id, jwt)
+	if err != nil || !found || jwt.Id == "" {
+		return nil, err
+	}
+
+	return jwt, nil
+}
+
+func (jwtClient *JwtClient) GetConsumer(id string) (*Consumer, error) {
+	return jwtClient.GetConsumerWithContext(context.Background(), id)
+}
+
+// GetConsumerWithContext returns the consumer owning the jwt credential with the id or key, or nil when there is no
+// such credential
+func (jwtClient *JwtClient) GetConsumerWithContext(ctx context.Context, id string) (*Consumer, error) {
+	return jwtClient.consumer(ctx, id)
+}
+
+func (jwtClient *JwtClient) List(query *CredentialQueryString) ([]*Jwt, error) {
+	return jwtClient.ListWithContext(context.Background(), query)
+}
+
+// ListWithContext returns the jwt credentials of every consumer matching the query, following pagination
+func (jwtClient *JwtClient) ListWithContext(ctx context.Context, query *CredentialQueryString) ([]*Jwt, error) {
+	jwts := make([]*Jwt, 0)
+	if err := jwtClient.list(ctx, "", query, &jwts); err != nil {
+		return nil, err
+	}
+
+	return jwts, nil
+}
+
+func (jwtClient *JwtClient) ListFromConsumer(consumerUsernameOrId string, query *CredentialQueryString) ([]*Jwt, error) {
+	return jwtClient.ListFromConsumerWithContext(context.Background(), consumerUsernameOrId, query)
+}
+
+// ListFromConsumerWithContext returns the jwt credentials of the consumer matching the query, following pagination
+func (jwtClient *JwtClient) ListFromConsumerWithContext(ctx context.Context, consumerUsernameOrId string, query *CredentialQueryString) ([]*Jwt, error) {
+	jwts := make([]*Jwt, 0)
+	if err := jwtClient.list(ctx, consumerUsernameOrId, query, &jwts); err != nil {
+		return nil, err
+	}
+
+	return jwts, nil
+}
+
+// Iter returns an iterator over the jwt credentials of every consumer matching the query
+func (jwtClient *JwtClient) Iter(ctx context.Context, query *CredentialQueryString) *JwtIterator {
+	return &JwtIterator{iterator: jwtClient.iter(ctx, "", query)}
+}
+
+// IterFromConsumer returns an iterator over the jwt credentials of the consumer matching the query
+func (jwtClient *JwtClient) IterFromConsumer(ctx context.Context, consumerUsernameOrId string, query *CredentialQueryString) *JwtIterator {
+	return &JwtIterator{iterator: jwtClient.iter(ctx, consumerUsernameOrId, query)}
+}
+
+type JwtIterator struct {
+	iterator
+}
+
+// Value returns the jwt credential the iterator is positioned on
+func (jwtIterator *JwtIterator) Value() *Jwt {
+	jwt, _ := jwtIterator.value.(*Jwt)
+	return jwt
+}
+
+func (jwtClient *JwtClient) UpdateById(consumerUsernameOrId string, id string, jwtRequest *JwtRequest) (*Jwt, error) {
+	return jwtClient.UpdateByIdWithContext(context.Background(), consumerUsernameOrId, id, jwtRequest)
+}
+
+func (jwtClient *JwtClient) UpdateByIdWithContext(ctx context.Context, consumerUsernameOrId string, id string, jwtRequest *JwtRequest) (*Jwt, error) {
+	jwt := &Jwt{}
+	if err := jwtClient.update(ctx, consumerUsernameOrId, id, jwtRequest, jwt); err != nil {
+		return nil, err
+	}
+
+	return jwt, nil
+}
+
+func (jwtClient *JwtClient) Upsert(consumerUsernameOrId string, id string, jwtRequest *JwtRequest) (*Jwt, error) {
+	return jwtClient.UpsertWithContext(context.Background(), consumerUsernameOrId, id, jwtRequest)
+}
+
+// UpsertWithContext creates the jwt credential of the consumer with the id or key or, when it exists, replaces it in
+// full with the request
+func (jwtClient *JwtClient) UpsertWithContext(ctx context.Context, consumerUsernameOrId string, id string, jwtRequest *JwtRequest) (*Jwt, error) {
+	jwt := &Jwt{}
+	if err := jwtClient.upsert(ctx, consumerUsernameOrId, id, jwtRequest, jwt); err != nil {
+		return nil, err
+	}
+
+	return jwt, nil
+}
+
+func (jwtClient *JwtClient) DeleteById(consumerUsernameOrId string, id string) error {
+	return jwtClient.DeleteByIdWithContext(context.Background(), consumerUsernameOrId, id)
+}
+
+func (jwtClient *JwtClient) DeleteByIdWithContext(ctx context.Context, consumerUsernameOrId string, id string) error {
+	return jwtClient.delete(ctx, consumerUsernameOrId, id)
+}
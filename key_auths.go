@@ -0,0 +1,158 @@
+package gokong
+
+import (
+	"context"
+)
+
+// KeyAuthClient manages key-auth credentials of consumers
+type KeyAuthClient struct {
+	credentialClient
+}
+
+type KeyAuthRequest struct {
+	Key    string    `json:"key,omitempty" yaml:"key,omitempty"`
+	Ttl    *int      `json:"ttl,omitempty" yaml:"ttl,omitempty"`
+	Tags   []*string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Fields `json:"-" yaml:"-"`
+}
+
+type KeyAuth struct {
+	Id        string    `json:"id,omitempty" yaml:"id,omitempty"`
+	Key       string    `json:"key,omitempty" yaml:"key,omitempty"`
+	Ttl       *int      `json:"ttl,omitempty" yaml:"ttl,omitempty"`
+	Consumer  *Id       `json:"consumer,omitempty" yaml:"consumer,omitempty"`
+	CreatedAt int       `json:"created_at,omitempty" yaml:"created_at,omitempty"`
+	Tags      []*string `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+type KeyAuths struct {
+	Results []*KeyAuth `json:"data" yaml:"data,omitempty"`
+	Next    *string    `json:"next" yaml:"next,omitempty"`
+	Offset  string     `json:"offset,omitempty" yaml:"offset,omitempty"`
+}
+
+const KeyAuthsPath = "/key-auths/"
+
+func (keyAuthClient *KeyAuthClient) Create(consumerUsernameOrId string, keyAuthRequest *KeyAuthRequest) (*KeyAuth, error) {
+	return keyAuthClient.CreateWithContext(context.Background(), consumerUsernameOrId, keyAuthRequest)
+}
+
+func (keyAuthClient *KeyAuthClient) CreateWithContext(ctx context.Context, consumerUsernameOrId string, keyAuthRequest *KeyAuthRequest) (*KeyAuth, error) {
+	keyAuth := &KeyAuth{}
+	if err := keyAuthClient.create(ctx, consumerUsernameOrId, keyAuthRequest, keyAuth); err != nil {
+		return nil, err
+	}
+
+	return keyAuth, nil
+}
+
+func (keyAuthClient *KeyAuthClient) GetById(consumerUsernameOrId string, id string) (*KeyAuth, error) {
+	return keyAuthClient.GetByIdWithContext(context.Background(), consumerUsernameOrId, id)
+}
+
+// GetByIdWithContext returns the key-auth credential of the consumer with the id or key, or nil when the consumer has
+// no such credential
+func (keyAuthClient *KeyAuthClient) GetByIdWithContext(ctx context.Context, consumerUsernameOrId string, id string) (*KeyAuth, error) {
+	keyAuth := &KeyAuth{}
+	found, err := keyAuthClient.getById(ctx, consumerUsernameOrId, id, keyAuth)
+	if err != nil || !found || keyAuth.Id == "" {
+		return nil, err
+	}
+
+	return keyAuth, nil
+}
+
+func (keyAuthClient *KeyAuthClient) GetConsumer(id string) (*Consumer, error) {
+	return keyAuthClient.GetConsumerWithContext(context.Background(), id)
+}
+
+// GetConsumerWithContext returns the consumer owning the key-auth credential with the id or key, or nil when there is
+// no such credential
+func (keyAuthClient *KeyAuthClient) GetConsumerWithContext(ctx context.Context, id string) (*Consumer, error) {
+	return keyAuthClient.consumer(ctx, id)
+}
+
+func (keyAuthClient *KeyAuthClient) List(query *CredentialQueryString) ([]*KeyAuth, error) {
+	return keyAuthClient.ListWithContext(context.Background(), query)
+}
+
+// ListWithContext returns the key-auth credentials of every consumer matching the query, following pagination
+func (keyAuthClient *KeyAuthClient) ListWithContext(ctx context.Context, query *CredentialQueryString) ([]*KeyAuth, error) {
+	keyAuths := make([]*KeyAuth, 0)
+	if err := keyAuthClient.list(ctx, "", query, &keyAuths); err != nil {
+		return nil, err
+	}
+
+	return keyAuths, nil
+}
+
+func (keyAuthClient *KeyAuthClient) ListFromConsumer(consumerUsernameOrId string, query *CredentialQueryString) ([]*KeyAuth, error) {
+	return keyAuthClient.ListFromConsumerWithContext(context.Background(), consumerUsernameOrId, query)
+}
+
+// ListFromConsumerWithContext returns the key-auth credentials of the consumer matching the query, following
+// pagination
+func (keyAuthClient *KeyAuthClient) ListFromConsumerWithContext(ctx context.Context, consumerUsernameOrId string, query *CredentialQueryString) ([]*KeyAuth, error) {
+	keyAuths := make([]*KeyAuth, 0)
+	if err := keyAuthClient.list(ctx, consumerUsernameOrId, query, &keyAuths); err != nil {
+		return nil, err
+	}
+
+	return keyAuths, nil
+}
+
+// Iter returns an iterator over the key-auth credentials of every consumer matching the query
+func (keyAuthClient *KeyAuthClient) Iter(ctx context.Context, query *CredentialQueryString) *KeyAuthIterator {
+	return &KeyAuthIterator{iterator: keyAuthClient.iter(ctx, "", query)}
+}
+
+// IterFromConsumer returns an iterator over the key-auth credentials of the consumer matching the query
+func (keyAuthClient *KeyAuthClient) IterFromConsumer(ctx context.Context, consumerUsernameOrId string, query *CredentialQueryString) *KeyAuthIterator {
+	return &KeyAuthIterator{iterator: keyAuthClient.iter(ctx, consumerUsernameOrId, query)}
+}
+
+type KeyAuthIterator struct {
+	iterator
+}
+
+// Value returns the key-auth credential the iterator is positioned on
+func (keyAuthIterator *KeyAuthIterator) Value() *KeyAuth {
+	keyAuth, _ := keyAuthIterator.value.(*KeyAuth)
+	return keyAuth
+}
+
+func (keyAuthClient *KeyAuthClient) UpdateById(consumerUsernameOrId string, id string, keyAuthRequest *KeyAuthRequest) (*KeyAuth, error) {
+	return keyAuthClient.UpdateByIdWithContext(context.Background(), consumerUsernameOrId, id, keyAuthRequest)
+}
+
+func (keyAuthClient *KeyAuthClient) UpdateByIdWithContext(ctx context.Context, consumerUsernameOrId string, id string, keyAuthRequest *KeyAuthRequest) (*KeyAuth, error) {
+	keyAuth := &KeyAuth{}
+	if err := keyAuthClient.update(ctx, consumerUsernameOrId, id, keyAuthRequest, keyAuth); err != nil {
+		return nil, err
+	}
+
+	return keyAuth, nil
+}
+
+func (keyAuthClient *KeyAuthClient) Upsert(consumerUsernameOrId string, id string, keyAuthRequest *KeyAuthRequest) (*KeyAuth, error) {
+	return keyAuthClient.UpsertWithContext(context.Background(), consumerUsernameOrId, id, keyAuthRequest)
+}
+
+// UpsertWithContext creates the key-auth credential of the consumer with the id or key or, when it exists, replaces
+// it in full with the request
+func (keyAuthClient *KeyAuthClient) UpsertWithContext(ctx context.Context, consumerUsernameOrId string, id string, keyAuthRequest *KeyAuthRequest) (*KeyAuth, error) {
+	keyAuth := &KeyAuth{}
+	if err := keyAuthClient.upsert(ctx, consumerUsernameOrId, id, keyAuthRequest, keyAuth); err != nil {
+		return nil, err
+	}
+
+	return keyAuth, nil
+}
+
+func (keyAuthClient *KeyAuthClient) DeleteById(consumerUsernameOrId string, id string) error {
+	return keyAuthClient.DeleteByIdWithContext(context.Background(), consumerUsernameOrId, id)
+}
+
+func (keyAuthClient *KeyAuthClient) DeleteByIdWithContext(ctx context.Context, consumerUsernameOrId string, id string) error {
+	return keyAuthClient.delete(ctx, consumerUsernameOrId, id)
+}
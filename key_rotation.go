@@ -0,0 +1,215 @@
+package gokong
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KeyExpiresTag prefixes the tag RotateKeys puts on the old keys it keeps for a grace period, the rest of the tag
+// is the unix time at which the key is revoked by RotateKeys or RevokeExpiredKeys
+const KeyExpiresTag = "gokong-key-expires:"
+
+// KeyRotation describes how RotateKeys replaces the key-auth keys of consumers
+type KeyRotation struct {
+	// GracePeriod keeps the old keys of a consumer valid for this long after the new key is issued, when it is zero
+	// the old keys are revoked straight away
+	GracePeriod time.Duration
+	// UseTtl expires the old keys by setting their ttl so kong removes them itself, otherwise the old keys are tagged
+	// with KeyExpiresTag and removed by a later RotateKeys or RevokeExpiredKeys
+	UseTtl bool
+	// Tags are set on every new key
+	Tags []*string
+}
+
+// KeyRotationReport records what was changed for each consumer, in the order the consumers were given
+type KeyRotationReport struct {
+	Consumers []*ConsumerKeyRotation `json:"consumers" yaml:"consumers"`
+}
+
+// ConsumerKeyRotation records the keys issued, retained and revoked for a consumer, Error is set when the rotation
+// of the consumer stopped part way through.  Expiring lists the old keys left as they were because they already
+// expire, either with a ttl or from the grace period of an earlier rotation
+type ConsumerKeyRotation struct {
+	Consumer string         `json:"consumer" yaml:"consumer"`
+	Issued   []string       `json:"issued,omitempty" yaml:"issued,omitempty"`
+	Retained []*RetainedKey `json:"retained,omitempty" yaml:"retained,omitempty"`
+	Expiring []*RetainedKey `json:"expiring,omitempty" yaml:"expiring,omitempty"`
+	Revoked  []string       `json:"revoked,omitempty" yaml:"revoked,omitempty"`
+	Error    string         `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// RetainedKey is an old key that stays valid until ExpiresAt
+type RetainedKey struct {
+	Id        string    `json:"id" yaml:"id"`
+	ExpiresAt time.Time `json:"expires_at" yaml:"expires_at"`
+}
+
+// Failed returns the rotations that stopped part way through
+func (keyRotationReport *KeyRotationReport) Failed() []*ConsumerKeyRotation {
+	failed := make([]*ConsumerKeyRotation, 0)
+	for _, consumerKeyRotation := range keyRotationReport.Consumers {
+		if consumerKeyRotation.Error != "" {
+			failed = append(failed, consumerKeyRotation)
+		}
+	}
+	return failed
+}
+
+func (keyRotationReport *KeyRotationReport) err(action string) error {
+	failed := keyRotationReport.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+
+	consumers := make([]string, 0, len(failed))
+	for _, consumerKeyRotation := range failed {
+		consumers = append(consumers, consumerKeyRotation.Consumer)
+	}
+	return fmt.Errorf("could not %s keys of consumers: %s", action, strings.Join(consumers, ", "))
+}
+
+func (consumerClient *ConsumerClient) RotateKeys(consumerUsernameOrIds []string, keyRotation *KeyRotation) (*KeyRotationReport, error) {
+	return consumerClient.RotateKeysWithContext(context.Background(), consumerUsernameOrIds, keyRotation)
+}
+
+// RotateKeysWithContext issues a new key-auth key to each consumer and then revokes its old keys, or keeps them for
+// the grace period.  Old keys kept by an earlier rotation are revoked once they expire and otherwise left alone, as
+// are keys with a ttl.  A consumer that fails does not stop the others, the report records every change made and
+// the error is non nil when any consumer failed
+func (consumerClient *ConsumerClient) RotateKeysWithContext(ctx context.Context, consumerUsernameOrIds []string, keyRotation *KeyRotation) (*KeyRotationReport, error) {
+	report := &KeyRotationReport{Consumers: make([]*ConsumerKeyRotation, 0, len(consumerUsernameOrIds))}
+	for _, consumerUsernameOrId := range consumerUsernameOrIds {
+		consumerKeyRotation := &ConsumerKeyRotation{Consumer: consumerUsernameOrId}
+		if err := consumerClient.rotateKeys(ctx, consumerKeyRotation, keyRotation); err != nil {
+			consumerKeyRotation.Error = err.Error()
+		}
+		report.Consumers = append(report.Consumers, consumerKeyRotation)
+	}
+
+	return report, report.err("rotate")
+}
+
+func (consumerClient *ConsumerClient) rotateKeys(ctx context.Context, consumerKeyRotation *ConsumerKeyRotation, keyRotation *KeyRotation) error {
+	keyAuths := consumerClient.keyAuths()
+
+	existing, err := keyAuths.ListFromConsumerWithContext(ctx, consumerKeyRotation.Consumer, &CredentialQueryString{})
+	if err != nil {
+		return err
+	}
+
+	issued, err := keyAuths.CreateWithContext(ctx, consumerKeyRotation.Consumer, &KeyAuthRequest{Tags: keyRotation.Tags})
+	if err != nil {
+		return err
+	}
+	consumerKeyRotation.Issued = append(consumerKeyRotation.Issued, issued.Id)
+
+	now := clock()
+	expiresAt := now.Add(keyRotation.GracePeriod)
+	for _, keyAuth := range existing {
+		if retainedUntil, ok := keyExpiry(keyAuth); ok {
+			if retainedUntil.After(now) {
+				consumerKeyRotation.Expiring = append(consumerKeyRotation.Expiring, &RetainedKey{Id: keyAuth.Id, ExpiresAt: retainedUntil})
+				continue
+			}
+		} else if keyAuth.Ttl != nil {
+			ttlExpiresAt := now.Add(time.Duration(*keyAuth.Ttl) * time.Second)
+			consumerKeyRotation.Expiring = append(consumerKeyRotation.Expiring, &RetainedKey{Id: keyAuth.Id, ExpiresAt: ttlExpiresAt})
+			continue
+		} else if keyRotation.GracePeriod > 0 {
+			if err := consumerClient.retainKey(ctx, consumerKeyRotation.Consumer, keyAuth, keyRotation, expiresAt); err != nil {
+				return err
+			}
+			consumerKeyRotation.Retained = append(consumerKeyRotation.Retained, &RetainedKey{Id: keyAuth.Id, ExpiresAt: expiresAt})
+			continue
+		}
+
+		if err := keyAuths.DeleteByIdWithContext(ctx, consumerKeyRotation.Consumer, keyAuth.Id); err != nil {
+			return err
+		}
+		consumerKeyRotation.Revoked = append(consumerKeyRotation.Revoked, keyAuth.Id)
+	}
+
+	return nil
+}
+
+// retainKey keeps the key valid until expiresAt, either with a ttl or by tagging it with its expiry
+func (consumerClient *ConsumerClient) retainKey(ctx context.Context, consumerUsernameOrId string, keyAuth *KeyAuth, keyRotation *KeyRotation, expiresAt time.Time) error {
+	keyAuthRequest := &KeyAuthRequest{}
+	if keyRotation.UseTtl {
+		keyAuthRequest.Ttl = Int(ttlSeconds(keyRotation.GracePeriod))
+	} else {
+		keyAuthRequest.Tags = append(append([]*string{}, keyAuth.Tags...), String(KeyExpiresTag+strconv.FormatInt(expiresAt.Unix(), 10)))
+	}
+
+	_, err := consumerClient.keyAuths().UpdateByIdWithContext(ctx, consumerUsernameOrId, keyAuth.Id, keyAuthRequest)
+	return err
+}
+
+// ttlSeconds returns the grace period in whole seconds for a ttl, rounding up as kong never expires a key with a
+// ttl of 0
+func ttlSeconds(gracePeriod time.Duration) int {
+	return int((gracePeriod + time.Second - 1) / time.Second)
+}
+
+func (consumerClient *ConsumerClient) RevokeExpiredKeys(consumerUsernameOrIds []string) (*KeyRotationReport, error) {
+	return consumerClient.RevokeExpiredKeysWithContext(context.Background(), consumerUsernameOrIds)
+}
+
+// RevokeExpiredKeysWithContext revokes the keys of each consumer whose grace period, given by KeyExpiresTag, has
+// passed without issuing new keys
+func (consumerClient *ConsumerClient) RevokeExpiredKeysWithContext(ctx context.Context, consumerUsernameOrIds []string) (*KeyRotationReport, error) {
+	report := &KeyRotationReport{Consumers: make([]*ConsumerKeyRotation, 0, len(consumerUsernameOrIds))}
+	for _, consumerUsernameOrId := range consumerUsernameOrIds {
+		consumerKeyRotation := &ConsumerKeyRotation{Consumer: consumerUsernameOrId}
+		if err := consumerClient.revokeExpiredKeys(ctx, consumerKeyRotation); err != nil {
+			consumerKeyRotation.Error = err.Error()
+		}
+		report.Consumers = append(report.Consumers, consumerKeyRotation)
+	}
+
+	return report, report.err("revoke")
+}
+
+func (consumerClient *ConsumerClient) revokeExpiredKeys(ctx context.Context, consumerKeyRotation *ConsumerKeyRotation) error {
+	keyAuths := consumerClient.keyAuths()
+
+	existing, err := keyAuths.ListFromConsumerWithContext(ctx, consumerKeyRotation.Consumer, &CredentialQueryString{})
+	if err != nil {
+		return err
+	}
+
+	now := clock()
+	for _, keyAuth := range existing {
+		if expiresAt, ok := keyExpiry(keyAuth); !ok || expiresAt.After(now) {
+			continue
+		}
+
+		if err := keyAuths.DeleteByIdWithContext(ctx, consumerKeyRotation.Consumer, keyAuth.Id); err != nil {
+			return err
+		}
+		consumerKeyRotation.Revoked = append(consumerKeyRotation.Revoked, keyAuth.Id)
+	}
+
+	return nil
+}
+
+func (consumerClient *ConsumerClient) keyAuths() *KeyAuthClient {
+	return (&KongAdminClient{config: consumerClient.config}).KeyAuths()
+}
+
+// keyExpiry returns the time the key is revoked at when it was kept by a rotation
+func keyExpiry(keyAuth *KeyAuth) (time.Time, bool) {
+	for _, tag := range StringValueSlice(keyAuth.Tags) {
+		if !strings.HasPrefix(tag, KeyExpiresTag) {
+			continue
+		}
+		unix, err := strconv.ParseInt(strings.TrimPrefix(tag, KeyExpiresTag), 10, 64)
+		if err == nil {
+			return time.Unix(unix, 0), true
+		}
+	}
+	return time.Time{}, false
+}
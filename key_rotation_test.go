@@ -0,0 +1,133 @@
+package gokong
+
+import (
+	"testing"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RotateKeysRevokesOldKeysWithoutGracePeriod(t *testing.T) {
+	client := NewClient(NewDefaultConfig())
+	consumer := createCredentialConsumer(t, client)
+
+	old, err := client.KeyAuths().Create(consumer.Id, &KeyAuthRequest{})
+	assert.Nil(t, err)
+
+	report, err := client.Consumers().RotateKeys([]string{consumer.Username}, &KeyRotation{Tags: StringSlice([]string{"q3"})})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(report.Consumers))
+	assert.Equal(t, consumer.Username, report.Consumers[0].Consumer)
+	assert.Equal(t, []string{old.Id}, report.Consumers[0].Revoked)
+	assert.Equal(t, 0, len(report.Consumers[0].Retained))
+
+	keyAuths, err := client.KeyAuths().ListFromConsumer(consumer.Id, &CredentialQueryString{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(keyAuths))
+	assert.Equal(t, report.Consumers[0].Issued, []string{keyAuths[0].Id})
+	assert.Equal(t, StringSlice([]string{"q3"}), keyAuths[0].Tags)
+}
+
+func Test_RotateKeysKeepsOldKeysForTheGracePeriod(t *testing.T) {
+	defer func() { clock = time.Now }()
+
+	client := NewClient(NewDefaultConfig())
+	consumer := createCredentialConsumer(t, client)
+
+	old, err := client.KeyAuths().Create(consumer.Id, &KeyAuthRequest{})
+	assert.Nil(t, err)
+
+	now := time.Now()
+	clock = func() time.Time { return now }
+
+	report, err := client.Consumers().RotateKeys([]string{consumer.Id}, &KeyRotation{GracePeriod: 24 * time.Hour})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(report.Consumers[0].Retained))
+	assert.Equal(t, old.Id, report.Consumers[0].Retained[0].Id)
+	assert.Equal(t, now.Add(24*time.Hour), report.Consumers[0].Retained[0].ExpiresAt)
+
+	retained, err := client.KeyAuths().GetById(consumer.Id, old.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, old.Key, retained.Key)
+
+	report, err = client.Consumers().RevokeExpiredKeys([]string{consumer.Id})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(report.Consumers[0].Revoked))
+
+	clock = func() time.Time { return now.Add(25 * time.Hour) }
+
+	report, err = client.Consumers().RevokeExpiredKeys([]string{consumer.Id})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{old.Id}, report.Consumers[0].Revoked)
+
+	keyAuths, err := client.KeyAuths().ListFromConsumer(consumer.Id, &CredentialQueryString{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(keyAuths))
+}
+
+func Test_RotateKeysCanExpireOldKeysWithATtl(t *testing.T) {
+	client := NewClient(NewDefaultConfig())
+	consumer := createCredentialConsumer(t, client)
+
+	old, err := client.KeyAuths().Create(consumer.Id, &KeyAuthRequest{})
+	assert.Nil(t, err)
+
+	_, err = client.Consumers().RotateKeys([]string{consumer.Id}, &KeyRotation{GracePeriod: time.Hour, UseTtl: true})
+	assert.Nil(t, err)
+
+	retained, err := client.KeyAuths().GetById(consumer.Id, old.Id)
+	assert.Nil(t, err)
+	assert.NotNil(t, retained.Ttl)
+
+	report, err := client.Consumers().RotateKeys([]string{consumer.Id}, &KeyRotation{GracePeriod: time.Hour, UseTtl: true})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(report.Consumers[0].Retained))
+	assert.NotEqual(t, old.Id, report.Consumers[0].Retained[0].Id)
+	assert.Equal(t, 1, len(report.Consumers[0].Expiring))
+	assert.Equal(t, old.Id, report.Consumers[0].Expiring[0].Id)
+	assert.False(t, report.Consumers[0].Expiring[0].ExpiresAt.After(time.Now().Add(time.Hour)))
+}
+
+func Test_RotateKeysReportsKeysExpiringFromAnEarlierGracePeriod(t *testing.T) {
+	defer func() { clock = time.Now }()
+
+	client := NewClient(NewDefaultConfig())
+	consumer := createCredentialConsumer(t, client)
+
+	old, err := client.KeyAuths().Create(consumer.Id, &KeyAuthRequest{})
+	assert.Nil(t, err)
+
+	now := time.Now().Truncate(time.Second)
+	clock = func() time.Time { return now }
+
+	report, err := client.Consumers().RotateKeys([]string{consumer.Id}, &KeyRotation{GracePeriod: 24 * time.Hour})
+	assert.Nil(t, err)
+	issued := report.Consumers[0].Issued[0]
+
+	report, err = client.Consumers().RotateKeys([]string{consumer.Id}, &KeyRotation{GracePeriod: 24 * time.Hour})
+	assert.Nil(t, err)
+	assert.Equal(t, []*RetainedKey{{Id: issued, ExpiresAt: now.Add(24 * time.Hour)}}, report.Consumers[0].Retained)
+	assert.Equal(t, []*RetainedKey{{Id: old.Id, ExpiresAt: now.Add(24 * time.Hour)}}, report.Consumers[0].Expiring)
+}
+
+func Test_TtlSecondsRoundsUpToAWholeSecond(t *testing.T) {
+	assert.Equal(t, 1, ttlSeconds(time.Millisecond))
+	assert.Equal(t, 1, ttlSeconds(time.Second))
+	assert.Equal(t, 2, ttlSeconds(1500*time.Millisecond))
+	assert.Equal(t, 3600, ttlSeconds(time.Hour))
+}
+
+func Test_RotateKeysReportsConsumersThatFailed(t *testing.T) {
+	client := NewClient(NewDefaultConfig())
+	consumer := createCredentialConsumer(t, client)
+	missing := "username-" + uuid.NewV4().String()
+
+	report, err := client.Consumers().RotateKeys([]string{missing, consumer.Id}, &KeyRotation{})
+
+	assert.Equal(t, "could not rotate keys of consumers: "+missing, err.Error())
+	assert.Equal(t, 2, len(report.Consumers))
+	assert.Equal(t, []*ConsumerKeyRotation{report.Consumers[0]}, report.Failed())
+	assert.NotEqual(t, "", report.Consumers[0].Error)
+	assert.Equal(t, 1, len(report.Consumers[1].Issued))
+}
@@ -0,0 +1,77 @@
+package kongtest
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// apiError is an error response with the body kong returns for it
+type apiError struct {
+	status int
+	body   map[string]interface{}
+}
+
+func errorBody(status int, code int, name string, text string, fields map[string]interface{}) (int, interface{}) {
+	err := newApiError(status, code, name, text, fields)
+	return err.status, err.body
+}
+
+func newApiError(status int, code int, name string, text string, fields map[string]interface{}) *apiError {
+	body := map[string]interface{}{"code": code, "name": name, "message": text}
+	if fields != nil {
+		body["fields"] = fields
+	}
+	return &apiError{status: status, body: body}
+}
+
+func message(status int, text string) (int, interface{}) {
+	return status, map[string]interface{}{"message": text}
+}
+
+func notFound() (int, interface{}) {
+	return message(http.StatusNotFound, "Not found")
+}
+
+func schemaViolation(fields map[string]interface{}) (int, interface{}) {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	violations := make([]string, 0, len(names))
+	for _, name := range names {
+		violation := fields[name]
+		if messages, ok := violation.([]string); ok {
+			violation = strings.Join(messages, ", ")
+		}
+		violations = append(violations, fmt.Sprintf("%s: %v", name, violation))
+	}
+
+	text := fmt.Sprintf("schema violation (%s)", strings.Join(violations, "; "))
+	if len(violations) > 1 {
+		text = fmt.Sprintf("%d schema violations (%s)", len(violations), strings.Join(violations, "; "))
+	}
+
+	return errorBody(http.StatusBadRequest, 2, "schema violation", text, fields)
+}
+
+func uniqueViolation(entity map[string]interface{}, fields []string) *apiError {
+	values := map[string]interface{}{}
+	pairs := []string{}
+	for _, name := range fields {
+		values[name] = entity[name]
+		pairs = append(pairs, fmt.Sprintf("%s=%q", name, fmt.Sprint(entity[name])))
+	}
+
+	return newApiError(http.StatusConflict, 5, "unique constraint violation",
+		fmt.Sprintf("UNIQUE violation detected on '{%s}'", strings.Join(pairs, ",")), values)
+}
+
+func foreignKeyViolation(field string, collection string, id string) *apiError {
+	return newApiError(http.StatusBadRequest, 4, "foreign key violation",
+		fmt.Sprintf("the foreign key '{id=\"%s\"}' does not reference an existing '%s' entity.", id, collection),
+		map[string]interface{}{field: map[string]interface{}{"id": id}})
+}
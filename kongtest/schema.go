@@ -0,0 +1,638 @@
+package kongtest
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type fieldType int
+
+const (
+	typeString fieldType = iota
+	typeInteger
+	typeBoolean
+	typeStrings
+	typeIntegers
+	typeRecord
+	typeForeign
+	typeIpPorts
+	typeHeaders
+)
+
+// field describes a single field of an entity the way kong's schemas do
+type field struct {
+	typ      fieldType
+	required bool
+	def      func() interface{}
+	oneOf    []string
+	min, max int
+	bounded  bool
+	foreign  string
+}
+
+// schema describes an entity collection, its fields, the fields that can be used in place of the id in a url and the
+// fields that must be unique (together) across the collection
+type schema struct {
+	name         string
+	fields       map[string]*field
+	endpointKeys []string
+	unique       [][]string
+	transient    []string
+	updatedAt    bool
+	check        func(entity map[string]interface{}) []string
+}
+
+func value(v interface{}) func() interface{} {
+	return func() interface{} { return copyValue(v) }
+}
+
+func between(typ fieldType, min int, max int) *field {
+	return &field{typ: typ, min: min, max: max, bounded: true}
+}
+
+func withDefault(f *field, v interface{}) *field {
+	f.def = value(v)
+	return f
+}
+
+func oneOf(typ fieldType, values ...string) *field {
+	return &field{typ: typ, oneOf: values}
+}
+
+func required(f *field) *field {
+	f.required = true
+	return f
+}
+
+func foreign(collection string) *field {
+	return &field{typ: typeForeign, foreign: collection}
+}
+
+var protocols = []string{"grpc", "grpcs", "http", "https", "tcp", "tls", "udp"}
+
+var bundledPlugins = []string{
+	"acl", "acme", "aws-lambda", "azure-functions", "basic-auth", "bot-detection", "correlation-id", "cors", "datadog",
+	"file-log", "grpc-gateway", "grpc-web", "hmac-auth", "http-log", "ip-restriction", "jwt", "key-auth", "ldap-auth",
+	"loggly", "oauth2", "post-function", "pre-function", "prometheus", "proxy-cache", "rate-limiting",
+	"request-size-limiting", "request-termination", "request-transformer", "response-ratelimiting",
+	"response-transformer", "session", "statsd", "syslog", "tcp-log", "udp-log", "zipkin",
+}
+
+func healthchecksDefault() interface{} {
+	return map[string]interface{}{
+		"threshold": 0,
+		"active": map[string]interface{}{
+			"type":                     "http",
+			"timeout":                  1,
+			"concurrency":              10,
+			"http_path":                "/",
+			"https_sni":                nil,
+			"https_verify_certificate": true,
+			"healthy": map[string]interface{}{
+				"interval":      0,
+				"successes":     0,
+				"http_statuses": []interface{}{200, 302},
+			},
+			"unhealthy": map[string]interface{}{
+				"interval":      0,
+				"http_failures": 0,
+				"tcp_failures":  0,
+				"timeouts":      0,
+				"http_statuses": []interface{}{429, 404, 500, 501, 502, 503, 504, 505},
+			},
+		},
+		"passive": map[string]interface{}{
+			"type": "http",
+			"healthy": map[string]interface{}{
+				"successes":     0,
+				"http_statuses": []interface{}{200, 201, 202, 203, 204, 205, 206, 207, 208, 226, 300, 301, 302, 303, 304, 305, 306, 307, 308},
+			},
+			"unhealthy": map[string]interface{}{
+				"http_failures": 0,
+				"tcp_failures":  0,
+				"timeouts":      0,
+				"http_statuses": []interface{}{429, 500, 503},
+			},
+		},
+	}
+}
+
+var schemas = map[string]*schema{
+	"services": {
+		name: "services",
+		fields: map[string]*field{
+			"name":               {typ: typeString},
+			"protocol":           withDefault(oneOf(typeString, protocols...), "http"),
+			"host":               required(&field{typ: typeString}),
+			"port":               withDefault(between(typeInteger, 0, 65535), 80),
+			"path":               {typ: typeString},
+			"retries":            withDefault(between(typeInteger, 0, 32767), 5),
+			"connect_timeout":    withDefault(between(typeInteger, 1, 2147483646), 60000),
+			"write_timeout":      withDefault(between(typeInteger, 1, 2147483646), 60000),
+			"read_timeout":       withDefault(between(typeInteger, 1, 2147483646), 60000),
+			"tls_verify":         {typ: typeBoolean},
+			"tls_verify_depth":   between(typeInteger, 0, 64),
+			"ca_certificates":    {typ: typeStrings},
+			"client_certificate": foreign("certificates"),
+			"enabled":            withDefault(&field{typ: typeBoolean}, true),
+		},
+		endpointKeys: []string{"name"},
+		unique:       [][]string{{"name"}},
+		updatedAt:    true,
+		check:        checkService,
+	},
+	"routes": {
+		name: "routes",
+		fields: map[string]*field{
+			"name":                       {typ: typeString},
+			"protocols":                  withDefault(oneOf(typeStrings, protocols...), []interface{}{"http", "https"}),
+			"methods":                    {typ: typeStrings},
+			"hosts":                      {typ: typeStrings},
+			"paths":                      {typ: typeStrings},
+			"headers":                    {typ: typeHeaders},
+			"https_redirect_status_code": withDefault(oneOf(typeInteger, "426", "301", "302", "307", "308"), 426),
+			"regex_priority":             withDefault(&field{typ: typeInteger}, 0),
+			"strip_path":                 withDefault(&field{typ: typeBoolean}, true),
+			"path_handling":              withDefault(oneOf(typeString, "v0", "v1"), "v0"),
+			"preserve_host":              withDefault(&field{typ: typeBoolean}, false),
+			"request_buffering":          withDefault(&field{typ: typeBoolean}, true),
+			"response_buffering":         withDefault(&field{typ: typeBoolean}, true),
+			"snis":                       {typ: typeStrings},
+			"sources":                    {typ: typeIpPorts},
+			"destinations":               {typ: typeIpPorts},
+			"service":                    foreign("services"),
+		},
+		endpointKeys: []string{"name"},
+		unique:       [][]string{{"name"}},
+		updatedAt:    true,
+		check:        checkRoute,
+	},
+	"consumers": {
+		name: "consumers",
+		fields: map[string]*field{
+			"username":  {typ: typeString},
+			"custom_id": {typ: typeString},
+		},
+		endpointKeys: []string{"username"},
+		unique:       [][]string{{"username"}, {"custom_id"}},
+		check:        checkConsumer,
+	},
+	"plugins": {
+		name: "plugins",
+		fields: map[string]*field{
+			"name":      required(oneOf(typeString, bundledPlugins...)),
+			"service":   foreign("services"),
+			"route":     foreign("routes"),
+			"consumer":  foreign("consumers"),
+			"config":    withDefault(&field{typ: typeRecord}, map[string]interface{}{}),
+			"enabled":   withDefault(&field{typ: typeBoolean}, true),
+			"protocols": withDefault(oneOf(typeStrings, protocols...), []interface{}{"grpc", "grpcs", "http", "https"}),
+			"run_on":    oneOf(typeString, "first", "second", "all"),
+		},
+		unique: [][]string{{"name", "service", "route", "consumer"}},
+	},
+	"upstreams": {
+		name: "upstreams",
+		fields: map[string]*field{
+			"name":                 required(&field{typ: typeString}),
+			"algorithm":            withDefault(oneOf(typeString, "consistent-hashing", "least-connections", "round-robin"), "round-robin"),
+			"slots":                withDefault(between(typeInteger, 10, 65536), 10000),
+			"hash_on":              withDefault(oneOf(typeString, "none", "consumer", "ip", "header", "cookie"), "none"),
+			"hash_fallback":        withDefault(oneOf(typeString, "none", "consumer", "ip", "header", "cookie"), "none"),
+			"hash_on_header":       {typ: typeString},
+			"hash_fallback_header": {typ: typeString},
+			"hash_on_cookie":       {typ: typeString},
+			"hash_on_cookie_path":  withDefault(&field{typ: typeString}, "/"),
+			"host_header":          {typ: typeString},
+			"client_certificate":   foreign("certificates"),
+			"healthchecks":         {typ: typeRecord, def: healthchecksDefault},
+		},
+		endpointKeys: []string{"name"},
+		unique:       [][]string{{"name"}},
+		check:        checkUpstream,
+	},
+	"targets": {
+		name: "targets",
+		fields: map[string]*field{
+			"target":   required(&field{typ: typeString}),
+			"weight":   withDefault(between(typeInteger, 0, 65535), 100),
+			"upstream": required(foreign("upstreams")),
+		},
+		unique: [][]string{{"upstream", "target"}},
+		check:  checkTarget,
+	},
+	"certificates": {
+		name: "certificates",
+		fields: map[string]*field{
+			"cert": required(&field{typ: typeString}),
+			"key":  required(&field{typ: typeString}),
+			"snis": {typ: typeStrings},
+		},
+		transient: []string{"snis"},
+		check:     checkCertificate,
+	},
+	"ca_certificates": {
+		name: "ca_certificates",
+		fields: map[string]*field{
+			"cert":        required(&field{typ: typeString}),
+			"cert_digest": {typ: typeString},
+		},
+		unique: [][]string{{"cert_digest"}},
+		check:  checkCACertificate,
+	},
+	"snis": {
+		name: "snis",
+		fields: map[string]*field{
+			"name":        required(&field{typ: typeString}),
+			"certificate": required(foreign("certificates")),
+		},
+		endpointKeys: []string{"name"},
+		unique:       [][]string{{"name"}},
+	},
+	"key-auths": {
+		name: "key-auths",
+		fields: map[string]*field{
+			"key":      {typ: typeString, def: randomKey},
+			"ttl":      between(typeInteger, 0, 100000000),
+			"consumer": required(foreign("consumers")),
+		},
+		endpointKeys: []string{"key"},
+		unique:       [][]string{{"key"}},
+	},
+	"basic-auths": {
+		name: "basic-auths",
+		fields: map[string]*field{
+			"username": required(&field{typ: typeString}),
+			"password": required(&field{typ: typeString}),
+			"consumer": required(foreign("consumers")),
+		},
+		endpointKeys: []string{"username"},
+		unique:       [][]string{{"username"}},
+	},
+	"jwts": {
+		name: "jwts",
+		fields: map[string]*field{
+			"key":            {typ: typeString, def: randomKey},
+			"secret":         {typ: typeString, def: randomKey},
+			"algorithm":      withDefault(oneOf(typeString, "HS256", "HS384", "HS512", "RS256", "RS512", "ES256"), "HS256"),
+			"rsa_public_key": {typ: typeString},
+			"consumer":       required(foreign("consumers")),
+		},
+		endpointKeys: []string{"key"},
+		unique:       [][]string{{"key"}},
+	},
+	"hmac-auths": {
+		name: "hmac-auths",
+		fields: map[string]*field{
+			"username": required(&field{typ: typeString}),
+			"secret":   {typ: typeString, def: randomKey},
+			"consumer": required(foreign("consumers")),
+		},
+		endpointKeys: []string{"username"},
+		unique:       [][]string{{"username"}},
+	},
+	"oauth2": {
+		name: "oauth2",
+		fields: map[string]*field{
+			"name":          required(&field{typ: typeString}),
+			"client_id":     {typ: typeString, def: randomKey},
+			"client_secret": {typ: typeString, def: randomKey},
+			"redirect_uris": {typ: typeStrings},
+			"consumer":      required(foreign("consumers")),
+		},
+		endpointKeys: []string{"client_id"},
+		unique:       [][]string{{"client_id"}},
+	},
+	"acls": {
+		name: "acls",
+		fields: map[string]*field{
+			"group":    required(&field{typ: typeString}),
+			"consumer": required(foreign("consumers")),
+		},
+		unique: [][]string{{"consumer", "group"}},
+	},
+}
+
+// credentials maps the path beneath a consumer at which each type of credential is managed to the collection
+// holding the credentials
+var credentials = map[string]string{
+	"key-auth":   "key-auths",
+	"basic-auth": "basic-auths",
+	"jwt":        "jwts",
+	"hmac-auth":  "hmac-auths",
+	"oauth2":     "oauth2",
+	"acls":       "acls",
+}
+
+func randomKey() interface{} {
+	return strings.Replace(newId(), "-", "", -1)
+}
+
+// apply validates the fields of body and copies them onto entity, fields that are missing from entity are given
+// their default.  A null in body clears the field.  It returns kong's field errors keyed by field name.
+func (schema *schema) apply(entity map[string]interface{}, body map[string]interface{}) map[string]interface{} {
+	errs := map[string]interface{}{}
+
+	for name, v := range body {
+		if name == "id" || name == "created_at" || name == "updated_at" {
+			continue
+		}
+
+		f, ok := schema.fields[name]
+		if name == "tags" {
+			f, ok = &field{typ: typeStrings}, true
+		}
+		if !ok {
+			errs[name] = "unknown field"
+			continue
+		}
+
+		if v == nil {
+			delete(entity, name)
+			continue
+		}
+
+		if err := f.validate(v); err != "" {
+			errs[name] = err
+			continue
+		}
+
+		if f.typ == typeRecord {
+			if current, ok := entity[name].(map[string]interface{}); ok {
+				v = merge(current, v.(map[string]interface{}))
+			}
+		}
+		entity[name] = copyValue(v)
+	}
+
+	for name, f := range schema.fields {
+		if _, ok := entity[name]; !ok && f.def != nil {
+			entity[name] = f.def()
+		} else if ok && f.def != nil && f.typ == typeRecord {
+			entity[name] = merge(f.def().(map[string]interface{}), entity[name].(map[string]interface{}))
+		}
+	}
+
+	for name, f := range schema.fields {
+		if _, invalid := errs[name]; invalid {
+			continue
+		}
+		if _, ok := entity[name]; !ok && f.required {
+			errs[name] = "required field missing"
+		}
+	}
+
+	if len(errs) == 0 && schema.check != nil {
+		if entityErrs := schema.check(entity); len(entityErrs) > 0 {
+			errs["@entity"] = entityErrs
+		}
+	}
+
+	return errs
+}
+
+func (f *field) validate(v interface{}) string {
+	switch f.typ {
+	case typeString:
+		s, ok := v.(string)
+		if !ok {
+			return "expected a string"
+		}
+		if len(f.oneOf) > 0 && !contains(f.oneOf, s) {
+			return "expected one of: " + strings.Join(f.oneOf, ", ")
+		}
+	case typeInteger:
+		n, ok := v.(float64)
+		if !ok || n != float64(int(n)) {
+			return "expected an integer"
+		}
+		if len(f.oneOf) > 0 && !contains(f.oneOf, strconv.Itoa(int(n))) {
+			return "expected one of: " + strings.Join(f.oneOf, ", ")
+		}
+		if f.bounded && (int(n) < f.min || int(n) > f.max) {
+			return fmt.Sprintf("value should be between %d and %d", f.min, f.max)
+		}
+	case typeBoolean:
+		if _, ok := v.(bool); !ok {
+			return "expected a boolean"
+		}
+	case typeStrings:
+		items, ok := v.([]interface{})
+		if !ok {
+			return "expected a set"
+		}
+		for _, item := range items {
+			s, ok := item.(string)
+			if !ok {
+				return "expected a string"
+			}
+			if len(f.oneOf) > 0 && !contains(f.oneOf, s) {
+				return "expected one of: " + strings.Join(f.oneOf, ", ")
+			}
+		}
+	case typeRecord:
+		if _, ok := v.(map[string]interface{}); !ok {
+			return "expected a record"
+		}
+	case typeForeign:
+		reference, ok := v.(map[string]interface{})
+		if !ok {
+			return "expected a record"
+		}
+		if _, ok := reference["id"].(string); !ok {
+			return "missing primary key"
+		}
+	case typeIpPorts:
+		items, ok := v.([]interface{})
+		if !ok {
+			return "expected a set"
+		}
+		for _, item := range items {
+			ipPort, ok := item.(map[string]interface{})
+			if !ok {
+				return "expected a record"
+			}
+			if ip, ok := ipPort["ip"].(string); ok && net.ParseIP(strings.Split(ip, "/")[0]) == nil {
+				return "invalid ip or cidr range: '" + ip + "'"
+			}
+		}
+	case typeHeaders:
+		headers, ok := v.(map[string]interface{})
+		if !ok {
+			return "expected a map"
+		}
+		for _, values := range headers {
+			if _, ok := values.([]interface{}); !ok {
+				return "expected an array"
+			}
+		}
+	}
+	return ""
+}
+
+func checkService(entity map[string]interface{}) []string {
+	if path, ok := entity["path"].(string); ok && !strings.HasPrefix(path, "/") {
+		return []string{"path should start with: /"}
+	}
+	if protocol := entity["protocol"]; protocol != "https" && protocol != "grpcs" && protocol != "tls" {
+		for _, name := range []string{"client_certificate", "tls_verify", "tls_verify_depth", "ca_certificates"} {
+			if !isEmpty(entity[name]) {
+				return []string{"failed conditional validation given value of field 'protocol'"}
+			}
+		}
+	}
+	return nil
+}
+
+func checkRoute(entity map[string]interface{}) []string {
+	routeProtocols := strings.Join(stringsOf(entity["protocols"]), ",")
+	has := func(names ...string) bool {
+		for _, name := range names {
+			if v, ok := entity[name]; ok && !isEmpty(v) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case strings.Contains(routeProtocols, "http") && !has("methods", "hosts", "headers", "paths", "snis"):
+		return []string{"must set one of 'methods', 'hosts', 'headers', 'paths' when 'protocols' is 'http'"}
+	case (strings.Contains(routeProtocols, "tcp") || strings.Contains(routeProtocols, "tls") || strings.Contains(routeProtocols, "udp")) &&
+		!has("sources", "destinations", "snis"):
+		return []string{"must set one of 'sources', 'destinations', 'snis' when 'protocols' is 'tcp', 'tls' or 'udp'"}
+	case strings.Contains(routeProtocols, "grpc") && !has("hosts", "headers", "paths", "snis"):
+		return []string{"must set one of 'hosts', 'headers', 'paths' when 'protocols' is 'grpc'"}
+	}
+	return nil
+}
+
+func checkConsumer(entity map[string]interface{}) []string {
+	if isEmpty(entity["username"]) && isEmpty(entity["custom_id"]) {
+		return []string{"at least one of these fields must be non-empty: 'custom_id', 'username'"}
+	}
+	return nil
+}
+
+func checkUpstream(entity map[string]interface{}) []string {
+	errs := []string{}
+	if entity["hash_on"] == "header" && isEmpty(entity["hash_on_header"]) {
+		errs = append(errs, "failed conditional validation given value of field 'hash_on'")
+	}
+	if entity["hash_on"] == "cookie" && isEmpty(entity["hash_on_cookie"]) {
+		errs = append(errs, "failed conditional validation given value of field 'hash_on'")
+	}
+	if entity["hash_on"] == "none" && entity["hash_fallback"] != "none" {
+		errs = append(errs, "failed conditional validation given value of field 'hash_on'")
+	}
+	return errs
+}
+
+func checkTarget(entity map[string]interface{}) []string {
+	target := entity["target"].(string)
+	if _, _, err := net.SplitHostPort(target); err != nil {
+		entity["target"] = net.JoinHostPort(strings.Trim(target, "[]"), "8000")
+	}
+	return nil
+}
+
+func checkCertificate(entity map[string]interface{}) []string {
+	errs := []string{}
+	if block, _ := pem.Decode([]byte(entity["cert"].(string))); block == nil {
+		errs = append(errs, "invalid certificate: unable to load certificate")
+	}
+	if block, _ := pem.Decode([]byte(entity["key"].(string))); block == nil {
+		errs = append(errs, "invalid key: unable to load private key")
+	}
+	return errs
+}
+
+func checkCACertificate(entity map[string]interface{}) []string {
+	block, _ := pem.Decode([]byte(entity["cert"].(string)))
+	if block == nil {
+		return []string{"invalid certificate: unable to load certificate"}
+	}
+	if certificate, err := x509.ParseCertificate(block.Bytes); err != nil || !certificate.IsCA {
+		return []string{`certificate does not appear to be a CA because it is missing the "CA" basic constraint`}
+	}
+	digest := sha256.Sum256(block.Bytes)
+	entity["cert_digest"] = hex.EncodeToString(digest[:])
+	return nil
+}
+
+// merge returns a copy of current with the fields of update merged in, records are merged recursively
+func merge(current map[string]interface{}, update map[string]interface{}) map[string]interface{} {
+	merged := copyValue(current).(map[string]interface{})
+	for k, v := range update {
+		currentRecord, currentIsRecord := merged[k].(map[string]interface{})
+		updateRecord, updateIsRecord := v.(map[string]interface{})
+		if currentIsRecord && updateIsRecord {
+			merged[k] = merge(currentRecord, updateRecord)
+			continue
+		}
+		merged[k] = copyValue(v)
+	}
+	return merged
+}
+
+func copyValue(v interface{}) interface{} {
+	switch typed := v.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(typed))
+		for k, item := range typed {
+			copied[k] = copyValue(item)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(typed))
+		for i, item := range typed {
+			copied[i] = copyValue(item)
+		}
+		return copied
+	case int:
+		return float64(typed)
+	}
+	return v
+}
+
+func isEmpty(v interface{}) bool {
+	switch typed := v.(type) {
+	case nil:
+		return true
+	case string:
+		return typed == ""
+	case []interface{}:
+		return len(typed) == 0
+	case map[string]interface{}:
+		return len(typed) == 0
+	}
+	return false
+}
+
+func stringsOf(v interface{}) []string {
+	result := []string{}
+	items, _ := v.([]interface{})
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func contains(values []string, v string) bool {
+	for _, candidate := range values {
+		if candidate == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,484 @@
+// Package kongtest provides an in-memory fake of the kong admin api for tests that cannot run kong itself.
+//
+// The fake implements services, routes, plugins, consumers and their credentials, upstreams, targets, certificates,
+// ca certificates, SNIs and status with kong's defaults, validation, uniqueness and foreign key checks, pagination and error bodies:
+//
+//	server := kongtest.NewServer()
+//	defer server.Close()
+//
+//	client := gokong.NewClient(&gokong.Config{HostAddress: server.URL})
+package kongtest
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Version is the version of kong the fake reports
+const Version = "2.8.1"
+
+// Server is a running fake kong admin api, its URL is the host address to give a client
+type Server struct {
+	*httptest.Server
+	mutex         sync.Mutex
+	store         *store
+	health        map[string]string
+	totalRequests int
+}
+
+// nested lists the collections that can be listed and created beneath an entity of another collection, keyed by
+// the parent collection and then the path of the child collection, see credentials, with the field of the child
+// that references the parent
+var nested = map[string]map[string]string{
+	"services": {"routes": "service", "plugins": "service"},
+	"routes":   {"plugins": "route"},
+	"consumers": {"plugins": "consumer", "key-auth": "consumer", "basic-auth": "consumer", "jwt": "consumer",
+		"hmac-auth": "consumer", "oauth2": "consumer", "acls": "consumer"},
+	"upstreams":    {"targets": "upstream"},
+	"certificates": {"snis": "certificate"},
+}
+
+// NewServer starts a fake kong admin api with no entities, Close stops it
+func NewServer() *Server {
+	server := &Server{store: newStore(), health: map[string]string{}}
+	server.Server = httptest.NewServer(http.HandlerFunc(server.serveHTTP))
+	return server
+}
+
+func (server *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	server.totalRequests++
+
+	status, body := server.handle(r)
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if body != nil {
+		_ = json.NewEncoder(w).Encode(body)
+	}
+}
+
+func (server *Server) handle(r *http.Request) (int, interface{}) {
+	segments := []string{}
+	for _, segment := range strings.Split(strings.Trim(r.URL.Path, "/"), "/") {
+		if segment != "" {
+			unescaped, _ := url.PathUnescape(segment)
+			segments = append(segments, unescaped)
+		}
+	}
+
+	body := map[string]interface{}{}
+	if raw, _ := ioutil.ReadAll(r.Body); len(bytes.TrimSpace(raw)) > 0 {
+		if err := json.Unmarshal(raw, &body); err != nil || body == nil {
+			return message(http.StatusBadRequest, "Cannot parse JSON body")
+		}
+	}
+
+	if len(segments) == 0 && r.Method == http.MethodGet {
+		return http.StatusOK, map[string]interface{}{"tagline": "Welcome to kong", "version": Version}
+	}
+
+	if len(segments) == 1 && segments[0] == "status" && r.Method == http.MethodGet {
+		return http.StatusOK, server.status()
+	}
+
+	if len(segments) == 0 || schemas[segments[0]] == nil {
+		return notFound()
+	}
+	schema := schemas[segments[0]]
+
+	switch len(segments) {
+	case 1:
+		switch r.Method {
+		case http.MethodGet:
+			return server.list(r, schema, map[string]string{}, false)
+		case http.MethodPost:
+			return server.create(schema, body, nil)
+		}
+	case 2:
+		entity := server.store.find(schema.name, segments[1])
+		switch r.Method {
+		case http.MethodGet:
+			if entity == nil {
+				return notFound()
+			}
+			return http.StatusOK, server.render(schema, entity)
+		case http.MethodPatch:
+			if entity == nil {
+				return notFound()
+			}
+			return server.update(schema, entity, body)
+		case http.MethodPut:
+			return server.upsert(schema, segments[1], entity, body, nil)
+		case http.MethodDelete:
+			return server.delete(schema, entity)
+		}
+	default:
+		parent := server.store.find(schema.name, segments[1])
+		if parent == nil {
+			return notFound()
+		}
+		return server.handleNested(r, schema, parent, segments[2:], body)
+	}
+
+	return message(http.StatusMethodNotAllowed, "Method not allowed")
+}
+
+func (server *Server) handleNested(r *http.Request, parentSchema *schema, parent map[string]interface{}, segments []string, body map[string]interface{}) (int, interface{}) {
+	if parentSchema.name == "upstreams" && segments[0] == "health" && len(segments) == 1 && r.Method == http.MethodGet {
+		return server.list(r, schemas["targets"], map[string]string{"upstream": parent["id"].(string)}, true)
+	}
+
+	if segments[0] == "consumer" && len(segments) == 1 && r.Method == http.MethodGet && parentSchema.fields["consumer"] != nil {
+		reference, ok := parent["consumer"].(map[string]interface{})
+		if !ok {
+			return notFound()
+		}
+		return http.StatusOK, server.render(schemas["consumers"], server.store.find("consumers", reference["id"].(string)))
+	}
+
+	parentField, ok := nested[parentSchema.name][segments[0]]
+	if !ok {
+		return notFound()
+	}
+	schema := schemas[segments[0]]
+	if collection, ok := credentials[segments[0]]; ok && parentSchema.name == "consumers" {
+		schema = schemas[collection]
+	}
+	reference := map[string]string{parentField: parent["id"].(string)}
+
+	if len(segments) == 1 {
+		switch r.Method {
+		case http.MethodGet:
+			return server.list(r, schema, reference, false)
+		case http.MethodPost:
+			return server.create(schema, body, reference)
+		}
+		return message(http.StatusMethodNotAllowed, "Method not allowed")
+	}
+
+	var entity map[string]interface{}
+	for _, candidate := range server.store.list(schema.name, reference) {
+		if candidate["id"] == segments[1] || (schema.name == "targets" && candidate["target"] == segments[1]) ||
+			(schema.name == "acls" && candidate["group"] == segments[1]) {
+			entity = candidate
+		}
+	}
+	if entity == nil {
+		entity = server.store.find(schema.name, segments[1])
+		if entity != nil && !matches(entity, reference) {
+			entity = nil
+		}
+	}
+
+	if schema.name == "targets" && len(segments) == 3 && r.Method == http.MethodPost &&
+		(segments[2] == "healthy" || segments[2] == "unhealthy") {
+		if entity == nil {
+			return notFound()
+		}
+		server.health[entity["id"].(string)] = strings.ToUpper(segments[2])
+		return http.StatusNoContent, nil
+	}
+
+	if len(segments) != 2 {
+		return notFound()
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if entity == nil {
+			return notFound()
+		}
+		return http.StatusOK, server.render(schema, entity)
+	case http.MethodPatch:
+		if entity == nil {
+			return notFound()
+		}
+		return server.update(schema, entity, body)
+	case http.MethodPut:
+		return server.upsert(schema, segments[1], entity, body, reference)
+	case http.MethodDelete:
+		return server.delete(schema, entity)
+	}
+
+	return message(http.StatusMethodNotAllowed, "Method not allowed")
+}
+
+// list returns a page of the entities of the collection matching filters and the tags in the query string, with
+// their health when they are the targets of an upstream's health endpoint
+func (server *Server) list(r *http.Request, schema *schema, filters map[string]string, withHealth bool) (int, interface{}) {
+	query := r.URL.Query()
+
+	size := 100
+	if query.Get("size") != "" {
+		var err error
+		size, err = strconv.Atoi(query.Get("size"))
+		if err != nil || size < 1 || size > 1000 {
+			return message(http.StatusBadRequest, "size must be an integer between 1 and 1000")
+		}
+	}
+
+	if schema.name == "consumers" && query.Get("custom_id") != "" {
+		filters["custom_id"] = query.Get("custom_id")
+	}
+
+	entities := []map[string]interface{}{}
+	for _, entity := range server.store.list(schema.name, filters) {
+		if query.Get("tags") == "" || hasTags(entity, query.Get("tags")) {
+			entities = append(entities, entity)
+		}
+	}
+
+	start := 0
+	if offset := query.Get("offset"); offset != "" {
+		var err error
+		start, err = strconv.Atoi(offset)
+		if err != nil || start < 0 || start > len(entities) {
+			return errorBody(http.StatusBadRequest, 9, "invalid offset", fmt.Sprintf("invalid offset: '%s'", offset), nil)
+		}
+	}
+
+	end := start + size
+	if end > len(entities) {
+		end = len(entities)
+	}
+
+	data := []interface{}{}
+	for _, entity := range entities[start:end] {
+		rendered := server.render(schema, entity)
+		if withHealth {
+			rendered["health"] = server.targetHealth(entity)
+		}
+		data = append(data, rendered)
+	}
+
+	page := map[string]interface{}{"data": data, "next": nil}
+	if end < len(entities) {
+		page["offset"] = strconv.Itoa(end)
+		page["next"] = r.URL.Path + "?offset=" + strconv.Itoa(end)
+	}
+	if withHealth {
+		page["node_id"] = "00000000-0000-4000-8000-000000000000"
+	}
+
+	return http.StatusOK, page
+}
+
+func (server *Server) create(schema *schema, body map[string]interface{}, reference map[string]string) (int, interface{}) {
+	entity := map[string]interface{}{"id": newId(), "created_at": now()}
+	if id, ok := body["id"].(string); ok {
+		if !uuidPattern.MatchString(id) {
+			return schemaViolation(map[string]interface{}{"id": "expected a valid UUID"})
+		}
+		entity["id"] = id
+	}
+
+	return server.write(http.StatusCreated, schema, entity, body, reference)
+}
+
+func (server *Server) update(schema *schema, current map[string]interface{}, body map[string]interface{}) (int, interface{}) {
+	entity := copyValue(current).(map[string]interface{})
+	return server.write(http.StatusOK, schema, entity, body, nil)
+}
+
+// upsert creates or replaces the entity with the id or endpoint key, the entity is replaced in full so fields that
+// are not in the body go back to their defaults
+func (server *Server) upsert(schema *schema, key string, current map[string]interface{}, body map[string]interface{}, reference map[string]string) (int, interface{}) {
+	entity := map[string]interface{}{"id": newId(), "created_at": now()}
+	if current != nil {
+		entity["id"], entity["created_at"] = current["id"], current["created_at"]
+	} else if uuidPattern.MatchString(key) {
+		entity["id"] = key
+	} else if len(schema.endpointKeys) > 0 {
+		body[schema.endpointKeys[0]] = key
+	} else {
+		return notFound()
+	}
+
+	return server.write(http.StatusOK, schema, entity, body, reference)
+}
+
+func (server *Server) write(status int, schema *schema, entity map[string]interface{}, body map[string]interface{}, reference map[string]string) (int, interface{}) {
+	if schema.name == "services" {
+		if errs := expandUrl(body); len(errs) > 0 {
+			return schemaViolation(errs)
+		}
+	}
+
+	for field, id := range reference {
+		body[field] = map[string]interface{}{"id": id}
+	}
+
+	if errs := schema.apply(entity, body); len(errs) > 0 {
+		return schemaViolation(errs)
+	}
+
+	if _, ok := body["password"].(string); ok && schema.name == "basic-auths" {
+		entity["password"] = hashPassword(entity)
+	}
+
+	transient := map[string]interface{}{}
+	for _, name := range schema.transient {
+		if v, ok := entity[name]; ok {
+			transient[name] = v
+			delete(entity, name)
+		}
+	}
+
+	if schema.updatedAt {
+		entity["updated_at"] = now()
+	}
+
+	if err := server.store.save(schema, entity); err != nil {
+		return err.status, err.body
+	}
+
+	if names, ok := transient["snis"]; ok {
+		if err := server.replaceSnis(entity["id"].(string), stringsOf(names)); err != nil {
+			return err.status, err.body
+		}
+	}
+
+	return status, server.render(schema, entity)
+}
+
+func (server *Server) replaceSnis(certificateId string, names []string) *apiError {
+	for _, name := range names {
+		if sni := server.store.find("snis", name); sni != nil && !matches(sni, map[string]string{"certificate": certificateId}) {
+			return uniqueViolation(map[string]interface{}{"name": name}, []string{"name"})
+		}
+	}
+
+	for _, sni := range server.store.list("snis", map[string]string{"certificate": certificateId}) {
+		server.store.delete("snis", sni["id"].(string))
+	}
+
+	for _, name := range names {
+		sni := map[string]interface{}{"id": newId(), "created_at": now(), "name": name, "certificate": map[string]interface{}{"id": certificateId}}
+		if err := server.store.save(schemas["snis"], sni); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (server *Server) delete(schema *schema, entity map[string]interface{}) (int, interface{}) {
+	if entity == nil {
+		return http.StatusNoContent, nil
+	}
+
+	id := entity["id"].(string)
+	if referencedBy := server.store.referencedBy(schema.name, id); referencedBy != "" {
+		return errorBody(http.StatusBadRequest, 4, "foreign key violation",
+			fmt.Sprintf("an existing '%s' entity references this '%s' entity", referencedBy, schema.name), nil)
+	}
+
+	server.store.delete(schema.name, id)
+	delete(server.health, id)
+	return http.StatusNoContent, nil
+}
+
+// render returns the entity as kong returns it, with every field of the schema present and unset fields null
+func (server *Server) render(schema *schema, entity map[string]interface{}) map[string]interface{} {
+	rendered := map[string]interface{}{"id": entity["id"], "created_at": entity["created_at"], "tags": nil}
+	if schema.updatedAt {
+		rendered["updated_at"] = entity["updated_at"]
+	}
+	for name := range schema.fields {
+		rendered[name] = nil
+	}
+	for name, v := range entity {
+		rendered[name] = copyValue(v)
+	}
+
+	if schema.name == "certificates" {
+		names := []string{}
+		for _, sni := range server.store.list("snis", map[string]string{"certificate": entity["id"].(string)}) {
+			names = append(names, sni["name"].(string))
+		}
+		sort.Strings(names)
+		rendered["snis"] = names
+	}
+
+	return rendered
+}
+
+func (server *Server) targetHealth(target map[string]interface{}) string {
+	if health, ok := server.health[target["id"].(string)]; ok {
+		return health
+	}
+	return "HEALTHCHECKS_OFF"
+}
+
+func (server *Server) status() map[string]interface{} {
+	return map[string]interface{}{
+		"database": map[string]interface{}{"reachable": true},
+		"server": map[string]interface{}{
+			"total_requests":       server.totalRequests,
+			"connections_active":   1,
+			"connections_accepted": server.totalRequests,
+			"connections_handled":  server.totalRequests,
+			"connections_reading":  0,
+			"connections_writing":  1,
+			"connections_waiting":  0,
+		},
+	}
+}
+
+// expandUrl replaces the url shorthand of a service with the protocol, host, port and path it describes
+func expandUrl(body map[string]interface{}) map[string]interface{} {
+	raw, ok := body["url"]
+	if !ok {
+		return nil
+	}
+	delete(body, "url")
+	if raw == nil {
+		return nil
+	}
+
+	s, ok := raw.(string)
+	if !ok {
+		return map[string]interface{}{"url": "expected a string"}
+	}
+
+	parsed, err := url.Parse(s)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return map[string]interface{}{"url": "missing host in url"}
+	}
+
+	body["protocol"] = parsed.Scheme
+	body["host"] = parsed.Hostname()
+	body["path"] = nil
+	if parsed.Path != "" {
+		body["path"] = parsed.Path
+	}
+
+	port := 80
+	if parsed.Scheme == "https" || parsed.Scheme == "grpcs" {
+		port = 443
+	}
+	if parsed.Port() != "" {
+		port, _ = strconv.Atoi(parsed.Port())
+	}
+	body["port"] = float64(port)
+
+	return nil
+}
+
+// hashPassword returns the password of a basic-auth credential hashed the way kong stores it, salted with the id of
+// the consumer
+func hashPassword(credential map[string]interface{}) string {
+	consumer := credential["consumer"].(map[string]interface{})
+	return fmt.Sprintf("%x", sha1.Sum([]byte(credential["password"].(string)+consumer["id"].(string))))
+}
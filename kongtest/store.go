@@ -0,0 +1,205 @@
+package kongtest
+
+import (
+	"crypto/rand"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// store holds every entity of every collection in the order they were created
+type store struct {
+	collections map[string][]map[string]interface{}
+}
+
+func newStore() *store {
+	store := &store{collections: map[string][]map[string]interface{}{}}
+	for name := range schemas {
+		store.collections[name] = []map[string]interface{}{}
+	}
+	return store
+}
+
+// find returns the entity of the collection whose id, or one of the collection's endpoint keys, is key
+func (store *store) find(collection string, key string) map[string]interface{} {
+	for _, entity := range store.collections[collection] {
+		if entity["id"] == key {
+			return entity
+		}
+	}
+
+	if uuidPattern.MatchString(key) {
+		return nil
+	}
+
+	for _, endpointKey := range schemas[collection].endpointKeys {
+		for _, entity := range store.collections[collection] {
+			if entity[endpointKey] == key {
+				return entity
+			}
+		}
+	}
+
+	return nil
+}
+
+// list returns the entities of the collection that match every one of the filters, a filter on a foreign field
+// matches the id of the referenced entity
+func (store *store) list(collection string, filters map[string]string) []map[string]interface{} {
+	result := []map[string]interface{}{}
+	for _, entity := range store.collections[collection] {
+		if matches(entity, filters) {
+			result = append(result, entity)
+		}
+	}
+	return result
+}
+
+func matches(entity map[string]interface{}, filters map[string]string) bool {
+	for name, want := range filters {
+		switch v := entity[name].(type) {
+		case map[string]interface{}:
+			if v["id"] != want {
+				return false
+			}
+		case string:
+			if v != want {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// save validates the entity against the uniqueness and foreign key constraints of the collection and then stores
+// it, replacing the stored entity with the same id
+func (store *store) save(schema *schema, entity map[string]interface{}) *apiError {
+	for name, f := range schema.fields {
+		if f.typ != typeForeign {
+			continue
+		}
+		reference, ok := entity[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if store.find(f.foreign, reference["id"].(string)) == nil {
+			return foreignKeyViolation(name, f.foreign, reference["id"].(string))
+		}
+	}
+
+	for _, fields := range schema.unique {
+		if entity[fields[0]] == nil {
+			continue
+		}
+		for _, other := range store.collections[schema.name] {
+			if other["id"] != entity["id"] && sameFields(entity, other, fields) {
+				return uniqueViolation(entity, fields)
+			}
+		}
+	}
+
+	stored := store.collections[schema.name]
+	for i, other := range stored {
+		if other["id"] == entity["id"] {
+			stored[i] = entity
+			return nil
+		}
+	}
+	store.collections[schema.name] = append(stored, entity)
+	return nil
+}
+
+func sameFields(a map[string]interface{}, b map[string]interface{}, fields []string) bool {
+	for _, name := range fields {
+		if fmt.Sprint(a[name]) != fmt.Sprint(b[name]) {
+			return false
+		}
+	}
+	return true
+}
+
+// reference is a foreign field of a collection
+type reference struct {
+	collection string
+	field      string
+}
+
+// cascades lists the entities that are deleted along with the entity they reference
+var cascades = map[string][]reference{
+	"services": {{"plugins", "service"}},
+	"routes":   {{"plugins", "route"}},
+	"consumers": {{"plugins", "consumer"}, {"key-auths", "consumer"}, {"basic-auths", "consumer"}, {"jwts", "consumer"},
+		{"hmac-auths", "consumer"}, {"oauth2", "consumer"}, {"acls", "consumer"}},
+	"upstreams":    {{"targets", "upstream"}},
+	"certificates": {{"snis", "certificate"}},
+}
+
+// restrictions lists the entities that stop the entity they reference from being deleted
+var restrictions = map[string][]reference{
+	"services":     {{"routes", "service"}},
+	"certificates": {{"services", "client_certificate"}, {"upstreams", "client_certificate"}},
+}
+
+// delete removes the entity from the collection along with everything that cascades from it
+func (store *store) delete(collection string, id string) {
+	remaining := []map[string]interface{}{}
+	for _, entity := range store.collections[collection] {
+		if entity["id"] != id {
+			remaining = append(remaining, entity)
+		}
+	}
+	store.collections[collection] = remaining
+
+	for _, cascade := range cascades[collection] {
+		for _, entity := range store.list(cascade.collection, map[string]string{cascade.field: id}) {
+			store.delete(cascade.collection, entity["id"].(string))
+		}
+	}
+}
+
+// referencedBy returns the name of a collection whose entities still reference the entity, kong refuses to delete
+// an entity while it is referenced in this way
+func (store *store) referencedBy(collection string, id string) string {
+	for _, restriction := range restrictions[collection] {
+		if len(store.list(restriction.collection, map[string]string{restriction.field: id})) > 0 {
+			return restriction.collection
+		}
+	}
+	return ""
+}
+
+func newId() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func now() float64 {
+	return float64(time.Now().Unix())
+}
+
+func hasTags(entity map[string]interface{}, filter string) bool {
+	tags := stringsOf(entity["tags"])
+	if strings.Contains(filter, "/") {
+		for _, tag := range strings.Split(filter, "/") {
+			if contains(tags, tag) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, tag := range strings.Split(filter, ",") {
+		if !contains(tags, tag) {
+			return false
+		}
+	}
+	return true
+}
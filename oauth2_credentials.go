@@ -0,0 +1,161 @@
+package gokong
+
+import (
+	"context"
+)
+
+// OAuth2CredentialClient manages oauth2 credentials of consumers
+type OAuth2CredentialClient struct {
+	credentialClient
+}
+
+type OAuth2CredentialRequest struct {
+	Name         string    `json:"name,omitempty" yaml:"name,omitempty"`
+	ClientId     string    `json:"client_id,omitempty" yaml:"client_id,omitempty"`
+	ClientSecret string    `json:"client_secret,omitempty" yaml:"client_secret,omitempty"`
+	RedirectUris []string  `json:"redirect_uris,omitempty" yaml:"redirect_uris,omitempty"`
+	Tags         []*string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Fields       `json:"-" yaml:"-"`
+}
+
+type OAuth2Credential struct {
+	Id           string    `json:"id,omitempty" yaml:"id,omitempty"`
+	Name         string    `json:"name,omitempty" yaml:"name,omitempty"`
+	ClientId     string    `json:"client_id,omitempty" yaml:"client_id,omitempty"`
+	ClientSecret string    `json:"client_secret,omitempty" yaml:"client_secret,omitempty"`
+	RedirectUris []string  `json:"redirect_uris,omitempty" yaml:"redirect_uris,omitempty"`
+	Consumer     *Id       `json:"consumer,omitempty" yaml:"consumer,omitempty"`
+	CreatedAt    int       `json:"created_at,omitempty" yaml:"created_at,omitempty"`
+	Tags         []*string `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+type OAuth2Credentials struct {
+	Results []*OAuth2Credential `json:"data" yaml:"data,omitempty"`
+	Next    *string             `json:"next" yaml:"next,omitempty"`
+	Offset  string              `json:"offset,omitempty" yaml:"offset,omitempty"`
+}
+
+const OAuth2CredentialsPath = "/oauth2/"
+
+func (oauth2CredentialClient *OAuth2CredentialClient) Create(consumerUsernameOrId string, oauth2CredentialRequest *OAuth2CredentialRequest) (*OAuth2Credential, error) {
+	return oauth2CredentialClient.CreateWithContext(context.Background(), consumerUsernameOrId, oauth2CredentialRequest)
+}
+
+func (oauth2CredentialClient *OAuth2CredentialClient) CreateWithContext(ctx context.Context, consumerUsernameOrId string, oauth2CredentialRequest *OAuth2CredentialRequest) (*OAuth2Credential, error) {
+	oauth2Credential := &OAuth2Credential{}
+	if err := oauth2CredentialClient.create(ctx, consumerUsernameOrId, oauth2CredentialRequest, oauth2Credential); err != nil {
+		return nil, err
+	}
+
+	return oauth2Credential, nil
+}
+
+func (oauth2CredentialClient *OAuth2CredentialClient) GetById(consumerUsernameOrId string, id string) (*OAuth2Credential, error) {
+	return oauth2CredentialClient.GetByIdWithContext(context.Background(), consumerUsernameOrId, id)
+}
+
+// GetByIdWithContext returns the oauth2 credential of the consumer with the id or client id, or nil when the consumer
+// has no such credential
+func (oauth2CredentialClient *OAuth2CredentialClient) GetByIdWithContext(ctx context.Context, consumerUsernameOrId string, id string) (*OAuth2Credential, error) {
+	oauth2Credential := &OAuth2Credential{}
+	found, err := oauth2CredentialClient.getById(ctx, consumerUsernameOrId, id, oauth2Credential)
+	if err != nil || !found || oauth2Credential.Id == "" {
+		return nil, err
+	}
+
+	return oauth2Credential, nil
+}
+
+func (oauth2CredentialClient *OAuth2CredentialClient) GetConsumer(id string) (*Consumer, error) {
+	return oauth2CredentialClient.GetConsumerWithContext(context.Background(), id)
+}
+
+// GetConsumerWithContext returns the consumer owning the oauth2 credential with the id or client id, or nil when
+// there is no such credential
+func (oauth2CredentialClient *OAuth2CredentialClient) GetConsumerWithContext(ctx context.Context, id string) (*Consumer, error) {
+	return oauth2CredentialClient.consumer(ctx, id)
+}
+
+func (oauth2CredentialClient *OAuth2CredentialClient) List(query *CredentialQueryString) ([]*OAuth2Credential, error) {
+	return oauth2CredentialClient.ListWithContext(context.Background(), query)
+}
+
+// ListWithContext returns the oauth2 credentials of every consumer matching the query, following pagination
+func (oauth2CredentialClient *OAuth2CredentialClient) ListWithContext(ctx context.Context, query *CredentialQueryString) ([]*OAuth2Credential, error) {
+	oauth2Credentials := make([]*OAuth2Credential, 0)
+	if err := oauth2CredentialClient.list(ctx, "", query, &oauth2Credentials); err != nil {
+		return nil, err
+	}
+
+	return oauth2Credentials, nil
+}
+
+func (oauth2CredentialClient *OAuth2CredentialClient) ListFromConsumer(consumerUsernameOrId string, query *CredentialQueryString) ([]*OAuth2Credential, error) {
+	return oauth2CredentialClient.ListFromConsumerWithContext(context.Background(), consumerUsernameOrId, query)
+}
+
+// ListFromConsumerWithContext returns the oauth2 credentials of the consumer matching the query, following pagination
+func (oauth2CredentialClient *OAuth2CredentialClient) ListFromConsumerWithContext(ctx context.Context, consumerUsernameOrId string, query *CredentialQueryString) ([]*OAuth2Credential, error) {
+	oauth2Credentials := make([]*OAuth2Credential, 0)
+	if err := oauth2CredentialClient.list(ctx, consumerUsernameOrId, query, &oauth2Credentials); err != nil {
+		return nil, err
+	}
+
+	return oauth2Credentials, nil
+}
+
+// Iter returns an iterator over the oauth2 credentials of every consumer matching the query
+func (oauth2CredentialClient *OAuth2CredentialClient) Iter(ctx context.Context, query *CredentialQueryString) *OAuth2CredentialIterator {
+	return &OAuth2CredentialIterator{iterator: oauth2CredentialClient.iter(ctx, "", query)}
+}
+
+// IterFromConsumer returns an iterator over the oauth2 credentials of the consumer matching the query
+func (oauth2CredentialClient *OAuth2CredentialClient) IterFromConsumer(ctx context.Context, consumerUsernameOrId string, query *CredentialQueryString) *OAuth2CredentialIterator {
+	return &OAuth2CredentialIterator{iterator: oauth2CredentialClient.iter(ctx, consumerUsernameOrId, query)}
+}
+
+type OAuth2CredentialIterator struct {
+	iterator
+}
+
+// Value returns the oauth2 credential the iterator is positioned on
+func (oauth2CredentialIterator *OAuth2CredentialIterator) Value() *OAuth2Credential {
+	oauth2Credential, _ := oauth2CredentialIterator.value.(*OAuth2Credential)
+	return oauth2Credential
+}
+
+func (oauth2CredentialClient *OAuth2CredentialClient) UpdateById(consumerUsernameOrId string, id string, oauth2CredentialRequest *OAuth2CredentialRequest) (*OAuth2Credential, error) {
+	return oauth2CredentialClient.UpdateByIdWithContext(context.Background(), consumerUsernameOrId, id, oauth2CredentialRequest)
+}
+
+func (oauth2CredentialClient *OAuth2CredentialClient) UpdateByIdWithContext(ctx context.Context, consumerUsernameOrId string, id string, oauth2CredentialRequest *OAuth2CredentialRequest) (*OAuth2Credential, error) {
+	oauth2Credential := &OAuth2Credential{}
+	if err := oauth2CredentialClient.update(ctx, consumerUsernameOrId, id, oauth2CredentialRequest, oauth2Credential); err != nil {
+		return nil, err
+	}
+
+	return oauth2Credential, nil
+}
+
+func (oauth2CredentialClient *OAuth2CredentialClient) Upsert(consumerUsernameOrId string, id string, oauth2CredentialRequest *OAuth2CredentialRequest) (*OAuth2Credential, error) {
+	return oauth2CredentialClient.UpsertWithContext(context.Background(), consumerUsernameOrId, id, oauth2CredentialRequest)
+}
+
+// UpsertWithContext creates the oauth2 credential of the consumer with the id or client id or, when it exists,
+// replaces it in full with the request
+func (oauth2CredentialClient *OAuth2CredentialClient) UpsertWithContext(ctx context.Context, consumerUsernameOrId string, id string, oauth2CredentialRequest *OAuth2CredentialRequest) (*OAuth2Credential, error) {
+	oauth2Credential := &OAuth2Credential{}
+	if err := oauth2CredentialClient.upsert(ctx, consumerUsernameOrId, id, oauth2CredentialRequest, oauth2Credential); err != nil {
+		return nil, err
+	}
+
+	return oauth2Credential, nil
+}
+
+func (oauth2CredentialClient *OAuth2CredentialClient) DeleteById(consumerUsernameOrId string, id string) error {
+	return oauth2CredentialClient.DeleteByIdWithContext(context.Background(), consumerUsernameOrId, id)
+}
+
+func (oauth2CredentialClient *OAuth2CredentialClient) DeleteByIdWithContext(ctx context.Context, consumerUsernameOrId string, id string) error {
+	return oauth2CredentialClient.delete(ctx, consumerUsernameOrId, id)
+}
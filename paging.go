@@ -0,0 +1,220 @@
+package gokong
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// listPage is implemented by the list responses kong returns so a pager can follow them from one page to the next,
+// items returns the slice of results the page holds
+type listPage interface {
+	nextOffset() string
+	items() interface{}
+}
+
+// pager fetches the pages of a kong list endpoint one at a time, the query is sent with every request with its
+// offset replaced by the one kong returned with the previous page and its size kept between 100 and 1000
+type pager struct {
+	ctx     context.Context
+	config  *Config
+	address string
+	name    string
+	query   interface{}
+	offset  string
+	started bool
+}
+
+func newPager(ctx context.Context, config *Config, address string, name string, query interface{}) *pager {
+	return &pager{ctx: ctx, config: config, address: address, name: name, query: query}
+}
+
+// more reports whether there is another page to fetch
+func (p *pager) more() bool {
+	return !p.started || p.offset != ""
+}
+
+// next fetches the next page into page
+func (p *pager) next(page listPage) error {
+	request := newGet(p.ctx, p.config, p.address).Query(p.query)
+
+	size, _ := strconv.Atoi(request.agent.QueryData.Get("size"))
+	request.agent.QueryData.Set("size", strconv.Itoa(pageSize(size)))
+	if p.offset != "" {
+		request.agent.QueryData.Set("offset", p.offset)
+	}
+
+	r, body, errs := request.End()
+	if errs != nil {
+		return fmt.Errorf("could not get %s, error: %v", p.name, errs)
+	}
+
+	if err := checkResponse(r, body); err != nil {
+		return err
+	}
+
+	err := json.Unmarshal([]byte(body), page)
+	if err != nil {
+		return fmt.Errorf("could not parse %s list response, error: %v", p.name, err)
+	}
+
+	p.started = true
+	p.offset = page.nextOffset()
+	return nil
+}
+
+func pageSize(size int) int {
+	if size < 100 {
+		return 100
+	}
+
+	if size > 1000 {
+		return 1000
+	}
+
+	return size
+}
+
+func nextOffset(next *string, offset string) string {
+	if next == nil || *next == "" {
+		return ""
+	}
+	return offset
+}
+
+func (services *Services) nextOffset() string {
+	return nextOffset(services.Next, services.Offset)
+}
+
+func (services *Services) items() interface{} {
+	return services.Data
+}
+
+func (routes *Routes) nextOffset() string {
+	return nextOffset(routes.Next, routes.Offset)
+}
+
+func (routes *Routes) items() interface{} {
+	return routes.Data
+}
+
+func (plugins *Plugins) nextOffset() string {
+	return nextOffset(plugins.Next, plugins.Offset)
+}
+
+func (plugins *Plugins) items() interface{} {
+	return plugins.Data
+}
+
+func (consumers *Consumers) nextOffset() string {
+	return nextOffset(&consumers.Next, consumers.Offset)
+}
+
+func (consumers *Consumers) items() interface{} {
+	return consumers.Results
+}
+
+func (upstreams *Upstreams) nextOffset() string {
+	return nextOffset(&upstreams.Next, upstreams.Offset)
+}
+
+func (upstreams *Upstreams) items() interface{} {
+	return upstreams.Results
+}
+
+func (targets *Targets) nextOffset() string {
+	return nextOffset(&targets.Next, targets.Offset)
+}
+
+func (targets *Targets) items() interface{} {
+	return targets.Data
+}
+
+func (certificates *Certificates) nextOffset() string {
+	return nextOffset(&certificates.Next, certificates.Offset)
+}
+
+func (certificates *Certificates) items() interface{} {
+	return certificates.Results
+}
+
+func (caCertificates *CACertificates) nextOffset() string {
+	return nextOffset(&caCertificates.Next, caCertificates.Offset)
+}
+
+func (caCertificates *CACertificates) items() interface{} {
+	return caCertificates.Results
+}
+
+func (snis *Snis) nextOffset() string {
+	return nextOffset(&snis.Next, snis.Offset)
+}
+
+func (snis *Snis) items() interface{} {
+	return snis.Results
+}
+
+func (workspaces *Workspaces) nextOffset() string {
+	return nextOffset(workspaces.Next, workspaces.Offset)
+}
+
+func (workspaces *Workspaces) items() interface{} {
+	return workspaces.Data
+}
+
+func (tags *Tags) nextOffset() string {
+	return nextOffset(tags.Next, tags.Offset)
+}
+
+func (tags *Tags) items() interface{} {
+	return tags.Data
+}
+
+func (keyAuths *KeyAuths) nextOffset() string {
+	return nextOffset(keyAuths.Next, keyAuths.Offset)
+}
+
+func (keyAuths *KeyAuths) items() interface{} {
+	return keyAuths.Results
+}
+
+func (basicAuths *BasicAuths) nextOffset() string {
+	return nextOffset(basicAuths.Next, basicAuths.Offset)
+}
+
+func (basicAuths *BasicAuths) items() interface{} {
+	return basicAuths.Results
+}
+
+func (jwts *Jwts) nextOffset() string {
+	return nextOffset(jwts.Next, jwts.Offset)
+}
+
+func (jwts *Jwts) items() interface{} {
+	return jwts.Results
+}
+
+func (hmacAuths *HmacAuths) nextOffset() string {
+	return nextOffset(hmacAuths.Next, hmacAuths.Offset)
+}
+
+func (hmacAuths *HmacAuths) items() interface{} {
+	return hmacAuths.Results
+}
+
+func (oauth2Credentials *OAuth2Credentials) nextOffset() string {
+	return nextOffset(oauth2Credentials.Next, oauth2Credentials.Offset)
+}
+
+func (oauth2Credentials *OAuth2Credentials) items() interface{} {
+	return oauth2Credentials.Results
+}
+
+func (acls *Acls) nextOffset() string {
+	return nextOffset(acls.Next, acls.Offset)
+}
+
+func (acls *Acls) items() interface{} {
+	return acls.Results
+}
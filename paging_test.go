@@ -0,0 +1,61 @@
+package gokong
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PagingFollowsEveryPageOfTargets(t *testing.T) {
+	queries := []string{}
+	server := newPagedServer(&queries, []string{`{"id":"1"}`, `{"id":"2"}`, `{"id":"3"}`})
+	defer server.Close()
+
+	client := NewClient(&Config{HostAddress: server.URL})
+
+	targets, err := client.Targets().GetTargetsFromUpstreamId("upstream")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(targets))
+	assert.Equal(t, []string{"size=100", "offset=1&size=100", "offset=2&size=100"}, queries)
+
+	queries = queries[:0]
+	targets, err = client.Targets().GetTargetsWithHealthFromUpstreamId("upstream")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(targets))
+	assert.Equal(t, []string{"size=100", "offset=1&size=100", "offset=2&size=100"}, queries)
+}
+
+func Test_PagingFollowsEveryPageOfUpstreamsCertificatesAndSnis(t *testing.T) {
+	queries := []string{}
+	server := newPagedServer(&queries, []string{`{"id":"1"},{"id":"2"}`, `{"id":"3"}`})
+	defer server.Close()
+
+	client := NewClient(&Config{HostAddress: server.URL})
+
+	upstreams, err := client.Upstreams().List()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(upstreams.Results))
+
+	certificates, err := client.Certificates().List()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(certificates.Results))
+
+	snis, err := client.Snis().List()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(snis.Results))
+
+	assert.Equal(t, 6, len(queries))
+}
+
+func Test_PagingKeepsTheQueryAndClampsTheSize(t *testing.T) {
+	queries := []string{}
+	server := newPagedServer(&queries, []string{`{"id":"1"}`, `{"id":"2"}`, `{"id":"3"}`})
+	defer server.Close()
+
+	client := NewClient(&Config{HostAddress: server.URL})
+
+	upstreams, err := client.Upstreams().GetUpstreams(&UpstreamQueryString{Offset: "1", Size: 5000, Tags: "a"})
+	assert.Nil(t, err)
+	assert.Equal(t, []*Upstream{{Id: "2"}, {Id: "3"}}, upstreams)
+	assert.Equal(t, []string{"offset=1&size=1000&tags=a", "offset=2&size=1000&tags=a"}, queries)
+}
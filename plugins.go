@@ -1,6 +1,7 @@
 package gokong
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 )
@@ -10,13 +11,15 @@ type PluginClient struct {
 }
 
 type PluginRequest struct {
-	Name       string                 `json:"name" yaml:"name"`
-	ConsumerId *Id                    `json:"consumer" yaml:"consumer"`
-	ServiceId  *Id                    `json:"service" yaml:"service"`
-	RouteId    *Id                    `json:"route" yaml:"route"`
+	Name       string                 `json:"name,omitempty" yaml:"name,omitempty"`
+	ConsumerId *Id                    `json:"consumer,omitempty" yaml:"consumer,omitempty"`
+	ServiceId  *Id                    `json:"service,omitempty" yaml:"service,omitempty"`
+	RouteId    *Id                    `json:"route,omitempty" yaml:"route,omitempty"`
 	RunOn      string                 `json:"run_on,omitempty" yaml:"run_on,omitempty"`
 	Config     map[string]interface{} `json:"config,omitempty" yaml:"config,omitempty"`
 	Enabled    *bool                  `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	Tags       []*string              `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Fields     `json:"-" yaml:"-"`
 }
 
 type Plugin struct {
@@ -28,6 +31,7 @@ type Plugin struct {
 	RunOn      string                 `json:"run_on,omitempty" yaml:"run_on,omitempty"`
 	Config     map[string]interface{} `json:"config,omitempty" yaml:"config,omitempty"`
 	Enabled    bool                   `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	Tags       []*string              `json:"tags,omitempty" yaml:"tags,omitempty"`
 }
 
 type Plugins struct {
@@ -39,19 +43,28 @@ type Plugins struct {
 type PluginQueryString struct {
 	Offset string `json:"offset,omitempty" yaml:"offset,omitempty"`
 	Size   int    `json:"size" yaml:"size,omitempty"`
+	Tags   string `json:"tags,omitempty" yaml:"tags,omitempty"`
 }
 
 const PluginsPath = "/plugins/"
 
 func (pluginClient *PluginClient) GetById(id string) (*Plugin, error) {
+	return pluginClient.GetByIdWithContext(context.Background(), id)
+}
+
+func (pluginClient *PluginClient) GetByIdWithContext(ctx context.Context, id string) (*Plugin, error) {
 
-	r, body, errs := newGet(pluginClient.config, pluginClient.config.HostAddress+PluginsPath+id).End()
+	r, body, errs := newGet(ctx, pluginClient.config, pluginClient.config.HostAddress+PluginsPath+id).End()
 	if errs != nil {
 		return nil, fmt.Errorf("could not get plugin, error: %v", errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return nil, fmt.Errorf("not authorised, message from kong: %s", body)
+	if r.StatusCode == 404 {
+		return nil, nil
+	}
+
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
 	}
 
 	plugin := &Plugin{}
@@ -68,54 +81,54 @@ func (pluginClient *PluginClient) GetById(id string) (*Plugin, error) {
 }
 
 func (pluginClient *PluginClient) List(query *PluginQueryString) ([]*Plugin, error) {
-	plugins := make([]*Plugin, 0)
-
-	if query.Size < 100 {
-		query.Size = 100
-	}
+	return pluginClient.ListWithContext(context.Background(), query)
+}
 
-	if query.Size > 1000 {
-		query.Size = 1000
-	}
+func (pluginClient *PluginClient) ListWithContext(ctx context.Context, query *PluginQueryString) ([]*Plugin, error) {
+	plugins := make([]*Plugin, 0)
 
-	for {
+	pages := newPager(ctx, pluginClient.config, pluginClient.config.HostAddress+PluginsPath, "plugins", *query)
+	for pages.more() {
 		data := &Plugins{}
-
-		r, body, errs := newGet(pluginClient.config, pluginClient.config.HostAddress+PluginsPath).Query(*query).End()
-		if errs != nil {
-			return nil, fmt.Errorf("could not get plugins, error: %v", errs)
-		}
-
-		if r.StatusCode == 401 || r.StatusCode == 403 {
-			return nil, fmt.Errorf("not authorised, message from kong: %s", body)
-		}
-
-		err := json.Unmarshal([]byte(body), data)
-		if err != nil {
-			return nil, fmt.Errorf("could not parse plugins list response, error: %v", err)
+		if err := pages.next(data); err != nil {
+			return nil, err
 		}
 
 		plugins = append(plugins, data.Data...)
-
-		if data.Next == nil || *data.Next == "" {
-			break
-		}
-
-		query.Offset = data.Offset
 	}
 
 	return plugins, nil
 }
 
+// Iter returns an iterator over the plugins matching the query
+func (pluginClient *PluginClient) Iter(ctx context.Context, query *PluginQueryString) *PluginIterator {
+	pages := newPager(ctx, pluginClient.config, pluginClient.config.HostAddress+PluginsPath, "plugins", *query)
+	return &PluginIterator{iterator: newIterator(pages, func() listPage { return &Plugins{} })}
+}
+
+type PluginIterator struct {
+	iterator
+}
+
+// Value returns the plugin the iterator is positioned on
+func (pluginIterator *PluginIterator) Value() *Plugin {
+	plugin, _ := pluginIterator.value.(*Plugin)
+	return plugin
+}
+
 func (pluginClient *PluginClient) Create(pluginRequest *PluginRequest) (*Plugin, error) {
+	return pluginClient.CreateWithContext(context.Background(), pluginRequest)
+}
 
-	r, body, errs := newPost(pluginClient.config, pluginClient.config.HostAddress+PluginsPath).Send(pluginRequest).End()
+func (pluginClient *PluginClient) CreateWithContext(ctx context.Context, pluginRequest *PluginRequest) (*Plugin, error) {
+
+	r, body, errs := newPost(ctx, pluginClient.config, pluginClient.config.HostAddress+PluginsPath).Send(pluginRequest).End()
 	if errs != nil {
 		return nil, fmt.Errorf("could not create new plugin, error: %v", errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return nil, fmt.Errorf("not authorised, message from kong: %s", body)
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
 	}
 
 	createdPlugin := &Plugin{}
@@ -132,14 +145,18 @@ func (pluginClient *PluginClient) Create(pluginRequest *PluginRequest) (*Plugin,
 }
 
 func (pluginClient *PluginClient) UpdateById(id string, pluginRequest *PluginRequest) (*Plugin, error) {
+	return pluginClient.UpdateByIdWithContext(context.Background(), id, pluginRequest)
+}
 
-	r, body, errs := newPatch(pluginClient.config, pluginClient.config.HostAddress+PluginsPath+id).Send(pluginRequest).End()
+func (pluginClient *PluginClient) UpdateByIdWithContext(ctx context.Context, id string, pluginRequest *PluginRequest) (*Plugin, error) {
+
+	r, body, errs := newPatch(ctx, pluginClient.config, pluginClient.config.HostAddress+PluginsPath+id).Send(pluginRequest).End()
 	if errs != nil {
 		return nil, fmt.Errorf("could not update plugin, error: %v", errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return nil, fmt.Errorf("not authorised, message from kong: %s", body)
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
 	}
 
 	updatedPlugin := &Plugin{}
@@ -155,28 +172,66 @@ func (pluginClient *PluginClient) UpdateById(id string, pluginRequest *PluginReq
 	return updatedPlugin, nil
 }
 
+func (pluginClient *PluginClient) Upsert(id string, pluginRequest *PluginRequest) (*Plugin, error) {
+	return pluginClient.UpsertWithContext(context.Background(), id, pluginRequest)
+}
+
+// UpsertWithContext creates the plugin with the id or, when it exists, replaces it in full with the request so
+// fields missing from the request go back to their defaults
+func (pluginClient *PluginClient) UpsertWithContext(ctx context.Context, id string, pluginRequest *PluginRequest) (*Plugin, error) {
+
+	r, body, errs := newPut(ctx, pluginClient.config, pluginClient.config.HostAddress+PluginsPath+id).Send(pluginRequest).End()
+	if errs != nil {
+		return nil, fmt.Errorf("could not upsert plugin, error: %v", errs)
+	}
+
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
+	}
+
+	upsertedPlugin := &Plugin{}
+	err := json.Unmarshal([]byte(body), upsertedPlugin)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse plugin upsert response, error: %v", err)
+	}
+
+	if upsertedPlugin.Id == "" {
+		return nil, fmt.Errorf("could not upsert plugin, error: %v", body)
+	}
+
+	return upsertedPlugin, nil
+}
+
 func (pluginClient *PluginClient) DeleteById(id string) error {
+	return pluginClient.DeleteByIdWithContext(context.Background(), id)
+}
 
-	r, body, errs := newDelete(pluginClient.config, pluginClient.config.HostAddress+PluginsPath+id).End()
+func (pluginClient *PluginClient) DeleteByIdWithContext(ctx context.Context, id string) error {
+
+	r, body, errs := newDelete(ctx, pluginClient.config, pluginClient.config.HostAddress+PluginsPath+id).End()
 	if errs != nil {
 		return fmt.Errorf("could not delete plugin, result: %v error: %v", r, errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return fmt.Errorf("not authorised, message from kong: %s", body)
+	if err := checkResponse(r, body); err != nil {
+		return err
 	}
 
 	return nil
 }
 
 func (pluginClient *PluginClient) GetByConsumerId(id string) (*Plugins, error) {
-	r, body, errs := newGet(pluginClient.config, pluginClient.config.HostAddress+"/consumers/"+id+"/plugins").End()
+	return pluginClient.GetByConsumerIdWithContext(context.Background(), id)
+}
+
+func (pluginClient *PluginClient) GetByConsumerIdWithContext(ctx context.Context, id string) (*Plugins, error) {
+	r, body, errs := newGet(ctx, pluginClient.config, pluginClient.config.HostAddress+"/consumers/"+id+"/plugins").End()
 	if errs != nil {
 		return nil, fmt.Errorf("could not get plugins, error: %v", errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return nil, fmt.Errorf("not authorised, message from kong: %s", body)
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
 	}
 
 	plugins := &Plugins{}
@@ -189,13 +244,17 @@ func (pluginClient *PluginClient) GetByConsumerId(id string) (*Plugins, error) {
 }
 
 func (pluginClient *PluginClient) GetByRouteId(id string) (*Plugins, error) {
-	r, body, errs := newGet(pluginClient.config, pluginClient.config.HostAddress+"/routes/"+id+"/plugins").End()
+	return pluginClient.GetByRouteIdWithContext(context.Background(), id)
+}
+
+func (pluginClient *PluginClient) GetByRouteIdWithContext(ctx context.Context, id string) (*Plugins, error) {
+	r, body, errs := newGet(ctx, pluginClient.config, pluginClient.config.HostAddress+"/routes/"+id+"/plugins").End()
 	if errs != nil {
 		return nil, fmt.Errorf("could not get plugins, error: %v", errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return nil, fmt.Errorf("not authorised, message from kong: %s", body)
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
 	}
 
 	plugins := &Plugins{}
@@ -208,13 +267,17 @@ func (pluginClient *PluginClient) GetByRouteId(id string) (*Plugins, error) {
 }
 
 func (pluginClient *PluginClient) GetByServiceId(id string) (*Plugins, error) {
-	r, body, errs := newGet(pluginClient.config, pluginClient.config.HostAddress+"/services/"+id+"/plugins").End()
+	return pluginClient.GetByServiceIdWithContext(context.Background(), id)
+}
+
+func (pluginClient *PluginClient) GetByServiceIdWithContext(ctx context.Context, id string) (*Plugins, error) {
+	r, body, errs := newGet(ctx, pluginClient.config, pluginClient.config.HostAddress+"/services/"+id+"/plugins").End()
 	if errs != nil {
 		return nil, fmt.Errorf("could not get plugins, error: %v", errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return nil, fmt.Errorf("not authorised, message from kong: %s", body)
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
 	}
 
 	plugins := &Plugins{}
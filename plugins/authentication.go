@@ -0,0 +1,92 @@
+package plugins
+
+// KeyAuth is the config of the key-auth plugin
+type KeyAuth struct {
+	KeyNames        []string `json:"key_names,omitempty" yaml:"key_names,omitempty"`
+	HideCredentials *bool    `json:"hide_credentials,omitempty" yaml:"hide_credentials,omitempty"`
+	Anonymous       *string  `json:"anonymous,omitempty" yaml:"anonymous,omitempty"`
+	KeyInHeader     *bool    `json:"key_in_header,omitempty" yaml:"key_in_header,omitempty"`
+	KeyInQuery      *bool    `json:"key_in_query,omitempty" yaml:"key_in_query,omitempty"`
+	KeyInBody       *bool    `json:"key_in_body,omitempty" yaml:"key_in_body,omitempty"`
+	RunOnPreflight  *bool    `json:"run_on_preflight,omitempty" yaml:"run_on_preflight,omitempty"`
+}
+
+func (*KeyAuth) PluginName() string {
+	return "key-auth"
+}
+
+// BasicAuth is the config of the basic-auth plugin
+type BasicAuth struct {
+	HideCredentials *bool   `json:"hide_credentials,omitempty" yaml:"hide_credentials,omitempty"`
+	Anonymous       *string `json:"anonymous,omitempty" yaml:"anonymous,omitempty"`
+}
+
+func (*BasicAuth) PluginName() string {
+	return "basic-auth"
+}
+
+// Jwt is the config of the jwt plugin
+type Jwt struct {
+	UriParamNames     []string `json:"uri_param_names,omitempty" yaml:"uri_param_names,omitempty"`
+	CookieNames       []string `json:"cookie_names,omitempty" yaml:"cookie_names,omitempty"`
+	HeaderNames       []string `json:"header_names,omitempty" yaml:"header_names,omitempty"`
+	ClaimsToVerify    []string `json:"claims_to_verify,omitempty" yaml:"claims_to_verify,omitempty"`
+	KeyClaimName      *string  `json:"key_claim_name,omitempty" yaml:"key_claim_name,omitempty"`
+	SecretIsBase64    *bool    `json:"secret_is_base64,omitempty" yaml:"secret_is_base64,omitempty"`
+	Anonymous         *string  `json:"anonymous,omitempty" yaml:"anonymous,omitempty"`
+	RunOnPreflight    *bool    `json:"run_on_preflight,omitempty" yaml:"run_on_preflight,omitempty"`
+	MaximumExpiration *int     `json:"maximum_expiration,omitempty" yaml:"maximum_expiration,omitempty"`
+}
+
+func (*Jwt) PluginName() string {
+	return "jwt"
+}
+
+// HmacAuth is the config of the hmac-auth plugin
+type HmacAuth struct {
+	HideCredentials     *bool    `json:"hide_credentials,omitempty" yaml:"hide_credentials,omitempty"`
+	ClockSkew           *int     `json:"clock_skew,omitempty" yaml:"clock_skew,omitempty"`
+	Anonymous           *string  `json:"anonymous,omitempty" yaml:"anonymous,omitempty"`
+	ValidateRequestBody *bool    `json:"validate_request_body,omitempty" yaml:"validate_request_body,omitempty"`
+	EnforceHeaders      []string `json:"enforce_headers,omitempty" yaml:"enforce_headers,omitempty"`
+	Algorithms          []string `json:"algorithms,omitempty" yaml:"algorithms,omitempty"`
+}
+
+func (*HmacAuth) PluginName() string {
+	return "hmac-auth"
+}
+
+// OAuth2 is the config of the oauth2 plugin
+type OAuth2 struct {
+	Scopes                        []string `json:"scopes,omitempty" yaml:"scopes,omitempty"`
+	MandatoryScope                *bool    `json:"mandatory_scope,omitempty" yaml:"mandatory_scope,omitempty"`
+	ProvisionKey                  *string  `json:"provision_key,omitempty" yaml:"provision_key,omitempty"`
+	TokenExpiration               *int     `json:"token_expiration,omitempty" yaml:"token_expiration,omitempty"`
+	EnableAuthorizationCode       *bool    `json:"enable_authorization_code,omitempty" yaml:"enable_authorization_code,omitempty"`
+	EnableImplicitGrant           *bool    `json:"enable_implicit_grant,omitempty" yaml:"enable_implicit_grant,omitempty"`
+	EnableClientCredentials       *bool    `json:"enable_client_credentials,omitempty" yaml:"enable_client_credentials,omitempty"`
+	EnablePasswordGrant           *bool    `json:"enable_password_grant,omitempty" yaml:"enable_password_grant,omitempty"`
+	HideCredentials               *bool    `json:"hide_credentials,omitempty" yaml:"hide_credentials,omitempty"`
+	AcceptHttpIfAlreadyTerminated *bool    `json:"accept_http_if_already_terminated,omitempty" yaml:"accept_http_if_already_terminated,omitempty"`
+	Anonymous                     *string  `json:"anonymous,omitempty" yaml:"anonymous,omitempty"`
+	GlobalCredentials             *bool    `json:"global_credentials,omitempty" yaml:"global_credentials,omitempty"`
+	AuthHeaderName                *string  `json:"auth_header_name,omitempty" yaml:"auth_header_name,omitempty"`
+	RefreshTokenTtl               *int     `json:"refresh_token_ttl,omitempty" yaml:"refresh_token_ttl,omitempty"`
+	ReuseRefreshToken             *bool    `json:"reuse_refresh_token,omitempty" yaml:"reuse_refresh_token,omitempty"`
+	Pkce                          *string  `json:"pkce,omitempty" yaml:"pkce,omitempty"`
+}
+
+func (*OAuth2) PluginName() string {
+	return "oauth2"
+}
+
+// Acl is the config of the acl plugin, set either Allow or Deny
+type Acl struct {
+	Allow            []string `json:"allow,omitempty" yaml:"allow,omitempty"`
+	Deny             []string `json:"deny,omitempty" yaml:"deny,omitempty"`
+	HideGroupsHeader *bool    `json:"hide_groups_header,omitempty" yaml:"hide_groups_header,omitempty"`
+}
+
+func (*Acl) PluginName() string {
+	return "acl"
+}
@@ -0,0 +1,68 @@
+// Package plugins holds typed configs for the plugins bundled with kong. A config marshals into the Config map of a
+// gokong.PluginRequest and can be decoded back from the Config map of a gokong.Plugin. Fields left nil are not sent
+// so kong fills them in with the plugin's defaults.
+package plugins
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/kevholditch/gokong"
+)
+
+// Config is implemented by the typed config of each plugin
+type Config interface {
+	// PluginName is the name kong knows the plugin by e.g. rate-limiting
+	PluginName() string
+}
+
+// ToMap returns the config as the map sent to kong in PluginRequest.Config
+func ToMap(config Config) (map[string]interface{}, error) {
+	body, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal %s config, error: %v", config.PluginName(), err)
+	}
+
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(body, &m); err != nil {
+		return nil, fmt.Errorf("could not marshal %s config, error: %v", config.PluginName(), err)
+	}
+	return m, nil
+}
+
+// Request returns a plugin request for the config, set the ids of the service, route or consumer the plugin applies
+// to on the returned request
+func Request(config Config) (*gokong.PluginRequest, error) {
+	m, err := ToMap(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gokong.PluginRequest{
+		Name:   config.PluginName(),
+		Config: m,
+	}, nil
+}
+
+// Decode fills in the config from the config of the plugin, it returns an error when the plugin is not the plugin
+// the config is for
+func Decode(plugin *gokong.Plugin, config Config) error {
+	if plugin.Name != config.PluginName() {
+		return fmt.Errorf("could not decode %s config from plugin %s", config.PluginName(), plugin.Name)
+	}
+
+	return DecodeMap(plugin.Config, config)
+}
+
+// DecodeMap fills in the config from a PluginRequest.Config or Plugin.Config map
+func DecodeMap(m map[string]interface{}, config Config) error {
+	body, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("could not decode %s config, error: %v", config.PluginName(), err)
+	}
+
+	if err := json.Unmarshal(body, config); err != nil {
+		return fmt.Errorf("could not decode %s config, error: %v", config.PluginName(), err)
+	}
+	return nil
+}
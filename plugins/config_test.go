@@ -0,0 +1,62 @@
+package plugins_test
+
+import (
+	"testing"
+
+	"github.com/kevholditch/gokong"
+	"github.com/kevholditch/gokong/kongtest"
+	"github.com/kevholditch/gokong/plugins"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ConfigsMarshalOnlyTheFieldsThatAreSet(t *testing.T) {
+	m, err := plugins.ToMap(&plugins.RateLimiting{
+		Minute:  gokong.Int(10),
+		LimitBy: gokong.String("ip"),
+		Policy:  gokong.String("local"),
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"minute": float64(10), "limit_by": "ip", "policy": "local"}, m)
+
+	pluginRequest, err := plugins.Request(&plugins.RequestTransformer{
+		Add:     &plugins.Transformation{Headers: []string{"x-source:gateway"}},
+		Replace: &plugins.ReplaceTransformation{Uri: gokong.String("/v2")},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "request-transformer", pluginRequest.Name)
+	assert.Equal(t, map[string]interface{}{
+		"add":     map[string]interface{}{"headers": []interface{}{"x-source:gateway"}},
+		"replace": map[string]interface{}{"uri": "/v2"},
+	}, pluginRequest.Config)
+}
+
+func Test_ConfigsAreDecodedFromPlugins(t *testing.T) {
+	server := kongtest.NewServer()
+	defer server.Close()
+	client := gokong.NewClient(&gokong.Config{HostAddress: server.URL})
+
+	pluginRequest, err := plugins.Request(&plugins.Cors{
+		Origins:     []string{"https://example.com"},
+		Credentials: gokong.Bool(true),
+		MaxAge:      gokong.Int(3600),
+	})
+	assert.Nil(t, err)
+
+	plugin, err := client.Plugins().Create(pluginRequest)
+	assert.Nil(t, err)
+
+	cors := &plugins.Cors{}
+	err = plugins.Decode(plugin, cors)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"https://example.com"}, cors.Origins)
+	assert.True(t, *cors.Credentials)
+	assert.Equal(t, 3600, *cors.MaxAge)
+
+	err = plugins.Decode(plugin, &plugins.KeyAuth{})
+	assert.Equal(t, "could not decode key-auth config from plugin cors", err.Error())
+
+	err = plugins.DecodeMap(map[string]interface{}{"key_names": "apikey"}, &plugins.KeyAuth{})
+	assert.NotNil(t, err)
+}
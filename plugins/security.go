@@ -0,0 +1,26 @@
+package plugins
+
+// Cors is the config of the cors plugin
+type Cors struct {
+	Origins           []string `json:"origins,omitempty" yaml:"origins,omitempty"`
+	Methods           []string `json:"methods,omitempty" yaml:"methods,omitempty"`
+	Headers           []string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	ExposedHeaders    []string `json:"exposed_headers,omitempty" yaml:"exposed_headers,omitempty"`
+	Credentials       *bool    `json:"credentials,omitempty" yaml:"credentials,omitempty"`
+	MaxAge            *int     `json:"max_age,omitempty" yaml:"max_age,omitempty"`
+	PreflightContinue *bool    `json:"preflight_continue,omitempty" yaml:"preflight_continue,omitempty"`
+}
+
+func (*Cors) PluginName() string {
+	return "cors"
+}
+
+// IpRestriction is the config of the ip-restriction plugin, set either Allow or Deny to a list of ips or cidr ranges
+type IpRestriction struct {
+	Allow []string `json:"allow,omitempty" yaml:"allow,omitempty"`
+	Deny  []string `json:"deny,omitempty" yaml:"deny,omitempty"`
+}
+
+func (*IpRestriction) PluginName() string {
+	return "ip-restriction"
+}
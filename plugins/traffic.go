@@ -0,0 +1,58 @@
+package plugins
+
+// RateLimiting is the config of the rate-limiting plugin, at least one of the limits must be set
+type RateLimiting struct {
+	Second            *int    `json:"second,omitempty" yaml:"second,omitempty"`
+	Minute            *int    `json:"minute,omitempty" yaml:"minute,omitempty"`
+	Hour              *int    `json:"hour,omitempty" yaml:"hour,omitempty"`
+	Day               *int    `json:"day,omitempty" yaml:"day,omitempty"`
+	Month             *int    `json:"month,omitempty" yaml:"month,omitempty"`
+	Year              *int    `json:"year,omitempty" yaml:"year,omitempty"`
+	LimitBy           *string `json:"limit_by,omitempty" yaml:"limit_by,omitempty"`
+	HeaderName        *string `json:"header_name,omitempty" yaml:"header_name,omitempty"`
+	Path              *string `json:"path,omitempty" yaml:"path,omitempty"`
+	Policy            *string `json:"policy,omitempty" yaml:"policy,omitempty"`
+	FaultTolerant     *bool   `json:"fault_tolerant,omitempty" yaml:"fault_tolerant,omitempty"`
+	HideClientHeaders *bool   `json:"hide_client_headers,omitempty" yaml:"hide_client_headers,omitempty"`
+	RedisHost         *string `json:"redis_host,omitempty" yaml:"redis_host,omitempty"`
+	RedisPort         *int    `json:"redis_port,omitempty" yaml:"redis_port,omitempty"`
+	RedisPassword     *string `json:"redis_password,omitempty" yaml:"redis_password,omitempty"`
+	RedisTimeout      *int    `json:"redis_timeout,omitempty" yaml:"redis_timeout,omitempty"`
+	RedisDatabase     *int    `json:"redis_database,omitempty" yaml:"redis_database,omitempty"`
+}
+
+func (*RateLimiting) PluginName() string {
+	return "rate-limiting"
+}
+
+// RequestSizeLimiting is the config of the request-size-limiting plugin
+type RequestSizeLimiting struct {
+	AllowedPayloadSize *int    `json:"allowed_payload_size,omitempty" yaml:"allowed_payload_size,omitempty"`
+	SizeUnit           *string `json:"size_unit,omitempty" yaml:"size_unit,omitempty"`
+}
+
+func (*RequestSizeLimiting) PluginName() string {
+	return "request-size-limiting"
+}
+
+// ProxyCache is the config of the proxy-cache plugin
+type ProxyCache struct {
+	ResponseCode    []int             `json:"response_code,omitempty" yaml:"response_code,omitempty"`
+	RequestMethod   []string          `json:"request_method,omitempty" yaml:"request_method,omitempty"`
+	ContentType     []string          `json:"content_type,omitempty" yaml:"content_type,omitempty"`
+	CacheTtl        *int              `json:"cache_ttl,omitempty" yaml:"cache_ttl,omitempty"`
+	Strategy        *string           `json:"strategy,omitempty" yaml:"strategy,omitempty"`
+	CacheControl    *bool             `json:"cache_control,omitempty" yaml:"cache_control,omitempty"`
+	StorageTtl      *int              `json:"storage_ttl,omitempty" yaml:"storage_ttl,omitempty"`
+	Memory          *ProxyCacheMemory `json:"memory,omitempty" yaml:"memory,omitempty"`
+	VaryQueryParams []string          `json:"vary_query_params,omitempty" yaml:"vary_query_params,omitempty"`
+	VaryHeaders     []string          `json:"vary_headers,omitempty" yaml:"vary_headers,omitempty"`
+}
+
+type ProxyCacheMemory struct {
+	DictionaryName *string `json:"dictionary_name,omitempty" yaml:"dictionary_name,omitempty"`
+}
+
+func (*ProxyCache) PluginName() string {
+	return "proxy-cache"
+}
@@ -0,0 +1,60 @@
+package plugins
+
+// RequestTransformer is the config of the request-transformer plugin, the headers, querystring and body of each
+// transformation are lists of name:value pairs or, for Remove, names
+type RequestTransformer struct {
+	HttpMethod *string                `json:"http_method,omitempty" yaml:"http_method,omitempty"`
+	Remove     *Transformation        `json:"remove,omitempty" yaml:"remove,omitempty"`
+	Rename     *Transformation        `json:"rename,omitempty" yaml:"rename,omitempty"`
+	Replace    *ReplaceTransformation `json:"replace,omitempty" yaml:"replace,omitempty"`
+	Add        *Transformation        `json:"add,omitempty" yaml:"add,omitempty"`
+	Append     *Transformation        `json:"append,omitempty" yaml:"append,omitempty"`
+}
+
+type Transformation struct {
+	Headers     []string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Querystring []string `json:"querystring,omitempty" yaml:"querystring,omitempty"`
+	Body        []string `json:"body,omitempty" yaml:"body,omitempty"`
+}
+
+type ReplaceTransformation struct {
+	Uri         *string  `json:"uri,omitempty" yaml:"uri,omitempty"`
+	Headers     []string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Querystring []string `json:"querystring,omitempty" yaml:"querystring,omitempty"`
+	Body        []string `json:"body,omitempty" yaml:"body,omitempty"`
+}
+
+func (*RequestTransformer) PluginName() string {
+	return "request-transformer"
+}
+
+// ResponseTransformer is the config of the response-transformer plugin, Json holds name:value pairs of the json
+// body and JsonTypes the type of each of them
+type ResponseTransformer struct {
+	Remove  *ResponseTransformation `json:"remove,omitempty" yaml:"remove,omitempty"`
+	Rename  *ResponseTransformation `json:"rename,omitempty" yaml:"rename,omitempty"`
+	Replace *ResponseTransformation `json:"replace,omitempty" yaml:"replace,omitempty"`
+	Add     *ResponseTransformation `json:"add,omitempty" yaml:"add,omitempty"`
+	Append  *ResponseTransformation `json:"append,omitempty" yaml:"append,omitempty"`
+}
+
+type ResponseTransformation struct {
+	Headers   []string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Json      []string `json:"json,omitempty" yaml:"json,omitempty"`
+	JsonTypes []string `json:"json_types,omitempty" yaml:"json_types,omitempty"`
+}
+
+func (*ResponseTransformer) PluginName() string {
+	return "response-transformer"
+}
+
+// CorrelationId is the config of the correlation-id plugin
+type CorrelationId struct {
+	HeaderName     *string `json:"header_name,omitempty" yaml:"header_name,omitempty"`
+	Generator      *string `json:"generator,omitempty" yaml:"generator,omitempty"`
+	EchoDownstream *bool   `json:"echo_downstream,omitempty" yaml:"echo_downstream,omitempty"`
+}
+
+func (*CorrelationId) PluginName() string {
+	return "correlation-id"
+}
@@ -1,12 +1,100 @@
 package gokong
 
 import (
+	"context"
 	"crypto/tls"
+	"io/ioutil"
+	"net/http"
 
 	"github.com/parnurzeal/gorequest"
 )
 
-func configureRequest(r *gorequest.SuperAgent, config *Config) *gorequest.SuperAgent {
+// request wraps a gorequest.SuperAgent so that the request is sent bound to a
+// context, allowing callers to cancel or put deadlines on admin api calls.
+type request struct {
+	ctx    context.Context
+	config *Config
+	agent  *gorequest.SuperAgent
+}
+
+func (r *request) Query(content interface{}) *request {
+	r.agent.Query(content)
+	return r
+}
+
+func (r *request) Send(content interface{}) *request {
+	if fieldsRequest, ok := content.(fieldsRequest); ok {
+		body, err := requestBody(fieldsRequest, r.config.KongVersion)
+		if err != nil {
+			r.agent.Errors = append(r.agent.Errors, err)
+			return r
+		}
+		content = body
+	}
+
+	r.agent.Send(content)
+	return r
+}
+
+func (r *request) End() (*http.Response, string, []error) {
+	if len(r.agent.Errors) != 0 {
+		return nil, "", r.agent.Errors
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, body, err := r.send()
+		if !r.config.RetryPolicy.shouldRetry(r.ctx, r.agent.Method, attempt, resp, err) {
+			if err != nil {
+				return nil, "", []error{err}
+			}
+			return resp, body, nil
+		}
+
+		if err := r.config.RetryPolicy.wait(r.ctx, attempt); err != nil {
+			return nil, "", []error{err}
+		}
+	}
+}
+
+func (r *request) send() (*http.Response, string, error) {
+	if err := r.ctx.Err(); err != nil {
+		return nil, "", err
+	}
+
+	req, err := r.agent.MakeRequest()
+	if err != nil {
+		return nil, "", err
+	}
+
+	resp, err := r.httpClient().Do(req.WithContext(r.ctx))
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return resp, string(body), nil
+}
+
+func (r *request) httpClient() *http.Client {
+	if r.config.HttpClient != nil {
+		return r.config.HttpClient
+	}
+
+	if r.config.Transport != nil {
+		return &http.Client{Transport: r.config.Transport}
+	}
+
+	client := r.agent.Client
+	client.Transport = r.agent.Transport
+	return client
+}
+
+func configureRequest(ctx context.Context, r *gorequest.SuperAgent, config *Config) *request {
 	r.TLSClientConfig(&tls.Config{InsecureSkipVerify: config.InsecureSkipVerify})
 	if config.Username != "" || config.Password != "" {
 		r.SetBasicAuth(config.Username, config.Password)
@@ -20,25 +108,30 @@ func configureRequest(r *gorequest.SuperAgent, config *Config) *gorequest.SuperA
 		r.Set("kong-admin-token", config.AdminToken)
 	}
 
-	return r
+	return &request{ctx: ctx, config: config, agent: r}
 }
 
-func newGet(config *Config, address string) *gorequest.SuperAgent {
+func newGet(ctx context.Context, config *Config, address string) *request {
 	r := gorequest.New().Get(address)
-	return configureRequest(r, config)
+	return configureRequest(ctx, r, config)
 }
 
-func newPost(config *Config, address string) *gorequest.SuperAgent {
+func newPost(ctx context.Context, config *Config, address string) *request {
 	r := gorequest.New().Post(address)
-	return configureRequest(r, config)
+	return configureRequest(ctx, r, config)
 }
 
-func newPatch(config *Config, address string) *gorequest.SuperAgent {
+func newPatch(ctx context.Context, config *Config, address string) *request {
 	r := gorequest.New().Patch(address)
-	return configureRequest(r, config)
+	return configureRequest(ctx, r, config)
+}
+
+func newPut(ctx context.Context, config *Config, address string) *request {
+	r := gorequest.New().Put(address)
+	return configureRequest(ctx, r, config)
 }
 
-func newDelete(config *Config, address string) *gorequest.SuperAgent {
+func newDelete(ctx context.Context, config *Config, address string) *request {
 	r := gorequest.New().Delete(address)
-	return configureRequest(r, config)
+	return configureRequest(ctx, r, config)
 }
@@ -0,0 +1,109 @@
+package gokong
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy controls how requests to kong are retried when they fail with a transient error.  Requests using
+// GET, PUT and DELETE are idempotent and are always retried, POST and PATCH requests are only retried when the
+// connection to kong could not be established (so kong never saw the request) unless RetryNonIdempotent is set.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of times a request is sent, including the first attempt
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry, it doubles on every subsequent retry
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between retries
+	MaxBackoff time.Duration
+	// RetryableStatusCodes are the response status codes from kong that are retried
+	RetryableStatusCodes []int
+	// RetryableError decides whether an error sending the request is retried, when nil all errors apart from the
+	// context being cancelled or its deadline passing are retried
+	RetryableError func(err error) bool
+	// RetryNonIdempotent allows POST and PATCH requests to be retried even when kong may have received them
+	RetryNonIdempotent bool
+}
+
+func NewDefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:          4,
+		InitialBackoff:       250 * time.Millisecond,
+		MaxBackoff:           5 * time.Second,
+		RetryableStatusCodes: []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+	}
+}
+
+func (retryPolicy *RetryPolicy) shouldRetry(ctx context.Context, method string, attempt int, resp *http.Response, err error) bool {
+	if retryPolicy == nil || attempt >= retryPolicy.MaxAttempts || ctx.Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		if isIdempotent(method) || retryPolicy.RetryNonIdempotent || isDialError(err) {
+			return retryPolicy.isRetryableError(err)
+		}
+		return false
+	}
+
+	if !isIdempotent(method) && !retryPolicy.RetryNonIdempotent {
+		return false
+	}
+
+	for _, statusCode := range retryPolicy.RetryableStatusCodes {
+		if resp.StatusCode == statusCode {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (retryPolicy *RetryPolicy) isRetryableError(err error) bool {
+	if retryPolicy.RetryableError != nil {
+		return retryPolicy.RetryableError(err)
+	}
+
+	return err != context.Canceled && err != context.DeadlineExceeded
+}
+
+// wait sleeps for the exponential backoff (with jitter) for the given attempt, returning early if ctx is done
+func (retryPolicy *RetryPolicy) wait(ctx context.Context, attempt int) error {
+	backoff := retryPolicy.InitialBackoff
+	for i := 1; i < attempt && (retryPolicy.MaxBackoff <= 0 || backoff < retryPolicy.MaxBackoff); i++ {
+		backoff *= 2
+	}
+
+	if retryPolicy.MaxBackoff > 0 && backoff > retryPolicy.MaxBackoff {
+		backoff = retryPolicy.MaxBackoff
+	}
+
+	if backoff > 0 {
+		backoff = backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+	}
+
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func isIdempotent(method string) bool {
+	return method == http.MethodGet || method == http.MethodPut || method == http.MethodDelete
+}
+
+func isDialError(err error) bool {
+	if urlError, ok := err.(interface{ Unwrap() error }); ok {
+		err = urlError.Unwrap()
+	}
+
+	opError, ok := err.(*net.OpError)
+	return ok && opError.Op == "dial"
+}
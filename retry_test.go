@@ -0,0 +1,77 @@
+package gokong
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newFlakyServer(failures int, calls *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		if *calls <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"database":{"reachable":true}}`))
+	}))
+}
+
+func newTestRetryPolicy() *RetryPolicy {
+	retryPolicy := NewDefaultRetryPolicy()
+	retryPolicy.InitialBackoff = time.Millisecond
+	retryPolicy.MaxBackoff = 5 * time.Millisecond
+	return retryPolicy
+}
+
+func Test_RetryPolicyRetriesIdempotentRequests(t *testing.T) {
+	calls := 0
+	server := newFlakyServer(2, &calls)
+	defer server.Close()
+
+	result, err := NewClient(&Config{HostAddress: server.URL, RetryPolicy: newTestRetryPolicy()}).Status().Get()
+
+	assert.Nil(t, err)
+	assert.NotNil(t, result)
+	assert.True(t, result.Database.Reachable)
+	assert.Equal(t, 3, calls)
+}
+
+func Test_RetryPolicyGivesUpAfterMaxAttempts(t *testing.T) {
+	calls := 0
+	server := newFlakyServer(10, &calls)
+	defer server.Close()
+
+	result, err := NewClient(&Config{HostAddress: server.URL, RetryPolicy: newTestRetryPolicy()}).Status().Get()
+
+	assert.Nil(t, result)
+	assert.Equal(t, http.StatusServiceUnavailable, err.(*KongError).StatusCode)
+	assert.Equal(t, 4, calls)
+}
+
+func Test_RetryPolicyDoesNotReplayNonIdempotentRequests(t *testing.T) {
+	calls := 0
+	server := newFlakyServer(1, &calls)
+	defer server.Close()
+
+	result, err := NewClient(&Config{HostAddress: server.URL, RetryPolicy: newTestRetryPolicy()}).Consumers().Create(&ConsumerRequest{Username: "foo"})
+
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func Test_NoRetryPolicyDoesNotRetry(t *testing.T) {
+	calls := 0
+	server := newFlakyServer(1, &calls)
+	defer server.Close()
+
+	result, err := NewClient(&Config{HostAddress: server.URL}).Status().Get()
+
+	assert.Nil(t, result)
+	assert.True(t, err != nil)
+	assert.Equal(t, 1, calls)
+}
@@ -1,8 +1,11 @@
 package gokong
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 )
 
 type RouteClient struct {
@@ -10,36 +13,49 @@ type RouteClient struct {
 }
 
 type RouteRequest struct {
-	Name          *string   `json:"name" yaml:"name"`
-	Protocols     []*string `json:"protocols" yaml:"protocols"`
-	Methods       []*string `json:"methods" yaml:"methods"`
-	Hosts         []*string `json:"hosts" yaml:"hosts"`
-	Paths         []*string `json:"paths" yaml:"paths"`
-	RegexPriority *int      `json:"regex_priority" yaml:"regex_priority"`
-	StripPath     *bool     `json:"strip_path" yaml:"strip_path"`
-	PreserveHost  *bool     `json:"preserve_host" yaml:"preserve_host"`
-	Snis          []*string `json:"snis" yaml:"snis"`
-	Sources       []*IpPort `json:"sources" yaml:"sources"`
-	Destinations  []*IpPort `json:"destinations" yaml:"destinations"`
-	Service       *Id       `json:"service" yaml:"service"`
+	Name                    *string             `json:"name,omitempty" yaml:"name,omitempty"`
+	Protocols               []*string           `json:"protocols,omitempty" yaml:"protocols,omitempty"`
+	Methods                 []*string           `json:"methods,omitempty" yaml:"methods,omitempty"`
+	Hosts                   []*string           `json:"hosts,omitempty" yaml:"hosts,omitempty"`
+	Paths                   []*string           `json:"paths,omitempty" yaml:"paths,omitempty"`
+	Headers                 map[string][]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	HttpsRedirectStatusCode *int                `json:"https_redirect_status_code,omitempty" yaml:"https_redirect_status_code,omitempty"`
+	RegexPriority           *int                `json:"regex_priority,omitempty" yaml:"regex_priority,omitempty"`
+	StripPath               *bool               `json:"strip_path,omitempty" yaml:"strip_path,omitempty"`
+	PathHandling            *string             `json:"path_handling,omitempty" yaml:"path_handling,omitempty"`
+	PreserveHost            *bool               `json:"preserve_host,omitempty" yaml:"preserve_host,omitempty"`
+	RequestBuffering        *bool               `json:"request_buffering,omitempty" yaml:"request_buffering,omitempty"`
+	ResponseBuffering       *bool               `json:"response_buffering,omitempty" yaml:"response_buffering,omitempty"`
+	Snis                    []*string           `json:"snis,omitempty" yaml:"snis,omitempty"`
+	Sources                 []*IpPort           `json:"sources,omitempty" yaml:"sources,omitempty"`
+	Destinations            []*IpPort           `json:"destinations,omitempty" yaml:"destinations,omitempty"`
+	Service                 *Id                 `json:"service,omitempty" yaml:"service,omitempty"`
+	Tags                    []*string           `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Fields                  `json:"-" yaml:"-"`
 }
 
 type Route struct {
-	Id            *string   `json:"id" yaml:"id"`
-	Name          *string   `json:"name" yaml:"name"`
-	CreatedAt     *int      `json:"created_at" yaml:"created_at"`
-	UpdatedAt     *int      `json:"updated_at" yaml:"updated_at"`
-	Protocols     []*string `json:"protocols" yaml:"protocols"`
-	Methods       []*string `json:"methods" yaml:"methods"`
-	Hosts         []*string `json:"hosts" yaml:"hosts"`
-	Paths         []*string `json:"paths" yaml:"paths"`
-	RegexPriority *int      `json:"regex_priority" yaml:"regex_priority"`
-	StripPath     *bool     `json:"strip_path" yaml:"strip_path"`
-	PreserveHost  *bool     `json:"preserve_host" yaml:"preserve_host"`
-	Snis          []*string `json:"snis" yaml:"snis"`
-	Sources       []*IpPort `json:"sources" yaml:"sources"`
-	Destinations  []*IpPort `json:"destinations" yaml:"destinations"`
-	Service       *Id       `json:"service" yaml:"service"`
+	Id                      *string             `json:"id" yaml:"id"`
+	Name                    *string             `json:"name" yaml:"name"`
+	CreatedAt               *int                `json:"created_at" yaml:"created_at"`
+	UpdatedAt               *int                `json:"updated_at" yaml:"updated_at"`
+	Protocols               []*string           `json:"protocols" yaml:"protocols"`
+	Methods                 []*string           `json:"methods" yaml:"methods"`
+	Hosts                   []*string           `json:"hosts" yaml:"hosts"`
+	Paths                   []*string           `json:"paths" yaml:"paths"`
+	Headers                 map[string][]string `json:"headers" yaml:"headers"`
+	HttpsRedirectStatusCode *int                `json:"https_redirect_status_code" yaml:"https_redirect_status_code"`
+	RegexPriority           *int                `json:"regex_priority" yaml:"regex_priority"`
+	StripPath               *bool               `json:"strip_path" yaml:"strip_path"`
+	PathHandling            *string             `json:"path_handling" yaml:"path_handling"`
+	PreserveHost            *bool               `json:"preserve_host" yaml:"preserve_host"`
+	RequestBuffering        *bool               `json:"request_buffering" yaml:"request_buffering"`
+	ResponseBuffering       *bool               `json:"response_buffering" yaml:"response_buffering"`
+	Snis                    []*string           `json:"snis" yaml:"snis"`
+	Sources                 []*IpPort           `json:"sources" yaml:"sources"`
+	Destinations            []*IpPort           `json:"destinations" yaml:"destinations"`
+	Service                 *Id                 `json:"service" yaml:"service"`
+	Tags                    []*string           `json:"tags" yaml:"tags"`
 }
 
 type IpPort struct {
@@ -56,22 +72,188 @@ type Routes struct {
 type RouteQueryString struct {
 	Offset string `json:"offset,omitempty"`
 	Size   int    `json:"size"`
+	Tags   string `json:"tags,omitempty"`
+}
+
+var routeProtocols = []string{"grpc", "grpcs", "http", "https", "tcp", "tls", "udp"}
+
+// routeFieldVersions are the versions of kong that added the route fields older versions reject
+var routeFieldVersions = map[string]string{
+	"tags":                       "1.1.0",
+	"https_redirect_status_code": "1.1.0",
+	"headers":                    "1.3.0",
+	"path_handling":              "2.0.0",
+	"request_buffering":          "2.3.0",
+	"response_buffering":         "2.3.0",
+}
+
+func (routeRequest *RouteRequest) fieldVersions() map[string]string {
+	return routeFieldVersions
+}
+
+// Validate checks the route against kong's schema rules for its protocols without calling kong, it returns a
+// *ValidationError listing every invalid field
+func (routeRequest *RouteRequest) Validate() error {
+	v := newValidator("route")
+
+	if routeRequest.Name != nil {
+		v.name("name", *routeRequest.Name)
+	}
+
+	protocols := StringValueSlice(routeRequest.Protocols)
+	if routeRequest.Protocols == nil {
+		protocols = []string{"http", "https"}
+	}
+	if len(protocols) == 0 {
+		v.add("protocols", "length must be at least 1")
+		return v.err()
+	}
+
+	var web, stream bool
+	for _, protocol := range protocols {
+		switch protocol {
+		case "http", "https", "grpc", "grpcs":
+			web = true
+		case "tcp", "tls", "udp":
+			stream = true
+		default:
+			v.oneOf("protocols", protocol, routeProtocols...)
+		}
+	}
+	if web && stream {
+		v.add("protocols", "http and grpc protocols cannot be mixed with tcp, tls or udp")
+	}
+
+	withSnis := has(protocols, "https", "grpcs", "tls")
+	switch {
+	case has(protocols, "http", "https"):
+		if len(routeRequest.Methods) == 0 && len(routeRequest.Hosts) == 0 && len(routeRequest.Headers) == 0 &&
+			len(routeRequest.Paths) == 0 && !(withSnis && len(routeRequest.Snis) > 0) {
+			v.add("@entity", "must set one of 'methods', 'hosts', 'headers', 'paths' when 'protocols' is 'http' or 'https'")
+		}
+	case has(protocols, "grpc", "grpcs"):
+		if len(routeRequest.Hosts) == 0 && len(routeRequest.Headers) == 0 && len(routeRequest.Paths) == 0 &&
+			!(withSnis && len(routeRequest.Snis) > 0) {
+			v.add("@entity", "must set one of 'hosts', 'headers', 'paths' when 'protocols' is 'grpc' or 'grpcs'")
+		}
+		if len(routeRequest.Methods) > 0 {
+			v.add("methods", "cannot set 'methods' when 'protocols' is 'grpc' or 'grpcs'")
+		}
+	case stream:
+		if len(routeRequest.Sources) == 0 && len(routeRequest.Destinations) == 0 && !(withSnis && len(routeRequest.Snis) > 0) {
+			v.add("@entity", "must set one of 'sources', 'destinations', 'snis' when 'protocols' is 'tcp', 'tls' or 'udp'")
+		}
+		if len(routeRequest.Methods) > 0 || len(routeRequest.Hosts) > 0 || len(routeRequest.Headers) > 0 ||
+			len(routeRequest.Paths) > 0 {
+			v.add("@entity", "cannot set 'methods', 'hosts', 'headers' or 'paths' when 'protocols' is 'tcp', 'tls' or 'udp'")
+		}
+	}
+
+	if len(routeRequest.Snis) > 0 && !withSnis {
+		v.add("snis", "'snis' can only be set when 'protocols' is 'grpcs', 'https' or 'tls'")
+	}
+	if (len(routeRequest.Sources) > 0 || len(routeRequest.Destinations) > 0) && !stream {
+		v.add("@entity", "'sources' and 'destinations' can only be set when 'protocols' is 'tcp', 'tls' or 'udp'")
+	}
+
+	for _, method := range StringValueSlice(routeRequest.Methods) {
+		if !methodPattern.MatchString(method) {
+			v.add("methods", "invalid value: %s", method)
+		}
+	}
+	for _, host := range StringValueSlice(routeRequest.Hosts) {
+		if hostAndPort := strings.SplitN(host, ":", 2); len(hostAndPort) == 2 {
+			host = hostAndPort[0]
+			if port, err := strconv.Atoi(hostAndPort[1]); err != nil || port < 0 || port > 65535 {
+				v.add("hosts", "invalid port number: %s", hostAndPort[1])
+			}
+		}
+		v.routeHost("hosts", host)
+	}
+	for _, path := range StringValueSlice(routeRequest.Paths) {
+		v.path("paths", path)
+	}
+	for name, values := range routeRequest.Headers {
+		switch {
+		case strings.EqualFold(name, "host"):
+			v.add("headers", "cannot contain 'host' header, which must be specified in the 'hosts' attribute")
+		case !headerNamePattern.MatchString(name):
+			v.add("headers", "bad header name '%s', allowed characters are A-Z, a-z, 0-9, '_', and '-'", name)
+		case len(values) == 0:
+			v.add("headers", "'%s' must have at least one value", name)
+		}
+	}
+	for _, sni := range StringValueSlice(routeRequest.Snis) {
+		v.hostname("snis", sni)
+	}
+	validateIpPorts(v, "sources", routeRequest.Sources)
+	validateIpPorts(v, "destinations", routeRequest.Destinations)
+
+	if routeRequest.RegexPriority != nil && *routeRequest.RegexPriority < 0 {
+		v.add("regex_priority", "value must be greater than or equal to 0")
+	}
+	if routeRequest.HttpsRedirectStatusCode != nil {
+		v.oneOf("https_redirect_status_code", strconv.Itoa(*routeRequest.HttpsRedirectStatusCode), "426", "301", "302", "307", "308")
+	}
+	if routeRequest.PathHandling != nil {
+		v.oneOf("path_handling", *routeRequest.PathHandling, "v0", "v1")
+	}
+
+	return v.err()
+}
+
+func validateIpPorts(v *validator, field string, ipPorts []*IpPort) {
+	for _, ipPort := range ipPorts {
+		if ipPort == nil || (ipPort.Ip == nil && ipPort.Port == nil) {
+			v.add(field, "at least one of these fields must be non-empty: 'ip', 'port'")
+			continue
+		}
+		if ipPort.Ip != nil {
+			v.ipOrCidr(field+".ip", *ipPort.Ip)
+		}
+		if ipPort.Port != nil {
+			v.between(field+".port", *ipPort.Port, 0, 65535)
+		}
+	}
+}
+
+func has(values []string, candidates ...string) bool {
+	for _, value := range values {
+		for _, candidate := range candidates {
+			if value == candidate {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 const RoutesPath = "/routes/"
 
 func (routeClient *RouteClient) GetByName(name string) (*Route, error) {
-	return routeClient.GetById(name)
+	return routeClient.GetByNameWithContext(context.Background(), name)
+}
+
+func (routeClient *RouteClient) GetByNameWithContext(ctx context.Context, name string) (*Route, error) {
+	return routeClient.GetByIdWithContext(ctx, name)
 }
 
 func (routeClient *RouteClient) GetById(id string) (*Route, error) {
-	r, body, errs := newGet(routeClient.config, routeClient.config.HostAddress+RoutesPath+id).End()
+	return routeClient.GetByIdWithContext(context.Background(), id)
+}
+
+func (routeClient *RouteClient) GetByIdWithContext(ctx context.Context, id string) (*Route, error) {
+	r, body, errs := newGet(ctx, routeClient.config, routeClient.config.HostAddress+RoutesPath+id).End()
 	if errs != nil {
 		return nil, fmt.Errorf("could not get the route, error: %v", errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return nil, fmt.Errorf("not authorised, message from kong: %s", body)
+	if r.StatusCode == 404 {
+		return nil, nil
+	}
+
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
 	}
 
 	route := &Route{}
@@ -88,13 +270,17 @@ func (routeClient *RouteClient) GetById(id string) (*Route, error) {
 }
 
 func (routeClient *RouteClient) Create(routeRequest *RouteRequest) (*Route, error) {
-	r, body, errs := newPost(routeClient.config, routeClient.config.HostAddress+RoutesPath).Send(routeRequest).End()
+	return routeClient.CreateWithContext(context.Background(), routeRequest)
+}
+
+func (routeClient *RouteClient) CreateWithContext(ctx context.Context, routeRequest *RouteRequest) (*Route, error) {
+	r, body, errs := newPost(ctx, routeClient.config, routeClient.config.HostAddress+RoutesPath).Send(routeRequest).End()
 	if errs != nil {
 		return nil, fmt.Errorf("could not register the route, error: %v", errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return nil, fmt.Errorf("not authorised, message from kong: %s", body)
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
 	}
 
 	createdRoute := &Route{}
@@ -111,90 +297,89 @@ func (routeClient *RouteClient) Create(routeRequest *RouteRequest) (*Route, erro
 }
 
 func (routeClient *RouteClient) List(query *RouteQueryString) ([]*Route, error) {
-	routes := make([]*Route, 0)
-
-	if query.Size < 100 {
-		query.Size = 100
-	}
+	return routeClient.ListWithContext(context.Background(), query)
+}
 
-	if query.Size > 1000 {
-		query.Size = 1000
-	}
+func (routeClient *RouteClient) ListWithContext(ctx context.Context, query *RouteQueryString) ([]*Route, error) {
+	routes := make([]*Route, 0)
 
-	for {
+	pages := newPager(ctx, routeClient.config, routeClient.config.HostAddress+RoutesPath, "routes", *query)
+	for pages.more() {
 		data := &Routes{}
-
-		r, body, errs := newGet(routeClient.config, routeClient.config.HostAddress+RoutesPath).Query(*query).End()
-		if errs != nil {
-			return nil, fmt.Errorf("could not get the route, error: %v", errs)
-		}
-
-		if r.StatusCode == 401 || r.StatusCode == 403 {
-			return nil, fmt.Errorf("not authorised, message from kong: %s", body)
-		}
-
-		err := json.Unmarshal([]byte(body), data)
-		if err != nil {
-			return nil, fmt.Errorf("could not parse route get response, error: %v", err)
+		if err := pages.next(data); err != nil {
+			return nil, err
 		}
 
 		routes = append(routes, data.Data...)
-
-		if data.Next == nil || *data.Next == "" {
-			break
-		}
-
-		query.Offset = data.Offset
 	}
 
 	return routes, nil
 }
 
-func (routeClient *RouteClient) GetRoutesFromServiceName(name string) ([]*Route, error) {
-	return routeClient.GetRoutesFromServiceId(name)
+// Iter returns an iterator over the routes matching the query
+func (routeClient *RouteClient) Iter(ctx context.Context, query *RouteQueryString) *RouteIterator {
+	pages := newPager(ctx, routeClient.config, routeClient.config.HostAddress+RoutesPath, "routes", *query)
+	return &RouteIterator{iterator: newIterator(pages, func() listPage { return &Routes{} })}
 }
 
-func (routeClient *RouteClient) GetRoutesFromServiceId(id string) ([]*Route, error) {
-	routes := make([]*Route, 0)
-	data := &Routes{}
+type RouteIterator struct {
+	iterator
+}
 
-	for {
-		r, body, errs := newGet(routeClient.config, routeClient.config.HostAddress+fmt.Sprintf("/services/%s/routes", id)).End()
-		if errs != nil {
-			return nil, fmt.Errorf("could not get the route, error: %v", errs)
-		}
+// Value returns the route the iterator is positioned on
+func (routeIterator *RouteIterator) Value() *Route {
+	route, _ := routeIterator.value.(*Route)
+	return route
+}
 
-		if r.StatusCode == 401 || r.StatusCode == 403 {
-			return nil, fmt.Errorf("not authorised, message from kong: %s", body)
-		}
+func (routeClient *RouteClient) GetRoutesFromServiceName(name string) ([]*Route, error) {
+	return routeClient.GetRoutesFromServiceNameWithContext(context.Background(), name)
+}
 
-		err := json.Unmarshal([]byte(body), data)
-		if err != nil {
-			return nil, fmt.Errorf("could not parse route get response, error: %v", err)
-		}
+func (routeClient *RouteClient) GetRoutesFromServiceNameWithContext(ctx context.Context, name string) ([]*Route, error) {
+	return routeClient.GetRoutesFromServiceIdWithContext(ctx, name)
+}
 
-		routes = append(routes, data.Data...)
+func (routeClient *RouteClient) GetRoutesFromServiceId(id string) ([]*Route, error) {
+	return routeClient.GetRoutesFromServiceIdWithContext(context.Background(), id)
+}
 
-		if data.Next == nil || *data.Next == "" {
-			break
+func (routeClient *RouteClient) GetRoutesFromServiceIdWithContext(ctx context.Context, id string) ([]*Route, error) {
+	routes := make([]*Route, 0)
+
+	pages := newPager(ctx, routeClient.config, routeClient.config.HostAddress+fmt.Sprintf("/services/%s/routes", id), "routes", RouteQueryString{})
+	for pages.more() {
+		data := &Routes{}
+		if err := pages.next(data); err != nil {
+			return nil, err
 		}
 
+		routes = append(routes, data.Data...)
 	}
+
 	return routes, nil
 }
 
 func (routeClient *RouteClient) UpdateByName(name string, routeRequest *RouteRequest) (*Route, error) {
-	return routeClient.UpdateById(name, routeRequest)
+	return routeClient.UpdateByNameWithContext(context.Background(), name, routeRequest)
+}
+
+func (routeClient *RouteClient) UpdateByNameWithContext(ctx context.Context, name string, routeRequest *RouteRequest) (*Route, error) {
+	return routeClient.UpdateByIdWithContext(ctx, name, routeRequest)
 }
 
 func (routeClient *RouteClient) UpdateById(id string, routeRequest *RouteRequest) (*Route, error) {
-	r, body, errs := newPatch(routeClient.config, routeClient.config.HostAddress+RoutesPath+id).Send(routeRequest).End()
+	return routeClient.UpdateByIdWithContext(context.Background(), id, routeRequest)
+}
+
+func (routeClient *RouteClient) UpdateByIdWithContext(ctx context.Context, id string, routeRequest *RouteRequest) (*Route, error) {
+	r, body, errs := newPatch(ctx, routeClient.config, routeClient.config.HostAddress+RoutesPath+id).Send(routeRequest).End()
 	if errs != nil {
 		return nil, fmt.Errorf("could not update route, error: %v", errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return nil, fmt.Errorf("not authorised, message from kong: %s", body)
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
 	}
 
 	updatedRoute := &Route{}
@@ -210,18 +395,56 @@ func (routeClient *RouteClient) UpdateById(id string, routeRequest *RouteRequest
 	return updatedRoute, nil
 }
 
+func (routeClient *RouteClient) Upsert(nameOrId string, routeRequest *RouteRequest) (*Route, error) {
+	return routeClient.UpsertWithContext(context.Background(), nameOrId, routeRequest)
+}
+
+// UpsertWithContext creates the route with the name or id or, when it exists, replaces it in full with the request so
+// fields missing from the request go back to their defaults
+func (routeClient *RouteClient) UpsertWithContext(ctx context.Context, nameOrId string, routeRequest *RouteRequest) (*Route, error) {
+
+	r, body, errs := newPut(ctx, routeClient.config, routeClient.config.HostAddress+RoutesPath+nameOrId).Send(routeRequest).End()
+	if errs != nil {
+		return nil, fmt.Errorf("could not upsert route, error: %v", errs)
+	}
+
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
+	}
+
+	upsertedRoute := &Route{}
+	err := json.Unmarshal([]byte(body), upsertedRoute)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse route upsert response, error: %v", err)
+	}
+
+	if upsertedRoute.Id == nil {
+		return nil, fmt.Errorf("could not upsert route, error: %v", body)
+	}
+
+	return upsertedRoute, nil
+}
+
 func (routeClient *RouteClient) DeleteByName(name string) error {
-	return routeClient.DeleteById(name)
+	return routeClient.DeleteByNameWithContext(context.Background(), name)
+}
+
+func (routeClient *RouteClient) DeleteByNameWithContext(ctx context.Context, name string) error {
+	return routeClient.DeleteByIdWithContext(ctx, name)
 }
 
 func (routeClient *RouteClient) DeleteById(id string) error {
-	r, body, errs := newDelete(routeClient.config, routeClient.config.HostAddress+RoutesPath+id).End()
+	return routeClient.DeleteByIdWithContext(context.Background(), id)
+}
+
+func (routeClient *RouteClient) DeleteByIdWithContext(ctx context.Context, id string) error {
+	r, body, errs := newDelete(ctx, routeClient.config, routeClient.config.HostAddress+RoutesPath+id).End()
 	if errs != nil {
 		return fmt.Errorf("could not delete the route, result: %v error: %v", r, errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return fmt.Errorf("not authorised, message from kong: %s", body)
+	if err := checkResponse(r, body); err != nil {
+		return err
 	}
 
 	return nil
@@ -451,3 +451,195 @@ func Test_AllRouteEndpointsShouldReturnErrorWhenRequestUnauthorised(t *testing.T
 	assert.NotNil(t, err)
 
 }
+
+func Test_RoutesValidateAcceptsValidRequest(t *testing.T) {
+	assert.Nil(t, (&RouteRequest{Name: String("orders"), Paths: StringSlice([]string{"/orders"})}).Validate())
+	assert.Nil(t, (&RouteRequest{
+		Protocols: StringSlice([]string{"https"}),
+		Methods:   StringSlice([]string{"GET", "POST"}),
+		Hosts:     StringSlice([]string{"*.example.com", "example.com:8443"}),
+	}).Validate())
+	assert.Nil(t, (&RouteRequest{Protocols: StringSlice([]string{"https"}), Snis: StringSlice([]string{"example.com"})}).Validate())
+	assert.Nil(t, (&RouteRequest{Protocols: StringSlice([]string{"grpc"}), Paths: StringSlice([]string{"/orders.Orders/"})}).Validate())
+	assert.Nil(t, (&RouteRequest{
+		Protocols:    StringSlice([]string{"tcp", "tls"}),
+		Sources:      IpPortSliceSlice([]IpPort{{Ip: String("10.0.0.0/8")}}),
+		Destinations: IpPortSliceSlice([]IpPort{{Ip: String("192.168.1.1"), Port: Int(5432)}}),
+	}).Validate())
+	assert.Nil(t, (&RouteRequest{Protocols: StringSlice([]string{"tls"}), Snis: StringSlice([]string{"example.com"})}).Validate())
+}
+
+func Test_RoutesValidateReportsEveryInvalidField(t *testing.T) {
+	err := (&RouteRequest{
+		Name:          String("orders route"),
+		Methods:       StringSlice([]string{"get"}),
+		Hosts:         StringSlice([]string{"example.com:99999"}),
+		Paths:         StringSlice([]string{"orders"}),
+		Snis:          StringSlice([]string{"example.com"}),
+		Sources:       IpPortSliceSlice([]IpPort{{Ip: String("10.0.0.300")}}),
+		RegexPriority: Int(-1),
+	}).Validate()
+
+	validationError, ok := err.(*ValidationError)
+	assert.True(t, ok)
+	assert.Equal(t, "route", validationError.Entity)
+
+	fields := []string{}
+	for _, fieldError := range validationError.Fields {
+		fields = append(fields, fieldError.Field)
+	}
+	assert.Equal(t, []string{"name", "@entity", "methods", "hosts", "paths", "sources.ip", "regex_priority"}, fields)
+	assert.Equal(t, "invalid port number: 99999", validationError.Field("hosts").Message)
+}
+
+func Test_RoutesValidateWildcardHosts(t *testing.T) {
+	for _, host := range []string{"*.example.com", "example.*", "api.example.*", "example.*:8000"} {
+		assert.Nil(t, (&RouteRequest{Hosts: StringSlice([]string{host})}).Validate(), host)
+	}
+
+	for _, host := range []string{"*.example.*", "example.*.com", "*", "example*"} {
+		err := (&RouteRequest{Hosts: StringSlice([]string{host})}).Validate()
+		assert.Equal(t, "invalid hostname: "+host, err.(*ValidationError).Field("hosts").Message, host)
+	}
+
+	err := (&RouteRequest{Protocols: StringSlice([]string{"https"}), Snis: StringSlice([]string{"example.*"})}).Validate()
+	assert.Equal(t, "invalid hostname: example.*", err.(*ValidationError).Field("snis").Message)
+}
+
+func Test_RoutesValidateProtocolRules(t *testing.T) {
+	err := (&RouteRequest{}).Validate()
+	assert.Equal(t, "must set one of 'methods', 'hosts', 'headers', 'paths' when 'protocols' is 'http' or 'https'", err.(*ValidationError).Field("@entity").Message)
+
+	err = (&RouteRequest{Protocols: StringSlice([]string{"tcp"}), Snis: StringSlice([]string{"example.com"})}).Validate()
+	assert.Equal(t, "must set one of 'sources', 'destinations', 'snis' when 'protocols' is 'tcp', 'tls' or 'udp'", err.(*ValidationError).Field("@entity").Message)
+	assert.Equal(t, "'snis' can only be set when 'protocols' is 'grpcs', 'https' or 'tls'", err.(*ValidationError).Field("snis").Message)
+
+	err = (&RouteRequest{Protocols: StringSlice([]string{"http", "tcp"}), Paths: StringSlice([]string{"/"})}).Validate()
+	assert.Equal(t, "http and grpc protocols cannot be mixed with tcp, tls or udp", err.(*ValidationError).Field("protocols").Message)
+
+	err = (&RouteRequest{Protocols: StringSlice([]string{"grpc"}), Methods: StringSlice([]string{"GET"}), Paths: StringSlice([]string{"/"})}).Validate()
+	assert.Equal(t, "cannot set 'methods' when 'protocols' is 'grpc' or 'grpcs'", err.(*ValidationError).Field("methods").Message)
+
+	err = (&RouteRequest{Protocols: StringSlice([]string{"ws"}), Paths: StringSlice([]string{"/"})}).Validate()
+	assert.Equal(t, "expected one of: grpc, grpcs, http, https, tcp, tls, udp", err.(*ValidationError).Field("protocols").Message)
+
+	err = (&RouteRequest{Headers: map[string][]string{"x-version": {"v1"}}}).Validate()
+	assert.Nil(t, err)
+}
+
+func Test_RoutesValidateHeadersRedirectStatusCodeAndPathHandling(t *testing.T) {
+	err := (&RouteRequest{
+		Paths:                   StringSlice([]string{"/"}),
+		Headers:                 map[string][]string{"Host": {"example.com"}},
+		HttpsRedirectStatusCode: Int(303),
+		PathHandling:            String("v2"),
+	}).Validate()
+
+	validationError := err.(*ValidationError)
+	assert.Equal(t, "cannot contain 'host' header, which must be specified in the 'hosts' attribute", validationError.Field("headers").Message)
+	assert.Equal(t, "expected one of: 426, 301, 302, 307, 308", validationError.Field("https_redirect_status_code").Message)
+	assert.Equal(t, "expected one of: v0, v1", validationError.Field("path_handling").Message)
+
+	err = (&RouteRequest{Headers: map[string][]string{"x-version": {}}}).Validate()
+	assert.Equal(t, "'x-version' must have at least one value", err.(*ValidationError).Field("headers").Message)
+}
+
+func Test_RoutesCreateAndUpdateHeadersBufferingAndPathHandling(t *testing.T) {
+	client := NewClient(NewDefaultConfig())
+	service, err := client.Services().Create(&ServiceRequest{
+		Name:     String("service-name" + uuid.NewV4().String()),
+		Protocol: String("http"),
+		Host:     String("foo.com"),
+	})
+	assert.Nil(t, err)
+
+	route, err := client.Routes().Create(&RouteRequest{
+		Headers:                 map[string][]string{"x-version": {"v1", "v2"}},
+		HttpsRedirectStatusCode: Int(308),
+		PathHandling:            String("v1"),
+		RequestBuffering:        Bool(false),
+		Tags:                    StringSlice([]string{"team-a"}),
+		Service:                 ToId(*service.Id),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string][]string{"x-version": {"v1", "v2"}}, route.Headers)
+	assert.Equal(t, 308, *route.HttpsRedirectStatusCode)
+	assert.Equal(t, "v1", *route.PathHandling)
+	assert.False(t, *route.RequestBuffering)
+	assert.True(t, *route.ResponseBuffering)
+	assert.Equal(t, StringSlice([]string{"team-a"}), route.Tags)
+	assert.NotNil(t, route.UpdatedAt)
+
+	updated, err := client.Routes().UpdateById(*route.Id, &RouteRequest{ResponseBuffering: Bool(false)})
+	assert.Nil(t, err)
+	assert.Equal(t, route.Headers, updated.Headers)
+	assert.False(t, *updated.RequestBuffering)
+	assert.False(t, *updated.ResponseBuffering)
+
+	fetched, err := client.Routes().GetById(*route.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, updated, fetched)
+
+	err = client.Routes().DeleteById(*route.Id)
+	assert.Nil(t, err)
+	err = client.Services().DeleteServiceById(*service.Id)
+	assert.Nil(t, err)
+}
+
+func Test_RouteRequestBodyLeavesOutFieldsOlderKongRejects(t *testing.T) {
+	routeRequest := &RouteRequest{
+		Paths:             StringSlice([]string{"/"}),
+		Headers:           map[string][]string{"x-version": {"v1"}},
+		PathHandling:      String("v1"),
+		RequestBuffering:  Bool(false),
+		ResponseBuffering: Bool(false),
+	}
+
+	body, err := requestBody(routeRequest, "2.0.4")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"paths":         []interface{}{"/"},
+		"headers":       map[string]interface{}{"x-version": []interface{}{"v1"}},
+		"path_handling": "v1",
+	}, body)
+
+	body, err = requestBody(routeRequest, "1.2.0-alpine")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"paths": []interface{}{"/"}}, body)
+
+	body, err = requestBody(routeRequest, "2.8.1.1-enterprise-edition")
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(body))
+}
+
+func Test_RoutesUpsertCreatesAndReplaces(t *testing.T) {
+	client := NewClient(NewDefaultConfig())
+	service, err := client.Services().Create(&ServiceRequest{
+		Name:     String("service-name" + uuid.NewV4().String()),
+		Protocol: String("http"),
+		Host:     String("foo.com"),
+	})
+	assert.Nil(t, err)
+
+	name := "route-" + uuid.NewV4().String()
+	created, err := client.Routes().Upsert(name, &RouteRequest{
+		Paths:   StringSlice([]string{"/foo"}),
+		Service: ToId(*service.Id),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, name, *created.Name)
+
+	replaced, err := client.Routes().Upsert(*created.Id, &RouteRequest{
+		Name:    String(name),
+		Paths:   StringSlice([]string{"/bar"}),
+		Service: ToId(*service.Id),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, *created.Id, *replaced.Id)
+	assert.Equal(t, StringSlice([]string{"/bar"}), replaced.Paths)
+
+	err = client.Routes().DeleteById(*created.Id)
+	assert.Nil(t, err)
+	err = client.Services().DeleteServiceById(*service.Id)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,389 @@
+package gokong
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SchemaClient fetches the schemas kong uses to validate entities and plugin configs, schemas are cached per admin
+// address so each one is only fetched once by the clients created from the same KongAdminClient
+type SchemaClient struct {
+	config *Config
+	cache  *schemaCache
+}
+
+type schemaCache struct {
+	sync.Mutex
+	schemas map[string]*Schema
+}
+
+func newSchemaCache() *schemaCache {
+	return &schemaCache{schemas: map[string]*Schema{}}
+}
+
+// Schema is the schema of a plugin or an entity, ShorthandFields are the deprecated names kong still accepts and
+// translates into Fields
+type Schema struct {
+	Fields          SchemaFields             `json:"fields" yaml:"fields"`
+	ShorthandFields SchemaFields             `json:"shorthand_fields,omitempty" yaml:"shorthand_fields,omitempty"`
+	EntityChecks    []map[string]interface{} `json:"entity_checks,omitempty" yaml:"entity_checks,omitempty"`
+}
+
+// SchemaField is a single field of a schema, Fields is set for records, Elements for arrays and sets and Keys and
+// Values for maps. ShorthandFields of a record are the other names kong accepts in it and translates into Fields
+type SchemaField struct {
+	Name            string        `json:"-" yaml:"name"`
+	Type            string        `json:"type" yaml:"type"`
+	Required        bool          `json:"required,omitempty" yaml:"required,omitempty"`
+	Default         interface{}   `json:"default,omitempty" yaml:"default,omitempty"`
+	OneOf           []interface{} `json:"one_of,omitempty" yaml:"one_of,omitempty"`
+	Between         []float64     `json:"between,omitempty" yaml:"between,omitempty"`
+	Gt              *float64      `json:"gt,omitempty" yaml:"gt,omitempty"`
+	LenMin          *int          `json:"len_min,omitempty" yaml:"len_min,omitempty"`
+	Reference       string        `json:"reference,omitempty" yaml:"reference,omitempty"`
+	Fields          SchemaFields  `json:"fields,omitempty" yaml:"fields,omitempty"`
+	Elements        *SchemaField  `json:"elements,omitempty" yaml:"elements,omitempty"`
+	Keys            *SchemaField  `json:"keys,omitempty" yaml:"keys,omitempty"`
+	Values          *SchemaField  `json:"values,omitempty" yaml:"values,omitempty"`
+	ShorthandFields SchemaFields  `json:"shorthand_fields,omitempty" yaml:"shorthand_fields,omitempty"`
+}
+
+// SchemaFields keeps the fields of a schema in the order kong returns them, kong sends each field as an object
+// holding a single key, the name of the field
+type SchemaFields []*SchemaField
+
+func (schemaFields *SchemaFields) UnmarshalJSON(data []byte) error {
+	var fields []map[string]*SchemaField
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	*schemaFields = make(SchemaFields, 0, len(fields))
+	for _, field := range fields {
+		for name, schemaField := range field {
+			schemaField.Name = name
+			*schemaFields = append(*schemaFields, schemaField)
+		}
+	}
+	return nil
+}
+
+func (schemaFields SchemaFields) MarshalJSON() ([]byte, error) {
+	fields := make([]map[string]*SchemaField, 0, len(schemaFields))
+	for _, schemaField := range schemaFields {
+		fields = append(fields, map[string]*SchemaField{schemaField.Name: schemaField})
+	}
+	return json.Marshal(fields)
+}
+
+// Field returns the field with the given name, or nil when there is no such field
+func (schemaFields SchemaFields) Field(name string) *SchemaField {
+	for _, schemaField := range schemaFields {
+		if schemaField.Name == name {
+			return schemaField
+		}
+	}
+	return nil
+}
+
+// Field returns the top level field with the given name, or nil when there is no such field
+func (schema *Schema) Field(name string) *SchemaField {
+	return schema.Fields.Field(name)
+}
+
+const SchemasPath = "/schemas/"
+
+func (schemaClient *SchemaClient) GetPluginSchema(name string) (*Schema, error) {
+	return schemaClient.GetPluginSchemaWithContext(context.Background(), name)
+}
+
+// GetPluginSchemaWithContext returns the schema of the plugin, or nil when kong has no plugin with the name
+func (schemaClient *SchemaClient) GetPluginSchemaWithContext(ctx context.Context, name string) (*Schema, error) {
+	return schemaClient.get(ctx, "plugins/"+url.PathEscape(name))
+}
+
+func (schemaClient *SchemaClient) GetEntitySchema(entity string) (*Schema, error) {
+	return schemaClient.GetEntitySchemaWithContext(context.Background(), entity)
+}
+
+// GetEntitySchemaWithContext returns the schema of an entity e.g. services or routes, or nil when kong has no entity
+// with the name
+func (schemaClient *SchemaClient) GetEntitySchemaWithContext(ctx context.Context, entity string) (*Schema, error) {
+	return schemaClient.get(ctx, url.PathEscape(entity))
+}
+
+func (schemaClient *SchemaClient) get(ctx context.Context, path string) (*Schema, error) {
+	address := schemaClient.config.HostAddress + SchemasPath + path
+
+	schemaClient.cache.Lock()
+	cached, ok := schemaClient.cache.schemas[address]
+	schemaClient.cache.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	r, body, errs := newGet(ctx, schemaClient.config, address).End()
+	if errs != nil {
+		return nil, fmt.Errorf("could not get schema, error: %v", errs)
+	}
+
+	if r.StatusCode == 404 {
+		return nil, nil
+	}
+
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
+	}
+
+	schema := &Schema{}
+	err := json.Unmarshal([]byte(body), schema)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse schema response, error: %v", err)
+	}
+
+	schemaClient.cache.Lock()
+	schemaClient.cache.schemas[address] = schema
+	schemaClient.cache.Unlock()
+
+	return schema, nil
+}
+
+func (schemaClient *SchemaClient) ValidatePlugin(pluginRequest *PluginRequest) error {
+	return schemaClient.ValidatePluginWithContext(context.Background(), pluginRequest)
+}
+
+// ValidatePluginWithContext checks the config of the plugin against the plugin's schema, it returns a
+// *ValidationError listing every invalid config field
+func (schemaClient *SchemaClient) ValidatePluginWithContext(ctx context.Context, pluginRequest *PluginRequest) error {
+	schema, err := schemaClient.GetPluginSchemaWithContext(ctx, pluginRequest.Name)
+	if err != nil {
+		return err
+	}
+
+	if schema == nil {
+		v := newValidator("plugin")
+		v.add("name", "plugin '%s' not enabled; add it to the 'plugins' configuration property", pluginRequest.Name)
+		return v.err()
+	}
+
+	return schema.ValidateConfig(pluginRequest.Config)
+}
+
+// ValidateConfig checks a plugin config against the config record of the plugin schema, fields missing from the
+// config are valid when the schema has a default for them
+func (schema *Schema) ValidateConfig(config map[string]interface{}) error {
+	v := newValidator("plugin")
+
+	configField := schema.Field("config")
+	if configField == nil {
+		if len(config) > 0 {
+			v.add("config", "unknown field")
+		}
+		return v.err()
+	}
+
+	value, err := normalise(config)
+	if err != nil {
+		v.add("config", "%v", err)
+		return v.err()
+	}
+
+	configField.validate(v, "config", value)
+	return v.err()
+}
+
+// ConfigWithDefaults returns a copy of the plugin config with the schema's defaults set for every missing field, the
+// same config kong stores when the plugin is created
+func (schema *Schema) ConfigWithDefaults(config map[string]interface{}) (map[string]interface{}, error) {
+	value, err := normalise(config)
+	if err != nil {
+		return nil, err
+	}
+
+	configField := schema.Field("config")
+	if configField == nil {
+		return config, nil
+	}
+
+	withDefaults, _ := configField.withDefaults(value).(map[string]interface{})
+	return withDefaults, nil
+}
+
+// normalise round trips the value through json so that the value only holds the types encoding/json decodes to
+func normalise(value map[string]interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	body, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var normalised interface{}
+	err = json.Unmarshal(body, &normalised)
+	return normalised, err
+}
+
+func (schemaField *SchemaField) withDefaults(value interface{}) interface{} {
+	if value == nil {
+		if schemaField.Default != nil {
+			return schemaField.Default
+		}
+		if schemaField.Type != "record" || !schemaField.Required {
+			return nil
+		}
+		value = map[string]interface{}{}
+	}
+
+	record, ok := value.(map[string]interface{})
+	if schemaField.Type != "record" || !ok {
+		return value
+	}
+
+	withDefaults := map[string]interface{}{}
+	for name, fieldValue := range record {
+		withDefaults[name] = fieldValue
+	}
+	for _, field := range schemaField.Fields {
+		withDefaults[field.Name] = field.withDefaults(record[field.Name])
+	}
+	return withDefaults
+}
+
+func (schemaField *SchemaField) validate(v *validator, field string, value interface{}) {
+	if value == nil {
+		switch {
+		case schemaField.Default != nil:
+		case schemaField.Type == "record" && schemaField.Required:
+			schemaField.validateRecord(v, field, map[string]interface{}{})
+		case schemaField.Required:
+			v.add(field, "required field missing")
+		}
+		return
+	}
+
+	switch schemaField.Type {
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			v.add(field, "expected a string")
+			return
+		}
+		if schemaField.LenMin != nil && len(s) < *schemaField.LenMin {
+			v.add(field, "length must be at least %d", *schemaField.LenMin)
+		}
+	case "number", "integer":
+		n, ok := value.(float64)
+		if !ok {
+			v.add(field, "expected %s %s", article(schemaField.Type), schemaField.Type)
+			return
+		}
+		if schemaField.Type == "integer" && n != math.Trunc(n) {
+			v.add(field, "expected an integer")
+			return
+		}
+		if len(schemaField.Between) == 2 && (n < schemaField.Between[0] || n > schemaField.Between[1]) {
+			v.add(field, "value should be between %v and %v", schemaField.Between[0], schemaField.Between[1])
+		}
+		if schemaField.Gt != nil && n <= *schemaField.Gt {
+			v.add(field, "value must be greater than %v", *schemaField.Gt)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			v.add(field, "expected a boolean")
+			return
+		}
+	case "array", "set":
+		elements, ok := value.([]interface{})
+		if !ok {
+			v.add(field, "expected %s %s", article(schemaField.Type), schemaField.Type)
+			return
+		}
+		if schemaField.LenMin != nil && len(elements) < *schemaField.LenMin {
+			v.add(field, "length must be at least %d", *schemaField.LenMin)
+		}
+		if schemaField.Elements != nil {
+			for _, element := range elements {
+				schemaField.Elements.validate(v, field, element)
+			}
+		}
+	case "map":
+		entries, ok := value.(map[string]interface{})
+		if !ok {
+			v.add(field, "expected a map")
+			return
+		}
+		keys := make([]string, 0, len(entries))
+		for key := range entries {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if schemaField.Keys != nil {
+				schemaField.Keys.validate(v, field, key)
+			}
+			if schemaField.Values != nil {
+				schemaField.Values.validate(v, field+"."+key, entries[key])
+			}
+		}
+	case "record":
+		record, ok := value.(map[string]interface{})
+		if !ok {
+			v.add(field, "expected a record")
+			return
+		}
+		schemaField.validateRecord(v, field, record)
+		return
+	}
+
+	if len(schemaField.OneOf) > 0 {
+		for _, candidate := range schemaField.OneOf {
+			if reflect.DeepEqual(candidate, value) {
+				return
+			}
+		}
+		values := make([]string, 0, len(schemaField.OneOf))
+		for _, candidate := range schemaField.OneOf {
+			values = append(values, fmt.Sprint(candidate))
+		}
+		v.add(field, "expected one of: %s", strings.Join(values, ", "))
+	}
+}
+
+func (schemaField *SchemaField) validateRecord(v *validator, field string, record map[string]interface{}) {
+	for _, recordField := range schemaField.Fields {
+		recordField.validate(v, field+"."+recordField.Name, record[recordField.Name])
+	}
+
+	for _, shorthandField := range schemaField.ShorthandFields {
+		if value, ok := record[shorthandField.Name]; ok {
+			shorthandField.validate(v, field+"."+shorthandField.Name, value)
+		}
+	}
+
+	unknown := []string{}
+	for name := range record {
+		if schemaField.Fields.Field(name) == nil && schemaField.ShorthandFields.Field(name) == nil {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		v.add(field+"."+name, "unknown field")
+	}
+}
+
+func article(noun string) string {
+	if strings.ContainsAny(noun[:1], "aeiou") {
+		return "an"
+	}
+	return "a"
+}
@@ -0,0 +1,184 @@
+package gokong
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const rateLimitingSchema = `{"fields":[
+	{"consumer":{"type":"foreign","reference":"consumers"}},
+	{"config":{"type":"record","required":true,"fields":[
+		{"minute":{"type":"number","gt":0}},
+		{"policy":{"type":"string","default":"cluster","len_min":0,"one_of":["local","cluster","redis"]}},
+		{"fault_tolerant":{"type":"boolean","required":true,"default":true}},
+		{"limit_by":{"type":"string","required":true,"one_of":["consumer","credential","ip"]}},
+		{"header_names":{"type":"array","elements":{"type":"string"}}},
+		{"redis":{"type":"record","required":true,"fields":[
+			{"host":{"type":"string"}},
+			{"port":{"type":"integer","default":6379,"between":[0,65535]}}
+		]}}
+	],"shorthand_fields":[
+		{"redis_host":{"type":"string"}},
+		{"redis_port":{"type":"integer"}}
+	]}}
+]}`
+
+func newSchemaServer(requests *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r.URL.Path)
+
+		if r.URL.Path == "/schemas/plugins/rate-limiting" {
+			fmt.Fprint(w, rateLimitingSchema)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message":"Not found"}`)
+	}))
+}
+
+func Test_SchemasAreParsedAndCached(t *testing.T) {
+	requests := []string{}
+	server := newSchemaServer(&requests)
+	defer server.Close()
+
+	client := NewClient(&Config{HostAddress: server.URL})
+
+	schema, err := client.Schemas().GetPluginSchema("rate-limiting")
+	assert.Nil(t, err)
+	assert.Equal(t, "consumers", schema.Field("consumer").Reference)
+	assert.Equal(t, "record", schema.Field("config").Type)
+	assert.Equal(t, []interface{}{"local", "cluster", "redis"}, schema.Field("config").Fields.Field("policy").OneOf)
+	assert.Equal(t, float64(6379), schema.Field("config").Fields.Field("redis").Fields.Field("port").Default)
+
+	cached, err := client.Schemas().GetPluginSchema("rate-limiting")
+	assert.Nil(t, err)
+	assert.True(t, schema == cached)
+	assert.Equal(t, []string{"/schemas/plugins/rate-limiting"}, requests)
+
+	missing, err := client.Schemas().GetEntitySchema("nothing")
+	assert.Nil(t, err)
+	assert.Nil(t, missing)
+}
+
+func Test_SchemasValidatePluginConfig(t *testing.T) {
+	requests := []string{}
+	server := newSchemaServer(&requests)
+	defer server.Close()
+
+	client := NewClient(&Config{HostAddress: server.URL})
+
+	err := client.Schemas().ValidatePlugin(&PluginRequest{
+		Name:   "rate-limiting",
+		Config: map[string]interface{}{"minute": 10, "limit_by": "ip", "header_names": []string{"X-A"}},
+	})
+	assert.Nil(t, err)
+
+	err = client.Schemas().ValidatePlugin(&PluginRequest{
+		Name: "rate-limiting",
+		Config: map[string]interface{}{
+			"minute":  0,
+			"policy":  "memory",
+			"redis":   map[string]interface{}{"port": 6379.5, "password": "secret"},
+			"minutes": 10,
+		},
+	})
+
+	validationError, ok := err.(*ValidationError)
+	assert.True(t, ok)
+	assert.Equal(t, "plugin", validationError.Entity)
+
+	fields := []string{}
+	for _, fieldError := range validationError.Fields {
+		fields = append(fields, fieldError.Field)
+	}
+	assert.Equal(t, []string{"config.minute", "config.policy", "config.limit_by", "config.redis.port", "config.redis.password", "config.minutes"}, fields)
+	assert.Equal(t, "value must be greater than 0", validationError.Field("config.minute").Message)
+	assert.Equal(t, "expected one of: local, cluster, redis", validationError.Field("config.policy").Message)
+	assert.Equal(t, "required field missing", validationError.Field("config.limit_by").Message)
+	assert.Equal(t, "expected an integer", validationError.Field("config.redis.port").Message)
+	assert.Equal(t, "unknown field", validationError.Field("config.minutes").Message)
+
+	err = client.Schemas().ValidatePlugin(&PluginRequest{Name: "no-such-plugin"})
+	assert.Equal(t, "invalid plugin, name: plugin 'no-such-plugin' not enabled; add it to the 'plugins' configuration property", err.Error())
+}
+
+func Test_SchemasAcceptShorthandFields(t *testing.T) {
+	requests := []string{}
+	server := newSchemaServer(&requests)
+	defer server.Close()
+
+	client := NewClient(&Config{HostAddress: server.URL})
+
+	schema, err := client.Schemas().GetPluginSchema("rate-limiting")
+	assert.Nil(t, err)
+	assert.Equal(t, "integer", schema.Field("config").ShorthandFields.Field("redis_port").Type)
+
+	err = client.Schemas().ValidatePlugin(&PluginRequest{
+		Name:   "rate-limiting",
+		Config: map[string]interface{}{"minute": 10, "limit_by": "ip", "redis_host": "redis.local", "redis_port": 6379},
+	})
+	assert.Nil(t, err)
+
+	err = client.Schemas().ValidatePlugin(&PluginRequest{
+		Name:   "rate-limiting",
+		Config: map[string]interface{}{"minute": 10, "limit_by": "ip", "redis_port": "6379"},
+	})
+	assert.Equal(t, "invalid plugin, config.redis_port: expected an integer", err.Error())
+}
+
+func Test_SchemasValidateOneOfArraysAndMaps(t *testing.T) {
+	schemaField := &SchemaField{OneOf: []interface{}{"all", []interface{}{"http", "https"}, map[string]interface{}{"mode": "strict"}}}
+
+	for _, value := range []interface{}{"all", []interface{}{"http", "https"}, map[string]interface{}{"mode": "strict"}} {
+		v := newValidator("plugin")
+		schemaField.validate(v, "config.modes", value)
+		assert.Nil(t, v.err())
+	}
+
+	v := newValidator("plugin")
+	schemaField.validate(v, "config.modes", []interface{}{"http"})
+	assert.Equal(t, "invalid plugin, config.modes: expected one of: all, [http https], map[mode:strict]", v.err().Error())
+}
+
+func Test_SchemasFillInConfigDefaults(t *testing.T) {
+	requests := []string{}
+	server := newSchemaServer(&requests)
+	defer server.Close()
+
+	schema, err := NewClient(&Config{HostAddress: server.URL}).Schemas().GetPluginSchema("rate-limiting")
+	assert.Nil(t, err)
+
+	config, err := schema.ConfigWithDefaults(map[string]interface{}{"minute": 10, "limit_by": "ip"})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"minute":         float64(10),
+		"policy":         "cluster",
+		"fault_tolerant": true,
+		"limit_by":       "ip",
+		"header_names":   nil,
+		"redis":          map[string]interface{}{"host": nil, "port": float64(6379)},
+	}, config)
+}
+
+func Test_SchemasFromKong(t *testing.T) {
+	client := NewClient(NewDefaultConfig())
+
+	schema, err := client.Schemas().GetPluginSchema("key-auth")
+	assert.Nil(t, err)
+	assert.NotNil(t, schema)
+	assert.Equal(t, "record", schema.Field("config").Type)
+
+	err = client.Schemas().ValidatePlugin(&PluginRequest{Name: "key-auth", Config: map[string]interface{}{"key_names": []string{"apikey"}}})
+	assert.Nil(t, err)
+
+	err = client.Schemas().ValidatePlugin(&PluginRequest{Name: "key-auth", Config: map[string]interface{}{"key_name": "apikey"}})
+	assert.Equal(t, "unknown field", err.(*ValidationError).Field("config.key_name").Message)
+
+	services, err := client.Schemas().GetEntitySchema("services")
+	assert.Nil(t, err)
+	assert.Equal(t, "string", services.Field("host").Type)
+}
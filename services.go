@@ -1,8 +1,11 @@
 package gokong
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"strconv"
 )
 
 type ServiceClient struct {
@@ -10,32 +13,45 @@ type ServiceClient struct {
 }
 
 type ServiceRequest struct {
-	Name           *string `json:"name" yaml:"name"`
-	Protocol       *string `json:"protocol" yaml:"protocol"`
-	Host           *string `json:"host" yaml:"host"`
-	Port           *int    `json:"port,omitempty" yaml:"port,omitempty"`
-	Path           *string `json:"path,omitempty" yaml:"path,omitempty"`
-	Retries        *int    `json:"retries,omitempty" yaml:"retries,omitempty"`
-	ConnectTimeout *int    `json:"connect_timeout,omitempty" yaml:"connect_timeout,omitempty"`
-	WriteTimeout   *int    `json:"write_timeout,omitempty" yaml:"write_timeout,omitempty"`
-	ReadTimeout    *int    `json:"read_timeout,omitempty" yaml:"read_timeout,omitempty"`
-	Url            *string `json:"url,omitempty" yaml:"url,omitempty"`
+	Name              *string   `json:"name,omitempty" yaml:"name,omitempty"`
+	Protocol          *string   `json:"protocol,omitempty" yaml:"protocol,omitempty"`
+	Host              *string   `json:"host,omitempty" yaml:"host,omitempty"`
+	Port              *int      `json:"port,omitempty" yaml:"port,omitempty"`
+	Path              *string   `json:"path,omitempty" yaml:"path,omitempty"`
+	Retries           *int      `json:"retries,omitempty" yaml:"retries,omitempty"`
+	ConnectTimeout    *int      `json:"connect_timeout,omitempty" yaml:"connect_timeout,omitempty"`
+	WriteTimeout      *int      `json:"write_timeout,omitempty" yaml:"write_timeout,omitempty"`
+	ReadTimeout       *int      `json:"read_timeout,omitempty" yaml:"read_timeout,omitempty"`
+	Url               *string   `json:"url,omitempty" yaml:"url,omitempty"`
+	ClientCertificate *Id       `json:"client_certificate,omitempty" yaml:"client_certificate,omitempty"`
+	TlsVerify         *bool     `json:"tls_verify,omitempty" yaml:"tls_verify,omitempty"`
+	TlsVerifyDepth    *int      `json:"tls_verify_depth,omitempty" yaml:"tls_verify_depth,omitempty"`
+	CaCertificates    []*string `json:"ca_certificates,omitempty" yaml:"ca_certificates,omitempty"`
+	Enabled           *bool     `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	Tags              []*string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Fields            `json:"-" yaml:"-"`
 }
 
 type Service struct {
-	Id             *string `json:"id" yaml:"id"`
-	CreatedAt      *int    `json:"created_at" yaml:"created_at"`
-	UpdatedAt      *int    `json:"updated_at" yaml:"updated_at"`
-	Protocol       *string `json:"protocol" yaml:"protocol"`
-	Host           *string `json:"host" yaml:"host"`
-	Port           *int    `json:"port" yaml:"port"`
-	Path           *string `json:"path" yaml:"path"`
-	Name           *string `json:"name" yaml:"name"`
-	Retries        *int    `json:"retries" yaml:"retries"`
-	ConnectTimeout *int    `json:"connect_timeout" yaml:"connect_timeout"`
-	WriteTimeout   *int    `json:"write_timeout" yaml:"write_timeout"`
-	ReadTimeout    *int    `json:"read_timeout" yaml:"read_timeout"`
-	Url            *string `json:"url" yaml:"url"`
+	Id                *string   `json:"id" yaml:"id"`
+	CreatedAt         *int      `json:"created_at" yaml:"created_at"`
+	UpdatedAt         *int      `json:"updated_at" yaml:"updated_at"`
+	Protocol          *string   `json:"protocol" yaml:"protocol"`
+	Host              *string   `json:"host" yaml:"host"`
+	Port              *int      `json:"port" yaml:"port"`
+	Path              *string   `json:"path" yaml:"path"`
+	Name              *string   `json:"name" yaml:"name"`
+	Retries           *int      `json:"retries" yaml:"retries"`
+	ConnectTimeout    *int      `json:"connect_timeout" yaml:"connect_timeout"`
+	WriteTimeout      *int      `json:"write_timeout" yaml:"write_timeout"`
+	ReadTimeout       *int      `json:"read_timeout" yaml:"read_timeout"`
+	Url               *string   `json:"url" yaml:"url"`
+	ClientCertificate *Id       `json:"client_certificate" yaml:"client_certificate"`
+	TlsVerify         *bool     `json:"tls_verify" yaml:"tls_verify"`
+	TlsVerifyDepth    *int      `json:"tls_verify_depth" yaml:"tls_verify_depth"`
+	CaCertificates    []*string `json:"ca_certificates" yaml:"ca_certificates"`
+	Enabled           *bool     `json:"enabled" yaml:"enabled"`
+	Tags              []*string `json:"tags" yaml:"tags"`
 }
 
 type Services struct {
@@ -47,11 +63,126 @@ type Services struct {
 type ServiceQueryString struct {
 	Offset string `json:"offset,omitempty"`
 	Size   int    `json:"size"`
+	Tags   string `json:"tags,omitempty"`
+}
+
+var serviceProtocols = []string{"grpc", "grpcs", "http", "https", "tcp", "tls", "udp"}
+
+// serviceFieldVersions are the versions of kong that added the service fields older versions reject
+var serviceFieldVersions = map[string]string{
+	"tags":               "1.1.0",
+	"client_certificate": "1.3.0",
+	"tls_verify":         "2.1.0",
+	"tls_verify_depth":   "2.1.0",
+	"ca_certificates":    "2.1.0",
+	"enabled":            "2.7.0",
+}
+
+func (serviceRequest *ServiceRequest) fieldVersions() map[string]string {
+	return serviceFieldVersions
+}
+
+// Validate checks the service against kong's schema rules without calling kong, it returns a *ValidationError
+// listing every invalid field
+func (serviceRequest *ServiceRequest) Validate() error {
+	v := newValidator("service")
+
+	if serviceRequest.Name != nil {
+		v.name("name", *serviceRequest.Name)
+	}
+
+	protocol, host, port, path := serviceRequest.Protocol, serviceRequest.Host, serviceRequest.Port, serviceRequest.Path
+	if serviceRequest.Url != nil {
+		if protocol != nil || host != nil || port != nil || path != nil {
+			v.add("url", "cannot be set along with 'protocol', 'host', 'port' or 'path'")
+		}
+
+		parsed, err := url.Parse(*serviceRequest.Url)
+		if err != nil || parsed.Scheme == "" || parsed.Hostname() == "" {
+			v.add("url", "missing host in url")
+			return v.err()
+		}
+
+		protocol, host, path = String(parsed.Scheme), String(parsed.Hostname()), nil
+		if parsed.Path != "" {
+			path = String(parsed.Path)
+		}
+		if parsed.Port() != "" {
+			urlPort, err := strconv.Atoi(parsed.Port())
+			if err != nil {
+				v.add("url", "invalid port in url")
+				return v.err()
+			}
+			port = Int(urlPort)
+		}
+	}
+
+	if protocol != nil {
+		v.oneOf("protocol", *protocol, serviceProtocols...)
+	}
+
+	if host == nil || *host == "" {
+		v.add("host", "required field missing")
+	} else {
+		v.hostname("host", *host)
+	}
+
+	if port != nil {
+		v.between("port", *port, 0, 65535)
+	}
+
+	if path != nil {
+		v.path("path", *path)
+		if protocol != nil && *protocol != "http" && *protocol != "https" {
+			v.add("path", "value must be null when 'protocol' is not 'http' or 'https'")
+		}
+	}
+
+	if serviceRequest.Retries != nil {
+		v.between("retries", *serviceRequest.Retries, 0, 32767)
+	}
+	if serviceRequest.ConnectTimeout != nil {
+		v.between("connect_timeout", *serviceRequest.ConnectTimeout, 1, 2147483646)
+	}
+	if serviceRequest.WriteTimeout != nil {
+		v.between("write_timeout", *serviceRequest.WriteTimeout, 1, 2147483646)
+	}
+	if serviceRequest.ReadTimeout != nil {
+		v.between("read_timeout", *serviceRequest.ReadTimeout, 1, 2147483646)
+	}
+
+	if serviceRequest.TlsVerifyDepth != nil {
+		v.between("tls_verify_depth", *serviceRequest.TlsVerifyDepth, 0, 64)
+	}
+	for _, caCertificate := range StringValueSlice(serviceRequest.CaCertificates) {
+		if !uuidPattern.MatchString(caCertificate) {
+			v.add("ca_certificates", "expected a valid UUID")
+		}
+	}
+	if protocol != nil && *protocol != "https" && *protocol != "grpcs" && *protocol != "tls" {
+		tlsFields := map[string]bool{
+			"client_certificate": serviceRequest.ClientCertificate != nil,
+			"tls_verify":         serviceRequest.TlsVerify != nil,
+			"tls_verify_depth":   serviceRequest.TlsVerifyDepth != nil,
+			"ca_certificates":    len(serviceRequest.CaCertificates) > 0,
+		}
+		for _, field := range []string{"client_certificate", "tls_verify", "tls_verify_depth", "ca_certificates"} {
+			if tlsFields[field] {
+				v.add(field, "value must be null when 'protocol' is not 'https', 'grpcs' or 'tls'")
+			}
+		}
+	}
+
+	return v.err()
 }
 
 const ServicesPath = "/services/"
 
 func (serviceClient *ServiceClient) Create(serviceRequest *ServiceRequest) (*Service, error) {
+	return serviceClient.CreateWithContext(context.Background(), serviceRequest)
+}
+
+func (serviceClient *ServiceClient) CreateWithContext(ctx context.Context, serviceRequest *ServiceRequest) (*Service, error) {
 
 	if serviceRequest.Port == nil {
 		serviceRequest.Port = Int(80)
@@ -73,13 +204,13 @@ func (serviceClient *ServiceClient) Create(serviceRequest *ServiceRequest) (*Ser
 		serviceRequest.WriteTimeout = Int(60000)
 	}
 
-	r, body, errs := newPost(serviceClient.config, serviceClient.config.HostAddress+ServicesPath).Send(serviceRequest).End()
+	r, body, errs := newPost(ctx, serviceClient.config, serviceClient.config.HostAddress+ServicesPath).Send(serviceRequest).End()
 	if errs != nil {
 		return nil, fmt.Errorf("could not register the service, error: %v", errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return nil, fmt.Errorf("not authorised, message from kong: %s", body)
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
 	}
 
 	createdService := &Service{}
@@ -96,25 +227,41 @@ func (serviceClient *ServiceClient) Create(serviceRequest *ServiceRequest) (*Ser
 }
 
 func (serviceClient *ServiceClient) GetServiceByName(name string) (*Service, error) {
-	return serviceClient.GetServiceById(name)
+	return serviceClient.GetServiceByNameWithContext(context.Background(), name)
+}
+
+func (serviceClient *ServiceClient) GetServiceByNameWithContext(ctx context.Context, name string) (*Service, error) {
+	return serviceClient.GetServiceByIdWithContext(ctx, name)
 }
 
 func (serviceClient *ServiceClient) GetServiceById(id string) (*Service, error) {
-	return serviceClient.getService(serviceClient.config.HostAddress + ServicesPath + id)
+	return serviceClient.GetServiceByIdWithContext(context.Background(), id)
+}
+
+func (serviceClient *ServiceClient) GetServiceByIdWithContext(ctx context.Context, id string) (*Service, error) {
+	return serviceClient.getService(ctx, serviceClient.config.HostAddress+ServicesPath+id)
 }
 
 func (serviceClient *ServiceClient) GetServiceFromRouteId(id string) (*Service, error) {
-	return serviceClient.getService(serviceClient.config.HostAddress + "/routes/" + id + "/service")
+	return serviceClient.GetServiceFromRouteIdWithContext(context.Background(), id)
+}
+
+func (serviceClient *ServiceClient) GetServiceFromRouteIdWithContext(ctx context.Context, id string) (*Service, error) {
+	return serviceClient.getService(ctx, serviceClient.config.HostAddress+"/routes/"+id+"/service")
 }
 
-func (serviceClient *ServiceClient) getService(endpoint string) (*Service, error) {
-	r, body, errs := newGet(serviceClient.config, endpoint).End()
+func (serviceClient *ServiceClient) getService(ctx context.Context, endpoint string) (*Service, error) {
+	r, body, errs := newGet(ctx, serviceClient.config, endpoint).End()
 	if errs != nil {
 		return nil, fmt.Errorf("could not get the service, error: %v", errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return nil, fmt.Errorf("not authorised, message from kong: %s", body)
+	if r.StatusCode == 404 {
+		return nil, nil
+	}
+
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
 	}
 
 	service := &Service{}
@@ -130,66 +277,121 @@ func (serviceClient *ServiceClient) getService(endpoint string) (*Service, error
 	return service, nil
 }
 
-func (serviceClient *ServiceClient) GetServices(query *ServiceQueryString) ([]*Service, error) {
-	services := make([]*Service, 0)
+func (serviceClient *ServiceClient) GetClientCertificate(service *Service) (*Certificate, error) {
+	return serviceClient.GetClientCertificateWithContext(context.Background(), service)
+}
 
-	if query.Size == 0 || query.Size < 100 {
-		query.Size = 100
+// GetClientCertificateWithContext returns the certificate the service presents to its upstream for mutual tls, or
+// nil when the service has none
+func (serviceClient *ServiceClient) GetClientCertificateWithContext(ctx context.Context, service *Service) (*Certificate, error) {
+	if service.ClientCertificate == nil {
+		return nil, nil
 	}
 
-	if query.Size > 1000 {
-		query.Size = 1000
+	certificateClient := &CertificateClient{config: serviceClient.config}
+	certificate, err := certificateClient.GetByIdWithContext(ctx, IdToString(service.ClientCertificate))
+	if err != nil {
+		return nil, err
+	}
+	if certificate == nil {
+		return nil, fmt.Errorf("could not find client certificate %s of service", IdToString(service.ClientCertificate))
 	}
 
-	for {
-		data := &Services{}
+	return certificate, nil
+}
 
-		r, body, errs := newGet(serviceClient.config, serviceClient.config.HostAddress+ServicesPath).Query(*query).End()
-		if errs != nil {
-			return nil, fmt.Errorf("could not get the service, error: %v", errs)
-		}
+func (serviceClient *ServiceClient) GetCACertificates(service *Service) ([]*CACertificate, error) {
+	return serviceClient.GetCACertificatesWithContext(context.Background(), service)
+}
 
-		if r.StatusCode == 401 || r.StatusCode == 403 {
-			return nil, fmt.Errorf("not authorised, message from kong: %s", body)
-		}
+// GetCACertificatesWithContext returns the ca certificates the service verifies the certificate of its upstream
+// with, in the order the service lists them
+func (serviceClient *ServiceClient) GetCACertificatesWithContext(ctx context.Context, service *Service) ([]*CACertificate, error) {
+	caCertificateClient := &CACertificateClient{config: serviceClient.config}
 
-		err := json.Unmarshal([]byte(body), data)
+	caCertificates := make([]*CACertificate, 0, len(service.CaCertificates))
+	for _, id := range StringValueSlice(service.CaCertificates) {
+		caCertificate, err := caCertificateClient.GetByIdWithContext(ctx, id)
 		if err != nil {
-			return nil, fmt.Errorf("could not parse service get response, error: %v", err)
+			return nil, err
+		}
+		if caCertificate == nil {
+			return nil, fmt.Errorf("could not find ca certificate %s of service", id)
 		}
+		caCertificates = append(caCertificates, caCertificate)
+	}
 
-		services = append(services, data.Data...)
+	return caCertificates, nil
+}
+
+func (serviceClient *ServiceClient) GetServices(query *ServiceQueryString) ([]*Service, error) {
+	return serviceClient.GetServicesWithContext(context.Background(), query)
+}
+
+func (serviceClient *ServiceClient) GetServicesWithContext(ctx context.Context, query *ServiceQueryString) ([]*Service, error) {
+	services := make([]*Service, 0)
 
-		if data.Next == nil || *data.Next == "" {
-			break
+	pages := newPager(ctx, serviceClient.config, serviceClient.config.HostAddress+ServicesPath, "services", *query)
+	for pages.more() {
+		data := &Services{}
+		if err := pages.next(data); err != nil {
+			return nil, err
 		}
 
-		query.Offset = data.Offset
+		services = append(services, data.Data...)
 	}
 
 	return services, nil
 }
 
+// Iter returns an iterator over the services matching the query
+func (serviceClient *ServiceClient) Iter(ctx context.Context, query *ServiceQueryString) *ServiceIterator {
+	pages := newPager(ctx, serviceClient.config, serviceClient.config.HostAddress+ServicesPath, "services", *query)
+	return &ServiceIterator{iterator: newIterator(pages, func() listPage { return &Services{} })}
+}
+
+type ServiceIterator struct {
+	iterator
+}
+
+// Value returns the service the iterator is positioned on
+func (serviceIterator *ServiceIterator) Value() *Service {
+	service, _ := serviceIterator.value.(*Service)
+	return service
+}
+
 func (serviceClient *ServiceClient) UpdateServiceByName(name string, serviceRequest *ServiceRequest) (*Service, error) {
-	return serviceClient.UpdateServiceById(name, serviceRequest)
+	return serviceClient.UpdateServiceByNameWithContext(context.Background(), name, serviceRequest)
+}
+
+func (serviceClient *ServiceClient) UpdateServiceByNameWithContext(ctx context.Context, name string, serviceRequest *ServiceRequest) (*Service, error) {
+	return serviceClient.UpdateServiceByIdWithContext(ctx, name, serviceRequest)
 }
 
 func (serviceClient *ServiceClient) UpdateServiceById(id string, serviceRequest *ServiceRequest) (*Service, error) {
-	return serviceClient.updateService(serviceClient.config.HostAddress+ServicesPath+id, serviceRequest)
+	return serviceClient.UpdateServiceByIdWithContext(context.Background(), id, serviceRequest)
+}
+
+func (serviceClient *ServiceClient) UpdateServiceByIdWithContext(ctx context.Context, id string, serviceRequest *ServiceRequest) (*Service, error) {
+	return serviceClient.updateService(ctx, serviceClient.config.HostAddress+ServicesPath+id, serviceRequest)
 }
 
 func (serviceClient *ServiceClient) UpdateServicebyRouteId(id string, serviceRequest *ServiceRequest) (*Service, error) {
-	return serviceClient.updateService(serviceClient.config.HostAddress+"/routes/"+id+"/service", serviceRequest)
+	return serviceClient.UpdateServicebyRouteIdWithContext(context.Background(), id, serviceRequest)
 }
 
-func (serviceClient *ServiceClient) updateService(endpoint string, serviceRequest *ServiceRequest) (*Service, error) {
-	r, body, errs := newPatch(serviceClient.config, endpoint).Send(serviceRequest).End()
+func (serviceClient *ServiceClient) UpdateServicebyRouteIdWithContext(ctx context.Context, id string, serviceRequest *ServiceRequest) (*Service, error) {
+	return serviceClient.updateService(ctx, serviceClient.config.HostAddress+"/routes/"+id+"/service", serviceRequest)
+}
+
+func (serviceClient *ServiceClient) updateService(ctx context.Context, endpoint string, serviceRequest *ServiceRequest) (*Service, error) {
+	r, body, errs := newPatch(ctx, serviceClient.config, endpoint).Send(serviceRequest).End()
 	if errs != nil {
 		return nil, fmt.Errorf("could not update service, error: %v", errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return nil, fmt.Errorf("not authorised, message from kong: %s", body)
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
 	}
 
 	updatedService := &Service{}
@@ -205,22 +407,56 @@ func (serviceClient *ServiceClient) updateService(endpoint string, serviceReques
 	return updatedService, nil
 }
 
+func (serviceClient *ServiceClient) Upsert(nameOrId string, serviceRequest *ServiceRequest) (*Service, error) {
+	return serviceClient.UpsertWithContext(context.Background(), nameOrId, serviceRequest)
+}
+
+// UpsertWithContext creates the service with the name or id or, when it exists, replaces it in full with the request so
+// fields missing from the request go back to their defaults
+func (serviceClient *ServiceClient) UpsertWithContext(ctx context.Context, nameOrId string, serviceRequest *ServiceRequest) (*Service, error) {
+
+	r, body, errs := newPut(ctx, serviceClient.config, serviceClient.config.HostAddress+ServicesPath+nameOrId).Send(serviceRequest).End()
+	if errs != nil {
+		return nil, fmt.Errorf("could not upsert service, error: %v", errs)
+	}
+
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
+	}
+
+	upsertedService := &Service{}
+	err := json.Unmarshal([]byte(body), upsertedService)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse service upsert response, error: %v", err)
+	}
+
+	if upsertedService.Id == nil {
+		return nil, fmt.Errorf("could not upsert service, error: %v", body)
+	}
+
+	return upsertedService, nil
+}
+
 func (serviceClient *ServiceClient) DeleteServiceByName(name string) error {
-	return serviceClient.DeleteServiceById(name)
+	return serviceClient.DeleteServiceByNameWithContext(context.Background(), name)
+}
+
+func (serviceClient *ServiceClient) DeleteServiceByNameWithContext(ctx context.Context, name string) error {
+	return serviceClient.DeleteServiceByIdWithContext(ctx, name)
 }
 
 func (serviceClient *ServiceClient) DeleteServiceById(id string) error {
-	r, body, errs := newDelete(serviceClient.config, serviceClient.config.HostAddress+ServicesPath+id).End()
+	return serviceClient.DeleteServiceByIdWithContext(context.Background(), id)
+}
+
+func (serviceClient *ServiceClient) DeleteServiceByIdWithContext(ctx context.Context, id string) error {
+	r, body, errs := newDelete(ctx, serviceClient.config, serviceClient.config.HostAddress+ServicesPath+id).End()
 	if errs != nil {
 		return fmt.Errorf("could not delete the service, result: %v error: %v", r, errs)
 	}
 
-	if r.StatusCode == 400 {
-		return fmt.Errorf("bad request, message from kong: %s", body)
-	}
-
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return fmt.Errorf("not authorised, message from kong: %s", body)
+	if err := checkResponse(r, body); err != nil {
+		return err
 	}
 
 	return nil
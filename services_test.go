@@ -1,7 +1,9 @@
 package gokong
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"testing"
 
 	uuid "github.com/satori/go.uuid"
@@ -185,3 +187,220 @@ func Test_AllServiceEndpointsShouldReturnErrorWhenRequestUnauthorised(t *testing
 	assert.NotNil(t, err)
 
 }
+
+func Test_ServiceEndpointsShouldReturnErrorWhenContextCancelled(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	client := NewClient(NewDefaultConfig())
+
+	results, err := client.Services().GetServicesWithContext(ctx, &ServiceQueryString{})
+	assert.NotNil(t, err)
+	assert.Nil(t, results)
+
+	s, err := client.Services().GetServiceByIdWithContext(ctx, uuid.NewV4().String())
+	assert.NotNil(t, err)
+	assert.Nil(t, s)
+
+	newService, err := client.Services().CreateWithContext(ctx, &ServiceRequest{
+		Name:     String("service-name" + uuid.NewV4().String()),
+		Protocol: String("http"),
+		Host:     String("foo.com"),
+	})
+	assert.NotNil(t, err)
+	assert.Nil(t, newService)
+}
+
+func Test_ServiceEndpointsShouldReturnKongErrors(t *testing.T) {
+
+	serviceRequest := &ServiceRequest{
+		Name:     String("service-name" + uuid.NewV4().String()),
+		Protocol: String("http"),
+		Host:     String("foo.com"),
+	}
+
+	client := NewClient(NewDefaultConfig())
+
+	createdService, err := client.Services().Create(serviceRequest)
+	assert.Nil(t, err)
+	assert.NotNil(t, createdService)
+
+	duplicateService, err := client.Services().Create(serviceRequest)
+	assert.Nil(t, duplicateService)
+	assert.True(t, IsConflict(err))
+
+	invalidService, err := client.Services().Create(&ServiceRequest{
+		Name:     String("service-name" + uuid.NewV4().String()),
+		Protocol: String("foo"),
+		Host:     String("foo.com"),
+	})
+	assert.Nil(t, invalidService)
+	assert.True(t, IsSchemaViolation(err))
+	assert.Contains(t, err.(*KongError).Fields, "protocol")
+
+	unauthorisedClient := NewClient(&Config{HostAddress: os.Getenv(kong401Server)})
+	services, err := unauthorisedClient.Services().GetServices(&ServiceQueryString{})
+	assert.Nil(t, services)
+	assert.True(t, IsUnauthorized(err))
+
+	err = client.Services().DeleteServiceById(*createdService.Id)
+	assert.Nil(t, err)
+}
+
+func Test_ServicesValidateAcceptsValidRequest(t *testing.T) {
+	assert.Nil(t, (&ServiceRequest{Name: String("orders"), Url: String("https://orders.internal:8443/v1")}).Validate())
+	assert.Nil(t, (&ServiceRequest{
+		Name:           String("orders"),
+		Protocol:       String("http"),
+		Host:           String("orders.internal"),
+		Port:           Int(8080),
+		Path:           String("/v1"),
+		Retries:        Int(0),
+		ConnectTimeout: Int(1000),
+	}).Validate())
+	assert.Nil(t, (&ServiceRequest{Protocol: String("tcp"), Host: String("10.0.0.1"), Port: Int(5432)}).Validate())
+}
+
+func Test_ServicesValidateReportsEveryInvalidField(t *testing.T) {
+	err := (&ServiceRequest{
+		Name:           String("orders service"),
+		Protocol:       String("ftp"),
+		Host:           String("orders_internal"),
+		Port:           Int(70000),
+		Path:           String("v1"),
+		Retries:        Int(-1),
+		ConnectTimeout: Int(0),
+		ReadTimeout:    Int(0),
+	}).Validate()
+
+	validationError, ok := err.(*ValidationError)
+	assert.True(t, ok)
+	assert.Equal(t, "service", validationError.Entity)
+
+	fields := []string{}
+	for _, fieldError := range validationError.Fields {
+		fields = append(fields, fieldError.Field)
+	}
+	assert.Equal(t, []string{"name", "protocol", "host", "port", "path", "path", "retries", "connect_timeout", "read_timeout"}, fields)
+	assert.Equal(t, "value should be between 0 and 65535", validationError.Field("port").Message)
+}
+
+func Test_ServicesValidateUrl(t *testing.T) {
+	err := (&ServiceRequest{Url: String("http://orders.internal"), Host: String("orders.internal")}).Validate()
+	assert.Equal(t, "cannot be set along with 'protocol', 'host', 'port' or 'path'", err.(*ValidationError).Field("url").Message)
+
+	err = (&ServiceRequest{Url: String("orders.internal")}).Validate()
+	assert.Equal(t, "missing host in url", err.(*ValidationError).Field("url").Message)
+
+	err = (&ServiceRequest{Url: String("tcp://orders.internal:5432/v1")}).Validate()
+	assert.Equal(t, "value must be null when 'protocol' is not 'http' or 'https'", err.(*ValidationError).Field("path").Message)
+
+	err = (&ServiceRequest{Name: String("orders")}).Validate()
+	assert.Equal(t, "required field missing", err.(*ValidationError).Field("host").Message)
+}
+
+func Test_ServicesUpsertCreatesAndReplaces(t *testing.T) {
+	client := NewClient(NewDefaultConfig())
+	name := "service-" + uuid.NewV4().String()
+
+	created, err := client.Services().Upsert(name, &ServiceRequest{
+		Name:     String(name),
+		Protocol: String("http"),
+		Host:     String("foo.com"),
+		Retries:  Int(2),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, name, *created.Name)
+	assert.Equal(t, 2, *created.Retries)
+
+	replaced, err := client.Services().Upsert(name, &ServiceRequest{
+		Name:     String(name),
+		Protocol: String("http"),
+		Host:     String("bar.com"),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, *created.Id, *replaced.Id)
+	assert.Equal(t, "bar.com", *replaced.Host)
+	assert.Equal(t, 5, *replaced.Retries)
+
+	err = client.Services().DeleteServiceById(*created.Id)
+	assert.Nil(t, err)
+}
+
+func Test_ServicesTlsSettingsAndClientCertificate(t *testing.T) {
+	client := NewClient(NewDefaultConfig())
+	certificate, err := client.Certificates().Create(&CertificateRequest{Cert: String(testCert1), Key: String(testKey1)})
+	assert.Nil(t, err)
+
+	service, err := client.Services().Create(&ServiceRequest{
+		Name:              String("service-name-" + uuid.NewV4().String()),
+		Protocol:          String("https"),
+		Host:              String("foo.com"),
+		ClientCertificate: ToId(*certificate.Id),
+		TlsVerify:         Bool(true),
+		TlsVerifyDepth:    Int(4),
+		Tags:              StringSlice([]string{"mtls"}),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, *certificate.Id, IdToString(service.ClientCertificate))
+	assert.True(t, *service.TlsVerify)
+	assert.Equal(t, 4, *service.TlsVerifyDepth)
+	assert.True(t, *service.Enabled)
+
+	clientCertificate, err := client.Services().GetClientCertificate(service)
+	assert.Nil(t, err)
+	assert.Equal(t, certificate.Id, clientCertificate.Id)
+
+	caCertificates, err := client.Services().GetCACertificates(service)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(caCertificates))
+
+	updated, err := client.Services().UpdateServiceById(*service.Id, &ServiceRequest{Enabled: Bool(false)})
+	assert.Nil(t, err)
+	assert.False(t, *updated.Enabled)
+	assert.Equal(t, service.ClientCertificate, updated.ClientCertificate)
+
+	missing := uuid.NewV4().String()
+	_, err = client.Services().GetCACertificates(&Service{CaCertificates: StringSlice([]string{missing})})
+	assert.Equal(t, "could not find ca certificate "+missing+" of service", err.Error())
+
+	err = client.Services().DeleteServiceById(*service.Id)
+	assert.Nil(t, err)
+	err = client.Certificates().DeleteById(*certificate.Id)
+	assert.Nil(t, err)
+}
+
+func Test_ServicesValidateTlsSettings(t *testing.T) {
+	err := (&ServiceRequest{
+		Protocol:       String("http"),
+		Host:           String("foo.com"),
+		TlsVerify:      Bool(true),
+		TlsVerifyDepth: Int(65),
+		CaCertificates: StringSlice([]string{"not-a-uuid"}),
+	}).Validate()
+
+	validationError := err.(*ValidationError)
+	assert.Equal(t, "value must be null when 'protocol' is not 'https', 'grpcs' or 'tls'", validationError.Field("tls_verify").Message)
+	assert.Equal(t, "value should be between 0 and 64", validationError.Field("tls_verify_depth").Message)
+	assert.Equal(t, "expected a valid UUID", validationError.Field("ca_certificates").Message)
+
+	err = (&ServiceRequest{Url: String("https://foo.com"), TlsVerify: Bool(true), ClientCertificate: ToId(uuid.NewV4().String())}).Validate()
+	assert.Nil(t, err)
+}
+
+func Test_ServiceRequestBodyLeavesOutFieldsOlderKongRejects(t *testing.T) {
+	serviceRequest := &ServiceRequest{
+		Host:      String("foo.com"),
+		TlsVerify: Bool(true),
+		Enabled:   Bool(false),
+	}
+
+	body, err := requestBody(serviceRequest, "2.5.0-ubuntu")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"host": "foo.com", "tls_verify": true}, body)
+
+	body, err = requestBody(serviceRequest, "2.0.0")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"host": "foo.com"}, body)
+}
@@ -1,6 +1,7 @@
 package gokong
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 )
@@ -10,31 +11,46 @@ type SnisClient struct {
 }
 
 type SnisRequest struct {
-	Name          string `json:"name,omitempty" yaml:"name,omitempty"`
-	CertificateId *Id    `json:"certificate,omitempty" yaml:"certificate,omitempty"`
+	Name          string    `json:"name,omitempty" yaml:"name,omitempty"`
+	CertificateId *Id       `json:"certificate,omitempty" yaml:"certificate,omitempty"`
+	Tags          []*string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Fields        `json:"-" yaml:"-"`
 }
 
 type Sni struct {
-	Name          string `json:"name,omitempty" yaml:"name,omitempty"`
-	CertificateId *Id    `json:"certificate,omitempty" yaml:"certificate,omitempty"`
+	Name          string    `json:"name,omitempty" yaml:"name,omitempty"`
+	CertificateId *Id       `json:"certificate,omitempty" yaml:"certificate,omitempty"`
+	Tags          []*string `json:"tags,omitempty" yaml:"tags,omitempty"`
 }
 
 type Snis struct {
 	Results []*Sni `json:"data,omitempty" yaml:"data,omitempty"`
 	Total   int    `json:"total,omitempty" yaml:"total,omitempty"`
+	Next    string `json:"next,omitempty" yaml:"next,omitempty"`
+	Offset  string `json:"offset,omitempty" yaml:"offset,omitempty"`
+}
+
+type SniQueryString struct {
+	Offset string `json:"offset,omitempty"`
+	Size   int    `json:"size"`
+	Tags   string `json:"tags,omitempty"`
 }
 
 const SnisPath = "/snis/"
 
 func (snisClient *SnisClient) Create(snisRequest *SnisRequest) (*Sni, error) {
+	return snisClient.CreateWithContext(context.Background(), snisRequest)
+}
 
-	r, body, errs := newPost(snisClient.config, snisClient.config.HostAddress+SnisPath).Send(snisRequest).End()
+func (snisClient *SnisClient) CreateWithContext(ctx context.Context, snisRequest *SnisRequest) (*Sni, error) {
+
+	r, body, errs := newPost(ctx, snisClient.config, snisClient.config.HostAddress+SnisPath).Send(snisRequest).End()
 	if errs != nil {
 		return nil, fmt.Errorf("could not create new sni, error: %v", errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return nil, fmt.Errorf("not authorised, message from kong: %s", body)
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
 	}
 
 	sni := &Sni{}
@@ -51,14 +67,22 @@ func (snisClient *SnisClient) Create(snisRequest *SnisRequest) (*Sni, error) {
 }
 
 func (snisClient *SnisClient) GetByName(name string) (*Sni, error) {
+	return snisClient.GetByNameWithContext(context.Background(), name)
+}
+
+func (snisClient *SnisClient) GetByNameWithContext(ctx context.Context, name string) (*Sni, error) {
 
-	r, body, errs := newGet(snisClient.config, snisClient.config.HostAddress+SnisPath+name).End()
+	r, body, errs := newGet(ctx, snisClient.config, snisClient.config.HostAddress+SnisPath+name).End()
 	if errs != nil {
 		return nil, fmt.Errorf("could not get sni, error: %v", errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return nil, fmt.Errorf("not authorised, message from kong: %s", body)
+	if r.StatusCode == 404 {
+		return nil, nil
+	}
+
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
 	}
 
 	sni := &Sni{}
@@ -75,48 +99,87 @@ func (snisClient *SnisClient) GetByName(name string) (*Sni, error) {
 }
 
 func (snisClient *SnisClient) List() (*Snis, error) {
+	return snisClient.ListWithContext(context.Background())
+}
 
-	r, body, errs := newGet(snisClient.config, snisClient.config.HostAddress+SnisPath).End()
-	if errs != nil {
-		return nil, fmt.Errorf("could not get snis, error: %v", errs)
+// ListWithContext returns every sni, following pagination
+func (snisClient *SnisClient) ListWithContext(ctx context.Context) (*Snis, error) {
+	results, err := snisClient.GetSnisWithContext(ctx, &SniQueryString{})
+	if err != nil {
+		return nil, err
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return nil, fmt.Errorf("not authorised, message from kong: %s", body)
-	}
+	return &Snis{Results: results}, nil
+}
 
-	snis := &Snis{}
-	err := json.Unmarshal([]byte(body), snis)
-	if err != nil {
-		return nil, fmt.Errorf("could not parse snis list response, error: %v", err)
+func (snisClient *SnisClient) GetSnis(query *SniQueryString) ([]*Sni, error) {
+	return snisClient.GetSnisWithContext(context.Background(), query)
+}
+
+// GetSnisWithContext returns every sni matching the query, following pagination
+func (snisClient *SnisClient) GetSnisWithContext(ctx context.Context, query *SniQueryString) ([]*Sni, error) {
+	snis := make([]*Sni, 0)
+
+	pages := newPager(ctx, snisClient.config, snisClient.config.HostAddress+SnisPath, "snis", *query)
+	for pages.more() {
+		data := &Snis{}
+		if err := pages.next(data); err != nil {
+			return nil, err
+		}
+
+		snis = append(snis, data.Results...)
 	}
 
 	return snis, nil
 }
 
+// Iter returns an iterator over the snis matching the query
+func (snisClient *SnisClient) Iter(ctx context.Context, query *SniQueryString) *SniIterator {
+	pages := newPager(ctx, snisClient.config, snisClient.config.HostAddress+SnisPath, "snis", *query)
+	return &SniIterator{iterator: newIterator(pages, func() listPage { return &Snis{} })}
+}
+
+type SniIterator struct {
+	iterator
+}
+
+// Value returns the sni the iterator is positioned on
+func (sniIterator *SniIterator) Value() *Sni {
+	sni, _ := sniIterator.value.(*Sni)
+	return sni
+}
+
 func (snisClient *SnisClient) DeleteByName(name string) error {
+	return snisClient.DeleteByNameWithContext(context.Background(), name)
+}
+
+func (snisClient *SnisClient) DeleteByNameWithContext(ctx context.Context, name string) error {
 
-	r, body, errs := newDelete(snisClient.config, snisClient.config.HostAddress+SnisPath+name).End()
+	r, body, errs := newDelete(ctx, snisClient.config, snisClient.config.HostAddress+SnisPath+name).End()
 	if errs != nil {
 		return fmt.Errorf("could not delete sni, result: %v error: %v", r, errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return fmt.Errorf("not authorised, message from kong: %s", body)
+	if err := checkResponse(r, body); err != nil {
+		return err
 	}
 
 	return nil
 }
 
 func (snisClient *SnisClient) UpdateByName(name string, snisRequest *SnisRequest) (*Sni, error) {
+	return snisClient.UpdateByNameWithContext(context.Background(), name, snisRequest)
+}
 
-	r, body, errs := newPatch(snisClient.config, snisClient.config.HostAddress+SnisPath+name).Send(snisRequest).End()
+func (snisClient *SnisClient) UpdateByNameWithContext(ctx context.Context, name string, snisRequest *SnisRequest) (*Sni, error) {
+
+	r, body, errs := newPatch(ctx, snisClient.config, snisClient.config.HostAddress+SnisPath+name).Send(snisRequest).End()
 	if errs != nil {
 		return nil, fmt.Errorf("could not update sni, error: %v", errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return nil, fmt.Errorf("not authorised, message from kong: %s", body)
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
 	}
 
 	updatedSni := &Sni{}
@@ -131,3 +194,33 @@ func (snisClient *SnisClient) UpdateByName(name string, snisRequest *SnisRequest
 
 	return updatedSni, nil
 }
+
+func (snisClient *SnisClient) Upsert(nameOrId string, snisRequest *SnisRequest) (*Sni, error) {
+	return snisClient.UpsertWithContext(context.Background(), nameOrId, snisRequest)
+}
+
+// UpsertWithContext creates the sni with the name or id or, when it exists, replaces it in full with the request so
+// fields missing from the request go back to their defaults
+func (snisClient *SnisClient) UpsertWithContext(ctx context.Context, nameOrId string, snisRequest *SnisRequest) (*Sni, error) {
+
+	r, body, errs := newPut(ctx, snisClient.config, snisClient.config.HostAddress+SnisPath+nameOrId).Send(snisRequest).End()
+	if errs != nil {
+		return nil, fmt.Errorf("could not upsert sni, error: %v", errs)
+	}
+
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
+	}
+
+	upsertedSni := &Sni{}
+	err := json.Unmarshal([]byte(body), upsertedSni)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse sni upsert response, error: %v", err)
+	}
+
+	if upsertedSni.CertificateId == nil {
+		return nil, fmt.Errorf("could not upsert sni, error: %v", body)
+	}
+
+	return upsertedSni, nil
+}
@@ -281,3 +281,23 @@ func Test_AllSniEndpointsShouldReturnErrorWhenRequestUnauthorised(t *testing.T)
 	assert.NotNil(t, err)
 
 }
+
+func Test_SnisAndCertificatesUpsert(t *testing.T) {
+	client := NewClient(NewDefaultConfig())
+	id := uuid.NewV4().String()
+
+	certificate, err := client.Certificates().Upsert(id, &CertificateRequest{Cert: String(testCert1), Key: String(testKey1)})
+	assert.Nil(t, err)
+	assert.Equal(t, id, *certificate.Id)
+
+	name := uuid.NewV4().String() + ".example.com"
+	sni, err := client.Snis().Upsert(name, &SnisRequest{CertificateId: ToId(id)})
+	assert.Nil(t, err)
+	assert.Equal(t, name, sni.Name)
+	assert.Equal(t, id, IdToString(sni.CertificateId))
+
+	err = client.Snis().DeleteByName(name)
+	assert.Nil(t, err)
+	err = client.Certificates().DeleteById(id)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,64 @@
+package gokong
+
+// State is a declarative, name keyed document describing the entities configured in kong.  Routes are nested under
+// their service, targets under their upstream and plugins under the service, route or consumer they apply to.  Routes
+// at the top level have no service.  Plugins at the top level are global unless they reference the entities they
+// apply to by name.  Entities without a name are referenced by the id they had in the kong they were dumped from,
+// which Dump records as the Id of each state.
+type State struct {
+	Services     []*ServiceState     `json:"services,omitempty" yaml:"services,omitempty"`
+	Routes       []*RouteState       `json:"routes,omitempty" yaml:"routes,omitempty"`
+	Consumers    []*ConsumerState    `json:"consumers,omitempty" yaml:"consumers,omitempty"`
+	Upstreams    []*UpstreamState    `json:"upstreams,omitempty" yaml:"upstreams,omitempty"`
+	Certificates []*CertificateState `json:"certificates,omitempty" yaml:"certificates,omitempty"`
+	// CACertificates are referenced by services, along with their client certificate, by their snapshot id
+	CACertificates []*CACertificateState `json:"ca_certificates,omitempty" yaml:"ca_certificates,omitempty"`
+	Plugins        []*PluginState        `json:"plugins,omitempty" yaml:"plugins,omitempty"`
+}
+
+type ServiceState struct {
+	ServiceRequest `yaml:",inline"`
+	Id             string         `json:"id,omitempty" yaml:"id,omitempty"`
+	Routes         []*RouteState  `json:"routes,omitempty" yaml:"routes,omitempty"`
+	Plugins        []*PluginState `json:"plugins,omitempty" yaml:"plugins,omitempty"`
+}
+
+type RouteState struct {
+	RouteRequest `yaml:",inline"`
+	Id           string         `json:"id,omitempty" yaml:"id,omitempty"`
+	Plugins      []*PluginState `json:"plugins,omitempty" yaml:"plugins,omitempty"`
+}
+
+type ConsumerState struct {
+	ConsumerRequest `yaml:",inline"`
+	Id              string         `json:"id,omitempty" yaml:"id,omitempty"`
+	Plugins         []*PluginState `json:"plugins,omitempty" yaml:"plugins,omitempty"`
+}
+
+type UpstreamState struct {
+	UpstreamRequest `yaml:",inline"`
+	Id              string           `json:"id,omitempty" yaml:"id,omitempty"`
+	Targets         []*TargetRequest `json:"targets,omitempty" yaml:"targets,omitempty"`
+}
+
+// CertificateState describes a certificate along with the snis that use it (via CertificateRequest.SNIs)
+type CertificateState struct {
+	CertificateRequest `yaml:",inline"`
+	Id                 string `json:"id,omitempty" yaml:"id,omitempty"`
+}
+
+// CACertificateState describes a ca certificate services use to verify their upstream
+type CACertificateState struct {
+	CACertificateRequest `yaml:",inline"`
+	Id                   string `json:"id,omitempty" yaml:"id,omitempty"`
+}
+
+// PluginState describes a plugin, ServiceName, RouteName and ConsumerName are only used by top level plugins to
+// reference the entities the plugin applies to
+type PluginState struct {
+	PluginRequest `yaml:",inline"`
+	Id            string `json:"id,omitempty" yaml:"id,omitempty"`
+	ServiceName   string `json:"service_name,omitempty" yaml:"service_name,omitempty"`
+	RouteName     string `json:"route_name,omitempty" yaml:"route_name,omitempty"`
+	ConsumerName  string `json:"consumer_name,omitempty" yaml:"consumer_name,omitempty"`
+}
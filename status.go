@@ -1,9 +1,11 @@
 package gokong
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 )
 
 type StatusClient struct {
@@ -30,12 +32,20 @@ type databaseStatus struct {
 }
 
 func (statusClient *StatusClient) Get() (*Status, error) {
+	return statusClient.GetWithContext(context.Background())
+}
+
+func (statusClient *StatusClient) GetWithContext(ctx context.Context) (*Status, error) {
 
-	_, body, errs := newGet(statusClient.config, statusClient.config.HostAddress+"/status").End()
+	r, body, errs := newGet(ctx, statusClient.config, statusClient.config.HostAddress+"/status").End()
 	if errs != nil {
 		return nil, errors.New(fmt.Sprintf("Could not call get status, error: %v", errs))
 	}
 
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
+	}
+
 	status := &Status{}
 	err := json.Unmarshal([]byte(body), status)
 	if err != nil {
@@ -45,3 +55,31 @@ func (statusClient *StatusClient) Get() (*Status, error) {
 	return status, nil
 
 }
+
+func (statusClient *StatusClient) GetVersion() (string, error) {
+	return statusClient.GetVersionWithContext(context.Background())
+}
+
+// GetVersionWithContext returns the version of kong, e.g. "2.8.1", to set as Config.KongVersion.  The version is read
+// from the root of the admin api, so it is the same for every workspace
+func (statusClient *StatusClient) GetVersionWithContext(ctx context.Context) (string, error) {
+
+	r, body, errs := newGet(ctx, statusClient.config, strings.TrimRight(statusClient.config.adminAddress(), "/")+"/").End()
+	if errs != nil {
+		return "", errors.New(fmt.Sprintf("Could not call get node information, error: %v", errs))
+	}
+
+	if err := checkResponse(r, body); err != nil {
+		return "", err
+	}
+
+	information := &struct {
+		Version string `json:"version"`
+	}{}
+	err := json.Unmarshal([]byte(body), information)
+	if err != nil {
+		return "", errors.New(fmt.Sprintf("Could not parse node information response, error: %v", err))
+	}
+
+	return information.Version, nil
+}
@@ -14,3 +14,10 @@ func Test_GetStatus(t *testing.T) {
 	assert.True(t, result.Database.Reachable)
 	assert.True(t, result.Server.ConnectionsAccepted >= 1)
 }
+
+func Test_GetVersion(t *testing.T) {
+	result, err := NewClient(NewDefaultConfig()).Status().GetVersion()
+
+	assert.Nil(t, err)
+	assert.NotEqual(t, "", result)
+}
@@ -0,0 +1,91 @@
+package gokong
+
+import (
+	"context"
+	"net/url"
+	"strings"
+)
+
+type TagClient struct {
+	config *Config
+}
+
+// Tag is a single tag applied to an entity, EntityName is the kind of entity e.g. services, routes, consumers
+type Tag struct {
+	EntityName string `json:"entity_name" yaml:"entity_name"`
+	EntityId   string `json:"entity_id" yaml:"entity_id"`
+	Tag        string `json:"tag" yaml:"tag"`
+}
+
+type Tags struct {
+	Data   []*Tag  `json:"data" yaml:"data"`
+	Next   *string `json:"next" yaml:"next"`
+	Offset string  `json:"offset,omitempty" yaml:"offset,omitempty"`
+}
+
+type TagQueryString struct {
+	Offset string `json:"offset,omitempty"`
+	Size   int    `json:"size"`
+}
+
+const TagsPath = "/tags/"
+
+// TagsAll returns a tags filter for use in a list query string that matches entities having every one of the tags
+func TagsAll(tags ...string) string {
+	return strings.Join(tags, ",")
+}
+
+// TagsAny returns a tags filter for use in a list query string that matches entities having at least one of the tags
+func TagsAny(tags ...string) string {
+	return strings.Join(tags, "/")
+}
+
+func (tagClient *TagClient) List(query *TagQueryString) ([]*Tag, error) {
+	return tagClient.ListWithContext(context.Background(), query)
+}
+
+// ListWithContext returns every tag applied to every entity in kong
+func (tagClient *TagClient) ListWithContext(ctx context.Context, query *TagQueryString) ([]*Tag, error) {
+	return tagClient.list(ctx, tagClient.config.HostAddress+TagsPath, query)
+}
+
+// Iter returns an iterator over the tags matching the query
+func (tagClient *TagClient) Iter(ctx context.Context, query *TagQueryString) *TagIterator {
+	pages := newPager(ctx, tagClient.config, tagClient.config.HostAddress+TagsPath, "tags", *query)
+	return &TagIterator{iterator: newIterator(pages, func() listPage { return &Tags{} })}
+}
+
+type TagIterator struct {
+	iterator
+}
+
+// Value returns the tag the iterator is positioned on
+func (tagIterator *TagIterator) Value() *Tag {
+	tag, _ := tagIterator.value.(*Tag)
+	return tag
+}
+
+func (tagClient *TagClient) GetByTag(tag string, query *TagQueryString) ([]*Tag, error) {
+	return tagClient.GetByTagWithContext(context.Background(), tag, query)
+}
+
+// GetByTagWithContext returns every entity the tag has been applied to
+func (tagClient *TagClient) GetByTagWithContext(ctx context.Context, tag string, query *TagQueryString) ([]*Tag, error) {
+	return tagClient.list(ctx, tagClient.config.HostAddress+TagsPath+url.PathEscape(tag), query)
+}
+
+func (tagClient *TagClient) list(ctx context.Context, address string, query *TagQueryString) ([]*Tag, error) {
+	tags := make([]*Tag, 0)
+
+	pages := newPager(ctx, tagClient.config, address, "tags", *query)
+	for pages.more() {
+		data := &Tags{}
+		if err := pages.next(data); err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, data.Data...)
+	}
+
+	return tags, nil
+}
@@ -0,0 +1,97 @@
+package gokong
+
+import (
+	"fmt"
+	"testing"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_TagsAllAndAny(t *testing.T) {
+	assert.Equal(t, "a,b,c", TagsAll("a", "b", "c"))
+	assert.Equal(t, "a/b/c", TagsAny("a", "b", "c"))
+	assert.Equal(t, "a", TagsAny("a"))
+}
+
+func Test_ServicesFilteredByTags(t *testing.T) {
+	team := fmt.Sprintf("team-%s", uuid.NewV4().String())
+	production := fmt.Sprintf("production-%s", uuid.NewV4().String())
+	client := NewClient(NewDefaultConfig())
+
+	both, err := client.Services().Create(&ServiceRequest{
+		Name: String(fmt.Sprintf("service-name-%s", uuid.NewV4().String())),
+		Url:  String("http://foo.com"),
+		Tags: StringSlice([]string{team, production}),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, StringSlice([]string{team, production}), both.Tags)
+
+	teamOnly, err := client.Services().Create(&ServiceRequest{
+		Name: String(fmt.Sprintf("service-name-%s", uuid.NewV4().String())),
+		Url:  String("http://foo.com"),
+		Tags: StringSlice([]string{team}),
+	})
+	assert.Nil(t, err)
+
+	result, err := client.Services().GetServices(&ServiceQueryString{Tags: TagsAll(team, production)})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(result))
+	assert.Equal(t, both.Id, result[0].Id)
+
+	result, err = client.Services().GetServices(&ServiceQueryString{Tags: TagsAny(team, production)})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(result))
+
+	tags, err := client.Tags().GetByTag(production, &TagQueryString{})
+	assert.Nil(t, err)
+	assert.Equal(t, []*Tag{{EntityName: "services", EntityId: *both.Id, Tag: production}}, tags)
+
+	err = client.Services().DeleteServiceById(*both.Id)
+	assert.Nil(t, err)
+	err = client.Services().DeleteServiceById(*teamOnly.Id)
+	assert.Nil(t, err)
+}
+
+func Test_ConsumersFilteredByTags(t *testing.T) {
+	tag := fmt.Sprintf("tag-%s", uuid.NewV4().String())
+	client := NewClient(NewDefaultConfig())
+
+	tagged, err := client.Consumers().Create(&ConsumerRequest{
+		Username: fmt.Sprintf("username-%s", uuid.NewV4().String()),
+		Tags:     StringSlice([]string{tag}),
+	})
+	assert.Nil(t, err)
+
+	untagged, err := client.Consumers().Create(&ConsumerRequest{
+		Username: fmt.Sprintf("username-%s", uuid.NewV4().String()),
+	})
+	assert.Nil(t, err)
+
+	result, err := client.Consumers().GetConsumers(&ConsumerQueryString{Tags: tag})
+	assert.Nil(t, err)
+	assert.Equal(t, []*Consumer{tagged}, result)
+
+	err = client.Consumers().DeleteById(tagged.Id)
+	assert.Nil(t, err)
+	err = client.Consumers().DeleteById(untagged.Id)
+	assert.Nil(t, err)
+}
+
+func Test_TagsList(t *testing.T) {
+	tag := fmt.Sprintf("tag-%s", uuid.NewV4().String())
+	client := NewClient(NewDefaultConfig())
+
+	upstream, err := client.Upstreams().Create(&UpstreamRequest{
+		Name: fmt.Sprintf("upstream-%s", uuid.NewV4().String()),
+		Tags: StringSlice([]string{tag}),
+	})
+	assert.Nil(t, err)
+
+	tags, err := client.Tags().List(&TagQueryString{})
+	assert.Nil(t, err)
+	assert.Contains(t, tags, &Tag{EntityName: "upstreams", EntityId: upstream.Id, Tag: tag})
+
+	err = client.Upstreams().DeleteById(upstream.Id)
+	assert.Nil(t, err)
+}
@@ -1,6 +1,7 @@
 package gokong
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 )
@@ -10,17 +11,20 @@ type TargetClient struct {
 }
 
 type TargetRequest struct {
-	Target string `json:"target" yaml:"target"`
-	Weight int    `json:"weight" yaml:"weight"`
+	Target string    `json:"target" yaml:"target"`
+	Weight int       `json:"weight" yaml:"weight"`
+	Tags   []*string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Fields `json:"-" yaml:"-"`
 }
 
 type Target struct {
-	Id        *string  `json:"id,omitempty" yaml:"id,omitempty"`
-	CreatedAt *float32 `json:"created_at" yaml:"created_at"`
-	Target    *string  `json:"target" yaml:"target"`
-	Weight    *int     `json:"weight" yaml:"weight"`
-	Upstream  *Id      `json:"upstream" yaml:"upstream"`
-	Health    *string  `json:"health" yaml:"health"`
+	Id        *string   `json:"id,omitempty" yaml:"id,omitempty"`
+	CreatedAt *float32  `json:"created_at" yaml:"created_at"`
+	Target    *string   `json:"target" yaml:"target"`
+	Weight    *int      `json:"weight" yaml:"weight"`
+	Upstream  *Id       `json:"upstream" yaml:"upstream"`
+	Health    *string   `json:"health" yaml:"health"`
+	Tags      []*string `json:"tags" yaml:"tags"`
 }
 
 type Targets struct {
@@ -28,22 +32,37 @@ type Targets struct {
 	Total  int       `json:"total,omitempty" yaml:"total,omitempty"`
 	Next   string    `json:"next,omitempty" yaml:"next,omitempty"`
 	NodeId string    `json:"node_id,omitempty" yaml:"node_id,omitempty"`
+	Offset string    `json:"offset,omitempty" yaml:"offset,omitempty"`
+}
+
+type TargetQueryString struct {
+	Offset string `json:"offset,omitempty"`
+	Size   int    `json:"size"`
+	Tags   string `json:"tags,omitempty"`
 }
 
 const TargetsPath = "/upstreams/%s/targets"
 
 func (targetClient *TargetClient) CreateFromUpstreamName(name string, targetRequest *TargetRequest) (*Target, error) {
-	return targetClient.CreateFromUpstreamId(name, targetRequest)
+	return targetClient.CreateFromUpstreamNameWithContext(context.Background(), name, targetRequest)
+}
+
+func (targetClient *TargetClient) CreateFromUpstreamNameWithContext(ctx context.Context, name string, targetRequest *TargetRequest) (*Target, error) {
+	return targetClient.CreateFromUpstreamIdWithContext(ctx, name, targetRequest)
 }
 
 func (targetClient *TargetClient) CreateFromUpstreamId(id string, targetRequest *TargetRequest) (*Target, error) {
-	r, body, errs := newPost(targetClient.config, targetClient.config.HostAddress+fmt.Sprintf(TargetsPath, id)).Send(targetRequest).End()
+	return targetClient.CreateFromUpstreamIdWithContext(context.Background(), id, targetRequest)
+}
+
+func (targetClient *TargetClient) CreateFromUpstreamIdWithContext(ctx context.Context, id string, targetRequest *TargetRequest) (*Target, error) {
+	r, body, errs := newPost(ctx, targetClient.config, targetClient.config.HostAddress+fmt.Sprintf(TargetsPath, id)).Send(targetRequest).End()
 	if errs != nil {
 		return nil, fmt.Errorf("could not register the target, error: %v", errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return nil, fmt.Errorf("not authorised, message from kong: %s", body)
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
 	}
 
 	createdTarget := &Target{}
@@ -60,53 +79,86 @@ func (targetClient *TargetClient) CreateFromUpstreamId(id string, targetRequest
 }
 
 func (targetClient *TargetClient) GetTargetsFromUpstreamName(name string) ([]*Target, error) {
-	return targetClient.GetTargetsFromUpstreamId(name)
+	return targetClient.GetTargetsFromUpstreamNameWithContext(context.Background(), name)
+}
+
+func (targetClient *TargetClient) GetTargetsFromUpstreamNameWithContext(ctx context.Context, name string) ([]*Target, error) {
+	return targetClient.GetTargetsFromUpstreamIdWithContext(ctx, name)
 }
 
 func (targetClient *TargetClient) GetTargetsFromUpstreamId(id string) ([]*Target, error) {
-	targets := []*Target{}
-	data := &Targets{}
+	return targetClient.GetTargetsFromUpstreamIdWithContext(context.Background(), id)
+}
 
-	for {
-		r, body, errs := newGet(targetClient.config, targetClient.config.HostAddress+fmt.Sprintf(TargetsPath, id)).End()
-		if errs != nil {
-			return nil, fmt.Errorf("could not get targets, error: %v", errs)
-		}
+func (targetClient *TargetClient) GetTargetsFromUpstreamIdWithContext(ctx context.Context, id string) ([]*Target, error) {
+	return targetClient.ListFromUpstreamIdWithContext(ctx, id, &TargetQueryString{})
+}
 
-		if r.StatusCode == 401 || r.StatusCode == 403 {
-			return nil, fmt.Errorf("not authorised, message from kong: %s", body)
-		}
+func (targetClient *TargetClient) ListFromUpstreamName(name string, query *TargetQueryString) ([]*Target, error) {
+	return targetClient.ListFromUpstreamIdWithContext(context.Background(), name, query)
+}
 
-		if r.StatusCode == 404 {
-			return nil, fmt.Errorf("non existent upstream: %s", id)
-		}
+func (targetClient *TargetClient) ListFromUpstreamNameWithContext(ctx context.Context, name string, query *TargetQueryString) ([]*Target, error) {
+	return targetClient.ListFromUpstreamIdWithContext(ctx, name, query)
+}
 
-		err := json.Unmarshal([]byte(body), data)
-		if err != nil {
-			return nil, fmt.Errorf("could not parse target get response, error: %v", err)
-		}
+func (targetClient *TargetClient) ListFromUpstreamId(id string, query *TargetQueryString) ([]*Target, error) {
+	return targetClient.ListFromUpstreamIdWithContext(context.Background(), id, query)
+}
 
-		targets = append(targets, data.Data...)
+// ListFromUpstreamIdWithContext returns every target of the upstream matching the query, following pagination
+func (targetClient *TargetClient) ListFromUpstreamIdWithContext(ctx context.Context, id string, query *TargetQueryString) ([]*Target, error) {
+	targets := make([]*Target, 0)
 
-		if data.Next == "" {
-			break
+	pages := newPager(ctx, targetClient.config, targetClient.config.HostAddress+fmt.Sprintf(TargetsPath, id), "targets", *query)
+	for pages.more() {
+		data := &Targets{}
+		if err := pages.next(data); err != nil {
+			return nil, err
 		}
+
+		targets = append(targets, data.Data...)
 	}
+
 	return targets, nil
 }
 
+// IterFromUpstreamId returns an iterator over the targets of the upstream matching the query
+func (targetClient *TargetClient) IterFromUpstreamId(ctx context.Context, id string, query *TargetQueryString) *TargetIterator {
+	pages := newPager(ctx, targetClient.config, targetClient.config.HostAddress+fmt.Sprintf(TargetsPath, id), "targets", *query)
+	return &TargetIterator{iterator: newIterator(pages, func() listPage { return &Targets{} })}
+}
+
+type TargetIterator struct {
+	iterator
+}
+
+// Value returns the target the iterator is positioned on
+func (targetIterator *TargetIterator) Value() *Target {
+	target, _ := targetIterator.value.(*Target)
+	return target
+}
+
 func (targetClient *TargetClient) DeleteFromUpstreamByHostPort(upstreamNameOrId string, hostPort string) error {
-	return targetClient.DeleteFromUpstreamById(upstreamNameOrId, hostPort)
+	return targetClient.DeleteFromUpstreamByHostPortWithContext(context.Background(), upstreamNameOrId, hostPort)
+}
+
+func (targetClient *TargetClient) DeleteFromUpstreamByHostPortWithContext(ctx context.Context, upstreamNameOrId string, hostPort string) error {
+	return targetClient.DeleteFromUpstreamByIdWithContext(ctx, upstreamNameOrId, hostPort)
 }
 
 func (targetClient *TargetClient) DeleteFromUpstreamById(upstreamNameOrId string, id string) error {
-	r, body, errs := newDelete(targetClient.config, targetClient.config.HostAddress+fmt.Sprintf(TargetsPath, upstreamNameOrId)+fmt.Sprintf("/%s", id)).End()
+	return targetClient.DeleteFromUpstreamByIdWithContext(context.Background(), upstreamNameOrId, id)
+}
+
+func (targetClient *TargetClient) DeleteFromUpstreamByIdWithContext(ctx context.Context, upstreamNameOrId string, id string) error {
+	r, body, errs := newDelete(ctx, targetClient.config, targetClient.config.HostAddress+fmt.Sprintf(TargetsPath, upstreamNameOrId)+fmt.Sprintf("/%s", id)).End()
 	if errs != nil {
 		return fmt.Errorf("could not delete the target, result: %v error: %v", r, errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return fmt.Errorf("not authorised, message from kong: %s", body)
+	if err := checkResponse(r, body); err != nil {
+		return err
 	}
 
 	if r.StatusCode != 204 {
@@ -117,17 +169,25 @@ func (targetClient *TargetClient) DeleteFromUpstreamById(upstreamNameOrId string
 }
 
 func (targetClient *TargetClient) SetTargetFromUpstreamByHostPortAsHealthy(upstreamNameOrId string, hostPort string) error {
-	return targetClient.SetTargetFromUpstreamByIdAsHealthy(upstreamNameOrId, hostPort)
+	return targetClient.SetTargetFromUpstreamByHostPortAsHealthyWithContext(context.Background(), upstreamNameOrId, hostPort)
+}
+
+func (targetClient *TargetClient) SetTargetFromUpstreamByHostPortAsHealthyWithContext(ctx context.Context, upstreamNameOrId string, hostPort string) error {
+	return targetClient.SetTargetFromUpstreamByIdAsHealthyWithContext(ctx, upstreamNameOrId, hostPort)
 }
 
 func (targetClient *TargetClient) SetTargetFromUpstreamByIdAsHealthy(upstreamNameOrId string, id string) error {
-	r, body, errs := newPost(targetClient.config, targetClient.config.HostAddress+fmt.Sprintf(TargetsPath, upstreamNameOrId)+fmt.Sprintf("/%s/healthy", id)).Send("").End()
+	return targetClient.SetTargetFromUpstreamByIdAsHealthyWithContext(context.Background(), upstreamNameOrId, id)
+}
+
+func (targetClient *TargetClient) SetTargetFromUpstreamByIdAsHealthyWithContext(ctx context.Context, upstreamNameOrId string, id string) error {
+	r, body, errs := newPost(ctx, targetClient.config, targetClient.config.HostAddress+fmt.Sprintf(TargetsPath, upstreamNameOrId)+fmt.Sprintf("/%s/healthy", id)).Send("").End()
 	if errs != nil {
 		return fmt.Errorf("could not set the target as healthy, result: %v error: %v", r, errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return fmt.Errorf("not authorised, message from kong: %s", body)
+	if err := checkResponse(r, body); err != nil {
+		return err
 	}
 
 	if r.StatusCode != 204 {
@@ -138,17 +198,25 @@ func (targetClient *TargetClient) SetTargetFromUpstreamByIdAsHealthy(upstreamNam
 }
 
 func (targetClient *TargetClient) SetTargetFromUpstreamByHostPortAsUnhealthy(upstreamNameOrId string, hostPort string) error {
-	return targetClient.SetTargetFromUpstreamByIdAsUnhealthy(upstreamNameOrId, hostPort)
+	return targetClient.SetTargetFromUpstreamByHostPortAsUnhealthyWithContext(context.Background(), upstreamNameOrId, hostPort)
+}
+
+func (targetClient *TargetClient) SetTargetFromUpstreamByHostPortAsUnhealthyWithContext(ctx context.Context, upstreamNameOrId string, hostPort string) error {
+	return targetClient.SetTargetFromUpstreamByIdAsUnhealthyWithContext(ctx, upstreamNameOrId, hostPort)
 }
 
 func (targetClient *TargetClient) SetTargetFromUpstreamByIdAsUnhealthy(upstreamNameOrId string, id string) error {
-	r, body, errs := newPost(targetClient.config, targetClient.config.HostAddress+fmt.Sprintf(TargetsPath, upstreamNameOrId)+fmt.Sprintf("/%s/unhealthy", id)).Send("").End()
+	return targetClient.SetTargetFromUpstreamByIdAsUnhealthyWithContext(context.Background(), upstreamNameOrId, id)
+}
+
+func (targetClient *TargetClient) SetTargetFromUpstreamByIdAsUnhealthyWithContext(ctx context.Context, upstreamNameOrId string, id string) error {
+	r, body, errs := newPost(ctx, targetClient.config, targetClient.config.HostAddress+fmt.Sprintf(TargetsPath, upstreamNameOrId)+fmt.Sprintf("/%s/unhealthy", id)).Send("").End()
 	if errs != nil {
 		return fmt.Errorf("could not set the target as unhealthy, result: %v error: %v", r, errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return fmt.Errorf("not authorised, message from kong: %s", body)
+	if err := checkResponse(r, body); err != nil {
+		return err
 	}
 
 	if r.StatusCode != 204 {
@@ -159,38 +227,30 @@ func (targetClient *TargetClient) SetTargetFromUpstreamByIdAsUnhealthy(upstreamN
 }
 
 func (targetClient *TargetClient) GetTargetsWithHealthFromUpstreamName(name string) ([]*Target, error) {
-	return targetClient.GetTargetsWithHealthFromUpstreamId(name)
+	return targetClient.GetTargetsWithHealthFromUpstreamNameWithContext(context.Background(), name)
 }
 
-func (targetClient *TargetClient) GetTargetsWithHealthFromUpstreamId(id string) ([]*Target, error) {
-	targets := []*Target{}
-	data := &Targets{}
-
-	for {
-		r, body, errs := newGet(targetClient.config, targetClient.config.HostAddress+fmt.Sprintf("/upstreams/%s/health", id)).End()
-		if errs != nil {
-			return nil, fmt.Errorf("could not get targets, error: %v", errs)
-		}
+func (targetClient *TargetClient) GetTargetsWithHealthFromUpstreamNameWithContext(ctx context.Context, name string) ([]*Target, error) {
+	return targetClient.GetTargetsWithHealthFromUpstreamIdWithContext(ctx, name)
+}
 
-		if r.StatusCode == 401 || r.StatusCode == 403 {
-			return nil, fmt.Errorf("not authorised, message from kong: %s", body)
-		}
+func (targetClient *TargetClient) GetTargetsWithHealthFromUpstreamId(id string) ([]*Target, error) {
+	return targetClient.GetTargetsWithHealthFromUpstreamIdWithContext(context.Background(), id)
+}
 
-		if r.StatusCode == 404 {
-			return nil, fmt.Errorf("non existent upstream: %s", id)
-		}
+func (targetClient *TargetClient) GetTargetsWithHealthFromUpstreamIdWithContext(ctx context.Context, id string) ([]*Target, error) {
+	targets := make([]*Target, 0)
 
-		err := json.Unmarshal([]byte(body), data)
-		if err != nil {
-			return nil, fmt.Errorf("could not parse target get response, error: %v", err)
+	pages := newPager(ctx, targetClient.config, targetClient.config.HostAddress+fmt.Sprintf("/upstreams/%s/health", id), "targets", TargetQueryString{})
+	for pages.more() {
+		data := &Targets{}
+		if err := pages.next(data); err != nil {
+			return nil, err
 		}
 
 		targets = append(targets, data.Data...)
-
-		if data.Next == "" {
-			break
-		}
 	}
+
 	return targets, nil
 }
 
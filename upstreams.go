@@ -1,8 +1,10 @@
 package gokong
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 )
 
 type UpstreamClient struct {
@@ -10,7 +12,7 @@ type UpstreamClient struct {
 }
 
 type UpstreamRequest struct {
-	Name               string               `json:"name" yaml:"name"`
+	Name               string               `json:"name,omitempty" yaml:"name,omitempty"`
 	Slots              int                  `json:"slots,omitempty" yaml:"slots,omitempty"`
 	HashOn             string               `json:"hash_on,omitempty" yaml:"hash_on,omitempty"`
 	HashFallback       string               `json:"hash_fallback,omitempty" yaml:"hash_fallback,omitempty"`
@@ -19,6 +21,8 @@ type UpstreamRequest struct {
 	HashOnCookie       string               `json:"hash_on_cookie,omitempty" yaml:"hash_on_cookie,omitempty"`
 	HashOnCookiePath   string               `json:"hash_on_cookie_path,omitempty" yaml:"hash_on_cookie_path,omitempty"`
 	HealthChecks       *UpstreamHealthCheck `json:"healthchecks,omitempty" yaml:"healthchecks,omitempty"`
+	Tags               []*string            `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Fields             `json:"-" yaml:"-"`
 }
 
 type UpstreamHealthCheck struct {
@@ -69,6 +73,8 @@ type PassiveUnhealthy struct {
 	Timeouts     int   `json:"timeouts" yaml:"timeouts"`
 }
 
+// Upstream is an upstream as kong returns it, its embedded UpstreamRequest can be sent straight back to kong to
+// update it
 type Upstream struct {
 	Id string `json:"id,omitempty" yaml:"id,omitempty"`
 	UpstreamRequest
@@ -77,23 +83,186 @@ type Upstream struct {
 type Upstreams struct {
 	Results []*Upstream `json:"data,omitempty" yaml:"data,omitempty"`
 	Next    string      `json:"next,omitempty" yaml:"next,omitempty"`
+	Offset  string      `json:"offset,omitempty" yaml:"offset,omitempty"`
+}
+
+type UpstreamQueryString struct {
+	Offset string `json:"offset,omitempty"`
+	Size   int    `json:"size"`
+	Tags   string `json:"tags,omitempty"`
+}
+
+var hashOnValues = []string{"none", "consumer", "ip", "header", "cookie"}
+var healthCheckTypes = []string{"tcp", "http", "https", "grpc", "grpcs"}
+
+// Validate checks the upstream against kong's schema rules without calling kong, it returns a *ValidationError
+// listing every invalid field
+func (upstreamRequest *UpstreamRequest) Validate() error {
+	v := newValidator("upstream")
+
+	v.required("name", upstreamRequest.Name)
+	if upstreamRequest.Name != "" {
+		v.hostname("name", upstreamRequest.Name)
+	}
+
+	if upstreamRequest.Slots != 0 {
+		v.between("slots", upstreamRequest.Slots, 10, 65536)
+	}
+
+	hashOn, hashFallback := upstreamRequest.HashOn, upstreamRequest.HashFallback
+	if hashOn == "" {
+		hashOn = "none"
+	}
+	if hashFallback == "" {
+		hashFallback = "none"
+	}
+	v.oneOf("hash_on", hashOn, hashOnValues...)
+	v.oneOf("hash_fallback", hashFallback, hashOnValues...)
+
+	switch {
+	case hashOn == "none" && hashFallback != "none":
+		v.add("hash_fallback", "must be 'none' when 'hash_on' is 'none'")
+	case hashOn == "cookie" && hashFallback != "none":
+		v.add("hash_fallback", "must be 'none' when 'hash_on' is 'cookie'")
+	case hashOn == hashFallback && (hashOn == "consumer" || hashOn == "ip"):
+		v.add("hash_fallback", "must differ from 'hash_on'")
+	}
+
+	if hashOn == "header" && upstreamRequest.HashOnHeader == "" {
+		v.add("hash_on_header", "must be set when 'hash_on' is 'header'")
+	}
+	if hashFallback == "header" && upstreamRequest.HashFallbackHeader == "" {
+		v.add("hash_fallback_header", "must be set when 'hash_fallback' is 'header'")
+	}
+	if hashOn == "header" && hashFallback == "header" && strings.EqualFold(upstreamRequest.HashOnHeader, upstreamRequest.HashFallbackHeader) {
+		v.add("hash_fallback_header", "must differ from 'hash_on_header'")
+	}
+	if upstreamRequest.HashOnHeader != "" && !headerNamePattern.MatchString(upstreamRequest.HashOnHeader) {
+		v.add("hash_on_header", "bad header name '%s', allowed characters are A-Z, a-z, 0-9, '_', and '-'", upstreamRequest.HashOnHeader)
+	}
+	if upstreamRequest.HashFallbackHeader != "" && !headerNamePattern.MatchString(upstreamRequest.HashFallbackHeader) {
+		v.add("hash_fallback_header", "bad header name '%s', allowed characters are A-Z, a-z, 0-9, '_', and '-'", upstreamRequest.HashFallbackHeader)
+	}
+
+	if (hashOn == "cookie" || hashFallback == "cookie") && upstreamRequest.HashOnCookie == "" {
+		v.add("hash_on_cookie", "must be set when 'hash_on' or 'hash_fallback' is 'cookie'")
+	}
+	if upstreamRequest.HashOnCookiePath != "" {
+		v.path("hash_on_cookie_path", upstreamRequest.HashOnCookiePath)
+	}
+
+	if upstreamRequest.HealthChecks != nil {
+		upstreamRequest.HealthChecks.validate(v, "healthchecks")
+	}
+
+	return v.err()
+}
+
+// Validate checks the health checks against kong's schema rules without calling kong
+func (healthCheck *UpstreamHealthCheck) Validate() error {
+	v := newValidator("healthchecks")
+	healthCheck.validate(v, "healthchecks")
+	return v.err()
+}
+
+func (healthCheck *UpstreamHealthCheck) validate(v *validator, field string) {
+	if healthCheck.Active != nil {
+		healthCheck.Active.validate(v, field+".active")
+	}
+	if healthCheck.Passive != nil {
+		healthCheck.Passive.validate(v, field+".passive")
+	}
+}
+
+// Validate checks the active health check against kong's schema rules without calling kong
+func (active *UpstreamHealthCheckActive) Validate() error {
+	v := newValidator("active health check")
+	active.validate(v, "healthchecks.active")
+	return v.err()
+}
+
+func (active *UpstreamHealthCheckActive) validate(v *validator, field string) {
+	if active.Type != "" {
+		v.oneOf(field+".type", active.Type, healthCheckTypes...)
+	}
+	if active.Concurrency != 0 {
+		v.between(field+".concurrency", active.Concurrency, 1, 2147483647)
+	}
+	v.between(field+".timeout", active.Timeout, 0, 65535)
+	if active.HttpPath != "" {
+		v.path(field+".http_path", active.HttpPath)
+	}
+	if active.HttpsSni != nil {
+		v.hostname(field+".https_sni", *active.HttpsSni)
+	}
+
+	if active.Healthy != nil {
+		v.httpStatuses(field+".healthy.http_statuses", active.Healthy.HttpStatuses)
+		v.between(field+".healthy.interval", active.Healthy.Interval, 0, 65535)
+		v.between(field+".healthy.successes", active.Healthy.Successes, 0, 255)
+	}
+
+	if active.Unhealthy != nil {
+		v.httpStatuses(field+".unhealthy.http_statuses", active.Unhealthy.HttpStatuses)
+		v.between(field+".unhealthy.interval", active.Unhealthy.Interval, 0, 65535)
+		v.between(field+".unhealthy.http_failures", active.Unhealthy.HttpFailures, 0, 255)
+		v.between(field+".unhealthy.tcp_failures", active.Unhealthy.TcpFailures, 0, 255)
+		v.between(field+".unhealthy.timeouts", active.Unhealthy.Timeouts, 0, 255)
+	}
+}
+
+// Validate checks the passive health check against kong's schema rules without calling kong
+func (passive *UpstreamHealthCheckPassive) Validate() error {
+	v := newValidator("passive health check")
+	passive.validate(v, "healthchecks.passive")
+	return v.err()
+}
+
+func (passive *UpstreamHealthCheckPassive) validate(v *validator, field string) {
+	if passive.Type != "" {
+		v.oneOf(field+".type", passive.Type, healthCheckTypes...)
+	}
+
+	if passive.Healthy != nil {
+		v.httpStatuses(field+".healthy.http_statuses", passive.Healthy.HttpStatuses)
+		v.between(field+".healthy.successes", passive.Healthy.Successes, 0, 255)
+	}
+
+	if passive.Unhealthy != nil {
+		v.httpStatuses(field+".unhealthy.http_statuses", passive.Unhealthy.HttpStatuses)
+		v.between(field+".unhealthy.http_failures", passive.Unhealthy.HttpFailures, 0, 255)
+		v.between(field+".unhealthy.tcp_failures", passive.Unhealthy.TcpFailures, 0, 255)
+		v.between(field+".unhealthy.timeouts", passive.Unhealthy.Timeouts, 0, 255)
+	}
 }
 
 const UpstreamsPath = "/upstreams/"
 
 func (upstreamClient *UpstreamClient) GetByName(name string) (*Upstream, error) {
-	return upstreamClient.GetById(name)
+	return upstreamClient.GetByNameWithContext(context.Background(), name)
+}
+
+func (upstreamClient *UpstreamClient) GetByNameWithContext(ctx context.Context, name string) (*Upstream, error) {
+	return upstreamClient.GetByIdWithContext(ctx, name)
 }
 
 func (upstreamClient *UpstreamClient) GetById(id string) (*Upstream, error) {
+	return upstreamClient.GetByIdWithContext(context.Background(), id)
+}
+
+func (upstreamClient *UpstreamClient) GetByIdWithContext(ctx context.Context, id string) (*Upstream, error) {
 
-	r, body, errs := newGet(upstreamClient.config, upstreamClient.config.HostAddress+UpstreamsPath+id).End()
+	r, body, errs := newGet(ctx, upstreamClient.config, upstreamClient.config.HostAddress+UpstreamsPath+id).End()
 	if errs != nil {
 		return nil, fmt.Errorf("could not get upstream, error: %v", errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return nil, fmt.Errorf("not authorised, message from kong: %s", body)
+	if r.StatusCode == 404 {
+		return nil, nil
+	}
+
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
 	}
 
 	upstream := &Upstream{}
@@ -110,14 +279,18 @@ func (upstreamClient *UpstreamClient) GetById(id string) (*Upstream, error) {
 }
 
 func (upstreamClient *UpstreamClient) Create(upstreamRequest *UpstreamRequest) (*Upstream, error) {
+	return upstreamClient.CreateWithContext(context.Background(), upstreamRequest)
+}
 
-	r, body, errs := newPost(upstreamClient.config, upstreamClient.config.HostAddress+UpstreamsPath).Send(upstreamRequest).End()
+func (upstreamClient *UpstreamClient) CreateWithContext(ctx context.Context, upstreamRequest *UpstreamRequest) (*Upstream, error) {
+
+	r, body, errs := newPost(ctx, upstreamClient.config, upstreamClient.config.HostAddress+UpstreamsPath).Send(upstreamRequest).End()
 	if errs != nil {
 		return nil, fmt.Errorf("could not create new upstream, error: %v", errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return nil, fmt.Errorf("not authorised, message from kong: %s", body)
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
 	}
 
 	createdUpstream := &Upstream{}
@@ -134,56 +307,103 @@ func (upstreamClient *UpstreamClient) Create(upstreamRequest *UpstreamRequest) (
 }
 
 func (upstreamClient *UpstreamClient) DeleteByName(name string) error {
-	return upstreamClient.DeleteById(name)
+	return upstreamClient.DeleteByNameWithContext(context.Background(), name)
+}
+
+func (upstreamClient *UpstreamClient) DeleteByNameWithContext(ctx context.Context, name string) error {
+	return upstreamClient.DeleteByIdWithContext(ctx, name)
 }
 
 func (upstreamClient *UpstreamClient) DeleteById(id string) error {
+	return upstreamClient.DeleteByIdWithContext(context.Background(), id)
+}
+
+func (upstreamClient *UpstreamClient) DeleteByIdWithContext(ctx context.Context, id string) error {
 
-	r, body, errs := newDelete(upstreamClient.config, upstreamClient.config.HostAddress+UpstreamsPath+id).End()
+	r, body, errs := newDelete(ctx, upstreamClient.config, upstreamClient.config.HostAddress+UpstreamsPath+id).End()
 	if errs != nil {
 		return fmt.Errorf("could not delete upstream, result: %v error: %v", r, errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return fmt.Errorf("not authorised, message from kong: %s", body)
+	if err := checkResponse(r, body); err != nil {
+		return err
 	}
 
 	return nil
 }
 
 func (upstreamClient *UpstreamClient) List() (*Upstreams, error) {
+	return upstreamClient.ListWithContext(context.Background())
+}
 
-	r, body, errs := newGet(upstreamClient.config, upstreamClient.config.HostAddress+UpstreamsPath).End()
-	if errs != nil {
-		return nil, fmt.Errorf("could not get upstreams, error: %v", errs)
+// ListWithContext returns every upstream, following pagination
+func (upstreamClient *UpstreamClient) ListWithContext(ctx context.Context) (*Upstreams, error) {
+	results, err := upstreamClient.GetUpstreamsWithContext(ctx, &UpstreamQueryString{})
+	if err != nil {
+		return nil, err
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return nil, fmt.Errorf("not authorised, message from kong: %s", body)
-	}
+	return &Upstreams{Results: results}, nil
+}
 
-	upstreams := &Upstreams{}
-	err := json.Unmarshal([]byte(body), upstreams)
-	if err != nil {
-		return nil, fmt.Errorf("could not parse upstreams list response, error: %v", err)
+func (upstreamClient *UpstreamClient) GetUpstreams(query *UpstreamQueryString) ([]*Upstream, error) {
+	return upstreamClient.GetUpstreamsWithContext(context.Background(), query)
+}
+
+// GetUpstreamsWithContext returns every upstream matching the query, following pagination
+func (upstreamClient *UpstreamClient) GetUpstreamsWithContext(ctx context.Context, query *UpstreamQueryString) ([]*Upstream, error) {
+	upstreams := make([]*Upstream, 0)
+
+	pages := newPager(ctx, upstreamClient.config, upstreamClient.config.HostAddress+UpstreamsPath, "upstreams", *query)
+	for pages.more() {
+		data := &Upstreams{}
+		if err := pages.next(data); err != nil {
+			return nil, err
+		}
+
+		upstreams = append(upstreams, data.Results...)
 	}
 
 	return upstreams, nil
 }
 
+// Iter returns an iterator over the upstreams matching the query
+func (upstreamClient *UpstreamClient) Iter(ctx context.Context, query *UpstreamQueryString) *UpstreamIterator {
+	pages := newPager(ctx, upstreamClient.config, upstreamClient.config.HostAddress+UpstreamsPath, "upstreams", *query)
+	return &UpstreamIterator{iterator: newIterator(pages, func() listPage { return &Upstreams{} })}
+}
+
+type UpstreamIterator struct {
+	iterator
+}
+
+// Value returns the upstream the iterator is positioned on
+func (upstreamIterator *UpstreamIterator) Value() *Upstream {
+	upstream, _ := upstreamIterator.value.(*Upstream)
+	return upstream
+}
+
 func (upstreamClient *UpstreamClient) UpdateByName(name string, upstreamRequest *UpstreamRequest) (*Upstream, error) {
-	return upstreamClient.UpdateById(name, upstreamRequest)
+	return upstreamClient.UpdateByNameWithContext(context.Background(), name, upstreamRequest)
+}
+
+func (upstreamClient *UpstreamClient) UpdateByNameWithContext(ctx context.Context, name string, upstreamRequest *UpstreamRequest) (*Upstream, error) {
+	return upstreamClient.UpdateByIdWithContext(ctx, name, upstreamRequest)
 }
 
 func (upstreamClient *UpstreamClient) UpdateById(id string, upstreamRequest *UpstreamRequest) (*Upstream, error) {
+	return upstreamClient.UpdateByIdWithContext(context.Background(), id, upstreamRequest)
+}
+
+func (upstreamClient *UpstreamClient) UpdateByIdWithContext(ctx context.Context, id string, upstreamRequest *UpstreamRequest) (*Upstream, error) {
 
-	r, body, errs := newPatch(upstreamClient.config, upstreamClient.config.HostAddress+UpstreamsPath+id).Send(upstreamRequest).End()
+	r, body, errs := newPatch(ctx, upstreamClient.config, upstreamClient.config.HostAddress+UpstreamsPath+id).Send(upstreamRequest).End()
 	if errs != nil {
 		return nil, fmt.Errorf("could not update upstream, error: %v", errs)
 	}
 
-	if r.StatusCode == 401 || r.StatusCode == 403 {
-		return nil, fmt.Errorf("not authorised, message from kong: %s", body)
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
 	}
 
 	updatedUpstream := &Upstream{}
@@ -198,3 +418,33 @@ func (upstreamClient *UpstreamClient) UpdateById(id string, upstreamRequest *Ups
 
 	return updatedUpstream, nil
 }
+
+func (upstreamClient *UpstreamClient) Upsert(nameOrId string, upstreamRequest *UpstreamRequest) (*Upstream, error) {
+	return upstreamClient.UpsertWithContext(context.Background(), nameOrId, upstreamRequest)
+}
+
+// UpsertWithContext creates the upstream with the name or id or, when it exists, replaces it in full with the
+// request so fields missing from the request go back to their defaults
+func (upstreamClient *UpstreamClient) UpsertWithContext(ctx context.Context, nameOrId string, upstreamRequest *UpstreamRequest) (*Upstream, error) {
+
+	r, body, errs := newPut(ctx, upstreamClient.config, upstreamClient.config.HostAddress+UpstreamsPath+nameOrId).Send(upstreamRequest).End()
+	if errs != nil {
+		return nil, fmt.Errorf("could not upsert upstream, error: %v", errs)
+	}
+
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
+	}
+
+	upsertedUpstream := &Upstream{}
+	err := json.Unmarshal([]byte(body), upsertedUpstream)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse upstream upsert response, error: %v", err)
+	}
+
+	if upsertedUpstream.Id == "" {
+		return nil, fmt.Errorf("could not upsert upstream, error: %v", body)
+	}
+
+	return upsertedUpstream, nil
+}
@@ -429,3 +429,150 @@ func Test_AllUpstreamEndpointsShouldReturnErrorWhenRequestUnauthorised(t *testin
 	assert.NotNil(t, err)
 
 }
+
+func Test_UpstreamsValidateAcceptsValidRequest(t *testing.T) {
+	upstreamRequest := &UpstreamRequest{
+		Name:         "service.v1.xyz",
+		Slots:        10,
+		HashOn:       "header",
+		HashOnHeader: "X-Consumer",
+		HashFallback: "ip",
+		HealthChecks: &UpstreamHealthCheck{
+			Active: &UpstreamHealthCheckActive{
+				Type:        "https",
+				Concurrency: 10,
+				HttpPath:    "/status",
+				HttpsSni:    String("example.com"),
+				Timeout:     1,
+				Healthy:     &ActiveHealthy{HttpStatuses: []int{200, 302}, Interval: 5, Successes: 2},
+				Unhealthy:   &ActiveUnhealthy{HttpStatuses: []int{500}, Interval: 5, HttpFailures: 3, TcpFailures: 3, Timeouts: 3},
+			},
+			Passive: &UpstreamHealthCheckPassive{
+				Type:      "http",
+				Healthy:   &PassiveHealthy{HttpStatuses: []int{200}, Successes: 1},
+				Unhealthy: &PassiveUnhealthy{HttpStatuses: []int{503}, HttpFailures: 1, TcpFailures: 1, Timeouts: 1},
+			},
+		},
+	}
+
+	assert.Nil(t, upstreamRequest.Validate())
+}
+
+func Test_UpstreamsValidateReportsEveryInvalidField(t *testing.T) {
+	upstreamRequest := &UpstreamRequest{
+		Name:   "not a hostname",
+		Slots:  5,
+		HashOn: "header",
+		HealthChecks: &UpstreamHealthCheck{
+			Active: &UpstreamHealthCheckActive{
+				Type:      "udp",
+				Timeout:   -1,
+				HttpPath:  "status",
+				Unhealthy: &ActiveUnhealthy{HttpStatuses: []int{42}, Interval: -5},
+			},
+			Passive: &UpstreamHealthCheckPassive{
+				Healthy: &PassiveHealthy{Successes: 256},
+			},
+		},
+	}
+
+	err := upstreamRequest.Validate()
+
+	validationError, ok := err.(*ValidationError)
+	assert.True(t, ok)
+	assert.Equal(t, "upstream", validationError.Entity)
+
+	fields := []string{}
+	for _, fieldError := range validationError.Fields {
+		fields = append(fields, fieldError.Field)
+	}
+	assert.Equal(t, []string{
+		"name",
+		"slots",
+		"hash_on_header",
+		"healthchecks.active.type",
+		"healthchecks.active.timeout",
+		"healthchecks.active.http_path",
+		"healthchecks.active.unhealthy.http_statuses",
+		"healthchecks.active.unhealthy.interval",
+		"healthchecks.passive.healthy.successes",
+	}, fields)
+	assert.Equal(t, "value should be between 10 and 65536", validationError.Field("slots").Message)
+}
+
+func Test_UpstreamsValidateHealthCheckStatuses(t *testing.T) {
+	assert.Nil(t, (&UpstreamHealthCheckPassive{Healthy: &PassiveHealthy{HttpStatuses: []int{101, 200, 999}}}).Validate())
+
+	for _, status := range []int{99, 1000} {
+		err := (&UpstreamHealthCheckPassive{Unhealthy: &PassiveUnhealthy{HttpStatuses: []int{500, status}}}).Validate()
+		assert.Equal(t, "invalid passive health check, healthchecks.passive.unhealthy.http_statuses: value should be between 100 and 999", err.Error())
+	}
+}
+
+func Test_UpstreamsForceSendZeroHealthCheckValues(t *testing.T) {
+	upstreamRequest := &UpstreamRequest{HealthChecks: &UpstreamHealthCheck{Active: &UpstreamHealthCheckActive{Type: "tcp"}}}
+	upstreamRequest.ForceSend("healthchecks.active.timeout")
+
+	body, err := requestBody(upstreamRequest, "")
+
+	assert.Nil(t, err)
+	active := body["healthchecks"].(map[string]interface{})["active"].(map[string]interface{})
+	assert.Equal(t, 0, active["timeout"])
+	assert.NotContains(t, active, "concurrency")
+}
+
+func Test_UpstreamsUpdateFromTheRequestOfAFetchedUpstream(t *testing.T) {
+	client := NewClient(NewDefaultConfig())
+	created, err := client.Upstreams().Create(&UpstreamRequest{Name: "upstream-" + uuid.NewV4().String(), Slots: 20})
+	assert.Nil(t, err)
+
+	fetched, err := client.Upstreams().GetById(created.Id)
+	assert.Nil(t, err)
+
+	body, err := requestBody(&fetched.UpstreamRequest, "")
+	assert.Nil(t, err)
+	assert.NotContains(t, body, "id")
+
+	fetched.Slots = 30
+	updated, err := client.Upstreams().UpdateById(created.Id, &fetched.UpstreamRequest)
+	assert.Nil(t, err)
+	assert.Equal(t, created.Id, updated.Id)
+	assert.Equal(t, 30, updated.Slots)
+
+	assert.Nil(t, client.Upstreams().DeleteById(created.Id))
+}
+
+func Test_UpstreamsValidateHashFallback(t *testing.T) {
+	err := (&UpstreamRequest{Name: "upstream", HashFallback: "ip"}).Validate()
+	assert.Equal(t, "must be 'none' when 'hash_on' is 'none'", err.(*ValidationError).Field("hash_fallback").Message)
+
+	err = (&UpstreamRequest{Name: "upstream", HashOn: "cookie", HashFallback: "ip", HashOnCookie: "session"}).Validate()
+	assert.Equal(t, "must be 'none' when 'hash_on' is 'cookie'", err.(*ValidationError).Field("hash_fallback").Message)
+
+	err = (&UpstreamRequest{Name: "upstream", HashOn: "header", HashOnHeader: "X-A", HashFallback: "header", HashFallbackHeader: "x-a"}).Validate()
+	assert.Equal(t, "must differ from 'hash_on_header'", err.(*ValidationError).Field("hash_fallback_header").Message)
+}
+
+func Test_UpstreamsValidateHealthChecksOnTheirOwn(t *testing.T) {
+	err := (&UpstreamHealthCheckActive{Concurrency: -1}).Validate()
+	assert.Equal(t, "invalid active health check, healthchecks.active.concurrency: value should be between 1 and 2147483647", err.Error())
+
+	assert.Nil(t, (&UpstreamHealthCheckPassive{Type: "tcp"}).Validate())
+}
+
+func Test_UpstreamsUpsertCreatesAndReplaces(t *testing.T) {
+	client := NewClient(NewDefaultConfig())
+	name := "upstream-" + uuid.NewV4().String()
+
+	created, err := client.Upstreams().Upsert(name, &UpstreamRequest{Name: name, Slots: 20})
+	assert.Nil(t, err)
+	assert.Equal(t, 20, created.Slots)
+
+	replaced, err := client.Upstreams().Upsert(name, &UpstreamRequest{Name: name})
+	assert.Nil(t, err)
+	assert.Equal(t, created.Id, replaced.Id)
+	assert.Equal(t, 10000, replaced.Slots)
+
+	err = client.Upstreams().DeleteById(created.Id)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,137 @@
+package gokong
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// FieldError describes why a single field of a request would be rejected by kong, Field is the path of the field
+// as kong names it e.g. healthchecks.active.timeout
+type FieldError struct {
+	Field   string `json:"field" yaml:"field"`
+	Message string `json:"message" yaml:"message"`
+}
+
+func (fieldError *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", fieldError.Field, fieldError.Message)
+}
+
+// ValidationError is returned by Validate when a request breaks one or more of kong's schema rules
+type ValidationError struct {
+	Entity string        `json:"entity" yaml:"entity"`
+	Fields []*FieldError `json:"fields" yaml:"fields"`
+}
+
+func (validationError *ValidationError) Error() string {
+	messages := make([]string, 0, len(validationError.Fields))
+	for _, fieldError := range validationError.Fields {
+		messages = append(messages, fieldError.Error())
+	}
+	return fmt.Sprintf("invalid %s, %s", validationError.Entity, strings.Join(messages, "; "))
+}
+
+// Field returns the error for the field, or nil when the field is valid
+func (validationError *ValidationError) Field(field string) *FieldError {
+	for _, fieldError := range validationError.Fields {
+		if fieldError.Field == field {
+			return fieldError
+		}
+	}
+	return nil
+}
+
+var namePattern = regexp.MustCompile(`^[\p{L}\p{N}._~-]+$`)
+var methodPattern = regexp.MustCompile(`^[A-Z]+$`)
+var hostnamePattern = regexp.MustCompile(`^([a-zA-Z0-9*]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)(\.[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$`)
+var routeHostPattern = regexp.MustCompile(`^(\*\.[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(\.[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*|[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(\.[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*(\.\*)?)$`)
+var headerNamePattern = regexp.MustCompile("^[a-zA-Z0-9!#$%&'*+.^_`|~-]+$")
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// validator collects the field errors of a request as it is checked
+type validator struct {
+	entity string
+	fields []*FieldError
+}
+
+func newValidator(entity string) *validator {
+	return &validator{entity: entity}
+}
+
+func (v *validator) add(field string, format string, args ...interface{}) {
+	v.fields = append(v.fields, &FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) between(field string, value int, min int, max int) {
+	if value < min || value > max {
+		v.add(field, "value should be between %d and %d", min, max)
+	}
+}
+
+func (v *validator) oneOf(field string, value string, values ...string) {
+	for _, candidate := range values {
+		if value == candidate {
+			return
+		}
+	}
+	v.add(field, "expected one of: %s", strings.Join(values, ", "))
+}
+
+func (v *validator) required(field string, value string) {
+	if value == "" {
+		v.add(field, "required field missing")
+	}
+}
+
+func (v *validator) hostname(field string, value string) {
+	if !hostnamePattern.MatchString(value) {
+		v.add(field, "invalid hostname: %s", value)
+	}
+}
+
+// routeHost checks a host of a route, which may have a single wildcard as either its first or its last label e.g.
+// *.example.com or example.*
+func (v *validator) routeHost(field string, value string) {
+	if !routeHostPattern.MatchString(value) {
+		v.add(field, "invalid hostname: %s", value)
+	}
+}
+
+func (v *validator) name(field string, value string) {
+	if !namePattern.MatchString(value) {
+		v.add(field, "invalid value '%s': it must only contain alphanumeric and '., -, _, ~' characters", value)
+	}
+}
+
+func (v *validator) ipOrCidr(field string, value string) {
+	if net.ParseIP(value) != nil {
+		return
+	}
+	if _, _, err := net.ParseCIDR(value); err != nil {
+		v.add(field, "invalid ip or cidr range: '%s'", value)
+	}
+}
+
+func (v *validator) path(field string, value string) {
+	if !strings.HasPrefix(value, "/") {
+		v.add(field, "should start with: /")
+	}
+}
+
+func (v *validator) httpStatuses(field string, statuses []int) {
+	for _, status := range statuses {
+		if status < 100 || status > 999 {
+			v.add(field, "value should be between 100 and 999")
+			return
+		}
+	}
+}
+
+// err returns a *ValidationError holding the collected field errors, or nil when there are none
+func (v *validator) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Entity: v.entity, Fields: v.fields}
+}
@@ -0,0 +1,57 @@
+package gokong
+
+import (
+	"strconv"
+	"strings"
+)
+
+// kongVersion is the leading numbers of a kong version, e.g. 2.8.1 for "2.8.1.1-enterprise-edition"
+type kongVersion []int
+
+func parseKongVersion(version string) kongVersion {
+	parsed := kongVersion{}
+	for _, part := range strings.Split(version, ".") {
+		digits := part
+		if end := strings.IndexFunc(part, func(r rune) bool { return r < '0' || r > '9' }); end >= 0 {
+			digits = part[:end]
+		}
+
+		number, err := strconv.Atoi(digits)
+		if err != nil {
+			break
+		}
+		parsed = append(parsed, number)
+
+		if digits != part {
+			break
+		}
+	}
+	return parsed
+}
+
+// atLeast returns true when the version is the same as or later than minimum, missing numbers count as zero
+func (version kongVersion) atLeast(minimum kongVersion) bool {
+	for i := 0; i < len(version) || i < len(minimum); i++ {
+		var v, m int
+		if i < len(version) {
+			v = version[i]
+		}
+		if i < len(minimum) {
+			m = minimum[i]
+		}
+		if v != m {
+			return v > m
+		}
+	}
+	return true
+}
+
+// supports returns true when kong at the configured version accepts a field added in the since version, an unknown
+// configured version supports every field
+func supports(configured string, since string) bool {
+	version := parseKongVersion(configured)
+	if len(version) == 0 {
+		return true
+	}
+	return version.atLeast(parseKongVersion(since))
+}
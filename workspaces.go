@@ -0,0 +1,207 @@
+package gokong
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+type WorkspaceClient struct {
+	config *Config
+}
+
+type WorkspaceRequest struct {
+	Name    string                 `json:"name,omitempty" yaml:"name,omitempty"`
+	Comment *string                `json:"comment,omitempty" yaml:"comment,omitempty"`
+	Config  map[string]interface{} `json:"config,omitempty" yaml:"config,omitempty"`
+	Meta    map[string]interface{} `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Fields  `json:"-" yaml:"-"`
+}
+
+type Workspace struct {
+	Id        string                 `json:"id,omitempty" yaml:"id,omitempty"`
+	Name      string                 `json:"name,omitempty" yaml:"name,omitempty"`
+	Comment   *string                `json:"comment,omitempty" yaml:"comment,omitempty"`
+	CreatedAt *int                   `json:"created_at,omitempty" yaml:"created_at,omitempty"`
+	Config    map[string]interface{} `json:"config,omitempty" yaml:"config,omitempty"`
+	Meta      map[string]interface{} `json:"meta,omitempty" yaml:"meta,omitempty"`
+}
+
+type Workspaces struct {
+	Data   []*Workspace `json:"data" yaml:"data"`
+	Next   *string      `json:"next" yaml:"next"`
+	Offset string       `json:"offset,omitempty" yaml:"offset,omitempty"`
+}
+
+type WorkspaceQueryString struct {
+	Offset string `json:"offset,omitempty"`
+	Size   int    `json:"size"`
+}
+
+const WorkspacesPath = "/workspaces/"
+
+func (workspaceClient *WorkspaceClient) GetByName(name string) (*Workspace, error) {
+	return workspaceClient.GetByNameWithContext(context.Background(), name)
+}
+
+func (workspaceClient *WorkspaceClient) GetByNameWithContext(ctx context.Context, name string) (*Workspace, error) {
+	return workspaceClient.GetByIdWithContext(ctx, name)
+}
+
+func (workspaceClient *WorkspaceClient) GetById(id string) (*Workspace, error) {
+	return workspaceClient.GetByIdWithContext(context.Background(), id)
+}
+
+func (workspaceClient *WorkspaceClient) GetByIdWithContext(ctx context.Context, id string) (*Workspace, error) {
+
+	r, body, errs := newGet(ctx, workspaceClient.config, workspaceClient.config.HostAddress+WorkspacesPath+id).End()
+	if errs != nil {
+		return nil, fmt.Errorf("could not get workspace, error: %v", errs)
+	}
+
+	if r.StatusCode == 404 {
+		return nil, nil
+	}
+
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
+	}
+
+	workspace := &Workspace{}
+	err := json.Unmarshal([]byte(body), workspace)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse workspace get response, error: %v", err)
+	}
+
+	if workspace.Id == "" {
+		return nil, nil
+	}
+
+	return workspace, nil
+}
+
+func (workspaceClient *WorkspaceClient) Create(workspaceRequest *WorkspaceRequest) (*Workspace, error) {
+	return workspaceClient.CreateWithContext(context.Background(), workspaceRequest)
+}
+
+func (workspaceClient *WorkspaceClient) CreateWithContext(ctx context.Context, workspaceRequest *WorkspaceRequest) (*Workspace, error) {
+
+	r, body, errs := newPost(ctx, workspaceClient.config, workspaceClient.config.HostAddress+WorkspacesPath).Send(workspaceRequest).End()
+	if errs != nil {
+		return nil, fmt.Errorf("could not create new workspace, error: %v", errs)
+	}
+
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
+	}
+
+	createdWorkspace := &Workspace{}
+	err := json.Unmarshal([]byte(body), createdWorkspace)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse workspace creation response, error: %v", err)
+	}
+
+	if createdWorkspace.Id == "" {
+		return nil, fmt.Errorf("could not create workspace, error: %v", body)
+	}
+
+	return createdWorkspace, nil
+}
+
+func (workspaceClient *WorkspaceClient) List(query *WorkspaceQueryString) ([]*Workspace, error) {
+	return workspaceClient.ListWithContext(context.Background(), query)
+}
+
+func (workspaceClient *WorkspaceClient) ListWithContext(ctx context.Context, query *WorkspaceQueryString) ([]*Workspace, error) {
+	workspaces := make([]*Workspace, 0)
+
+	pages := newPager(ctx, workspaceClient.config, workspaceClient.config.HostAddress+WorkspacesPath, "workspaces", *query)
+	for pages.more() {
+		data := &Workspaces{}
+		if err := pages.next(data); err != nil {
+			return nil, err
+		}
+
+		workspaces = append(workspaces, data.Data...)
+	}
+
+	return workspaces, nil
+}
+
+// Iter returns an iterator over the workspaces matching the query
+func (workspaceClient *WorkspaceClient) Iter(ctx context.Context, query *WorkspaceQueryString) *WorkspaceIterator {
+	pages := newPager(ctx, workspaceClient.config, workspaceClient.config.HostAddress+WorkspacesPath, "workspaces", *query)
+	return &WorkspaceIterator{iterator: newIterator(pages, func() listPage { return &Workspaces{} })}
+}
+
+type WorkspaceIterator struct {
+	iterator
+}
+
+// Value returns the workspace the iterator is positioned on
+func (workspaceIterator *WorkspaceIterator) Value() *Workspace {
+	workspace, _ := workspaceIterator.value.(*Workspace)
+	return workspace
+}
+
+func (workspaceClient *WorkspaceClient) UpdateByName(name string, workspaceRequest *WorkspaceRequest) (*Workspace, error) {
+	return workspaceClient.UpdateByNameWithContext(context.Background(), name, workspaceRequest)
+}
+
+func (workspaceClient *WorkspaceClient) UpdateByNameWithContext(ctx context.Context, name string, workspaceRequest *WorkspaceRequest) (*Workspace, error) {
+	return workspaceClient.UpdateByIdWithContext(ctx, name, workspaceRequest)
+}
+
+func (workspaceClient *WorkspaceClient) UpdateById(id string, workspaceRequest *WorkspaceRequest) (*Workspace, error) {
+	return workspaceClient.UpdateByIdWithContext(context.Background(), id, workspaceRequest)
+}
+
+func (workspaceClient *WorkspaceClient) UpdateByIdWithContext(ctx context.Context, id string, workspaceRequest *WorkspaceRequest) (*Workspace, error) {
+
+	r, body, errs := newPatch(ctx, workspaceClient.config, workspaceClient.config.HostAddress+WorkspacesPath+id).Send(workspaceRequest).End()
+	if errs != nil {
+		return nil, fmt.Errorf("could not update workspace, error: %v", errs)
+	}
+
+	if err := checkResponse(r, body); err != nil {
+		return nil, err
+	}
+
+	updatedWorkspace := &Workspace{}
+	err := json.Unmarshal([]byte(body), updatedWorkspace)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse workspace update response, error: %v", err)
+	}
+
+	if updatedWorkspace.Id == "" {
+		return nil, fmt.Errorf("could not update workspace, error: %v", body)
+	}
+
+	return updatedWorkspace, nil
+}
+
+func (workspaceClient *WorkspaceClient) DeleteByName(name string) error {
+	return workspaceClient.DeleteByNameWithContext(context.Background(), name)
+}
+
+func (workspaceClient *WorkspaceClient) DeleteByNameWithContext(ctx context.Context, name string) error {
+	return workspaceClient.DeleteByIdWithContext(ctx, name)
+}
+
+func (workspaceClient *WorkspaceClient) DeleteById(id string) error {
+	return workspaceClient.DeleteByIdWithContext(context.Background(), id)
+}
+
+func (workspaceClient *WorkspaceClient) DeleteByIdWithContext(ctx context.Context, id string) error {
+
+	r, body, errs := newDelete(ctx, workspaceClient.config, workspaceClient.config.HostAddress+WorkspacesPath+id).End()
+	if errs != nil {
+		return fmt.Errorf("could not delete workspace, result: %v error: %v", r, errs)
+	}
+
+	if err := checkResponse(r, body); err != nil {
+		return err
+	}
+
+	return nil
+}
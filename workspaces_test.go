@@ -0,0 +1,93 @@
+package gokong
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newRecordingServer(paths *[]string, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*paths = append(*paths, r.Method+" "+r.URL.Path)
+		w.Write([]byte(body))
+	}))
+}
+
+func Test_WorkspaceScopesEverySubClient(t *testing.T) {
+	paths := []string{}
+	server := newRecordingServer(&paths, `{"data":[],"next":null}`)
+	defer server.Close()
+
+	client := NewClient(&Config{HostAddress: server.URL}).Workspace("team-a")
+
+	client.Services().GetServiceById("foo")
+	client.Routes().List(&RouteQueryString{})
+	client.Plugins().DeleteById("bar")
+	client.Consumers().List()
+	client.Upstreams().List()
+	client.Targets().GetTargetsFromUpstreamId("baz")
+	client.Certificates().List()
+	client.Snis().List()
+
+	assert.Equal(t, []string{
+		"GET /team-a/services/foo",
+		"GET /team-a/routes/",
+		"DELETE /team-a/plugins/bar",
+		"GET /team-a/consumers/",
+		"GET /team-a/upstreams/",
+		"GET /team-a/upstreams/baz/targets",
+		"GET /team-a/certificates/",
+		"GET /team-a/snis/",
+	}, paths)
+}
+
+func Test_WorkspaceDoesNotChangeTheParentClient(t *testing.T) {
+	paths := []string{}
+	server := newRecordingServer(&paths, `{}`)
+	defer server.Close()
+
+	client := NewClient(&Config{HostAddress: server.URL})
+	client.Workspace("team-a")
+	client.Services().GetServiceById("foo")
+
+	assert.Equal(t, []string{"GET /services/foo"}, paths)
+}
+
+func Test_WorkspaceOfAWorkspaceClientSwitchesWorkspace(t *testing.T) {
+	paths := []string{}
+	server := newRecordingServer(&paths, `{}`)
+	defer server.Close()
+
+	teamA := NewClient(&Config{HostAddress: server.URL}).Workspace("team-a")
+	teamA.Workspace("team-b").Services().GetServiceById("foo")
+	teamA.Services().GetServiceById("foo")
+
+	assert.Equal(t, []string{"GET /team-b/services/foo", "GET /team-a/services/foo"}, paths)
+}
+
+func Test_WorkspaceGetsTheVersionFromTheRootOfTheAdminApi(t *testing.T) {
+	paths := []string{}
+	server := newRecordingServer(&paths, `{"version":"2.8.1"}`)
+	defer server.Close()
+
+	version, err := NewClient(&Config{HostAddress: server.URL + "/"}).Workspace("team-a").Workspace("team-b").Status().GetVersion()
+
+	assert.Nil(t, err)
+	assert.Equal(t, "2.8.1", version)
+	assert.Equal(t, []string{"GET /"}, paths)
+}
+
+func Test_WorkspaceClientCreatesWorkspaces(t *testing.T) {
+	paths := []string{}
+	server := newRecordingServer(&paths, `{"id":"d5d0b6a8-7e4a-4b0e-9bcb-3f0a34a3c1f3","name":"team-a","comment":"the a team"}`)
+	defer server.Close()
+
+	result, err := NewClient(&Config{HostAddress: server.URL}).Workspaces().Create(&WorkspaceRequest{Name: "team-a", Comment: String("the a team")})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "team-a", result.Name)
+	assert.Equal(t, "the a team", *result.Comment)
+	assert.Equal(t, []string{"POST /workspaces/"}, paths)
+}